@@ -13,6 +13,7 @@ import (
 	"github.com/moeryomenko/saga/internal/order/infrastructure/eventhandler"
 	"github.com/moeryomenko/saga/internal/order/infrastructure/repository"
 	"github.com/moeryomenko/saga/internal/order/service"
+	"github.com/moeryomenko/saga/pkg/outbox"
 )
 
 func main() {
@@ -39,8 +40,20 @@ func main() {
 		healing.WithReadyEndpoint(cfg.Health.ReadyEndpoint),
 	)
 
-	group.Run(eventhandler.HandleEvents(service.HandleEvent))
-	group.Run(service.Procuder(cfg.EventPollingPeriod))
+	group.Run(eventhandler.HandleEvents(service.HandleReply))
+	group.Run(service.Procuder(outbox.RelayConfig{
+		Mode:       cfg.EventRelayMode,
+		PollPeriod: cfg.EventPollingPeriod,
+		BatchSize:  cfg.EventBatchSize,
+	}))
+	group.Run(service.PurgeEvents(outbox.RetentionConfig{
+		Policy:    cfg.EventRetention.Policy,
+		Age:       cfg.EventRetention.Age(),
+		Period:    cfg.EventRetention.Period,
+		BatchSize: cfg.EventRetention.BatchSize,
+	}))
+	group.Run(service.ExpireOrders(cfg.Saga.PollingPeriod, cfg.Saga.BatchSize))
+	group.Run(service.PurgeIdempotencyKeys(cfg.Idempotency.PurgePeriod))
 	group.RunGracefully(health.Heartbeat, health.Stop)
 	group.RunGracefully(squad.RunServer(api.New(cfg)))
 
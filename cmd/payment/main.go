@@ -12,6 +12,8 @@ import (
 	"github.com/moeryomenko/saga/internal/payment/infrastructure/eventhandler"
 	"github.com/moeryomenko/saga/internal/payment/infrastructure/repository"
 	"github.com/moeryomenko/saga/internal/payment/service"
+	"github.com/moeryomenko/saga/pkg/money"
+	"github.com/moeryomenko/saga/pkg/outbox"
 )
 
 func main() {
@@ -21,6 +23,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	rates, err := money.ParseRates(cfg.FXRates)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, `parse fx rates: %s`, err)
+		os.Exit(1)
+	}
+
 	group, err := squad.New(
 		squad.WithSignalHandler(squad.WithGracefulPeriod(cfg.Health.GracePeriod)),
 		squad.WithBootstrap(repository.Init(cfg), eventhandler.Init(cfg)),
@@ -38,8 +46,18 @@ func main() {
 		healing.WithReadyEndpoint(cfg.Health.ReadyEndpoint),
 	)
 
-	group.Run(eventhandler.HandleEvents(service.HandlePayments))
-	group.Run(service.Producer(cfg.EventPollingPeriod))
+	group.Run(eventhandler.HandleEvents(service.HandlePayments(rates)))
+	group.Run(service.Producer(outbox.RelayConfig{
+		Mode:       cfg.EventRelayMode,
+		PollPeriod: cfg.EventPollingPeriod,
+		BatchSize:  cfg.EventBatchSize,
+	}))
+	group.Run(service.PurgeEvents(outbox.RetentionConfig{
+		Policy:    cfg.EventRetention.Policy,
+		Age:       cfg.EventRetention.Age(),
+		Period:    cfg.EventRetention.Period,
+		BatchSize: cfg.EventRetention.BatchSize,
+	}))
 	group.RunGracefully(health.Heartbeat, health.Stop)
 
 	errs := group.Wait()
@@ -9,8 +9,12 @@ import (
 	"github.com/moeryomenko/squad"
 
 	"github.com/moeryomenko/saga/internal/stock/config"
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/internal/stock/infrastructure/api"
 	"github.com/moeryomenko/saga/internal/stock/infrastructure/eventhandler"
+	"github.com/moeryomenko/saga/internal/stock/infrastructure/repository"
 	"github.com/moeryomenko/saga/internal/stock/service"
+	"github.com/moeryomenko/saga/pkg/outbox"
 )
 
 func main() {
@@ -20,10 +24,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	strategy := domain.AllocationStrategy(cfg.AllocationStrategy)
+	if !strategy.Valid() {
+		fmt.Fprintf(os.Stderr, `invalid allocation strategy: %s`, cfg.AllocationStrategy)
+		os.Exit(1)
+	}
+
 	group, err := squad.New(
 		squad.WithSignalHandler(squad.WithGracefulPeriod(cfg.Health.GracePeriod)),
-		squad.WithBootstrap(eventhandler.Init(cfg)),
-		squad.WithCloses(eventhandler.Close),
+		squad.WithBootstrap(repository.Init(cfg), eventhandler.Init(cfg)),
+		squad.WithCloses(repository.Close, eventhandler.Close),
 	)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, `bootstrap service: %s`, err)
@@ -37,8 +47,20 @@ func main() {
 		healing.WithReadyEndpoint(cfg.Health.ReadyEndpoint),
 	)
 
-	group.Run(eventhandler.HandleEvents(service.HandleEvent))
+	group.Run(eventhandler.HandleEvents(service.HandleEvents(strategy)))
+	group.Run(service.Producer(outbox.RelayConfig{
+		Mode:       cfg.EventRelayMode,
+		PollPeriod: cfg.EventPollingPeriod,
+		BatchSize:  cfg.EventBatchSize,
+	}))
+	group.Run(service.PurgeEvents(outbox.RetentionConfig{
+		Policy:    cfg.EventRetention.Policy,
+		Age:       cfg.EventRetention.Age(),
+		Period:    cfg.EventRetention.Period,
+		BatchSize: cfg.EventRetention.BatchSize,
+	}))
 	group.RunGracefully(health.Heartbeat, health.Stop)
+	group.RunGracefully(squad.RunServer(api.New(cfg)))
 
 	errs := group.Wait()
 	for _, err := range errs {
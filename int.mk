@@ -1,6 +1,6 @@
 # Database related variables.
 PG_USER ?= test
-PG_DBS ?= "orders,payments"
+PG_DBS ?= "orders,payments,stock"
 PG_PASS ?= pass
 
 .PHONY: up
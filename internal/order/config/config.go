@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -22,7 +23,19 @@ type Config struct {
 	Host string `envconfig:"HOST"`
 	Port int    `envconfig:"PORT" default:"8080"`
 
+	// EventRelayMode is how outbox relay learns about new events: poll, notify or replication,
+	// in notify mode polling period is fallback for missed notifications.
+	EventRelayMode string `envconfig:"EVENT_RELAY_MODE" default:"poll"`
+	// EventPollingPeriod is max latency of outbox relay, when event log is drained.
 	EventPollingPeriod time.Duration `envconfig:"EVENT_POLLING_PERIOD" default:"200ms"`
+	// EventBatchSize is max number of events published by outbox relay at once.
+	EventBatchSize int `envconfig:"EVENT_BATCH_SIZE" default:"100"`
+	// EventRetention removes published events from event log.
+	EventRetention RetentionConfig `envconfig:"EVENT_RETENTION"`
+
+	Saga SagaConfig `envconfig:"SAGA"`
+
+	Idempotency IdempotencyConfig `envconfig:"IDEMPOTENCY"`
 
 	Health HealthConfig `envconfig:"HEALTH"`
 
@@ -52,6 +65,24 @@ type PoolConfig struct {
 	MaxIdleConns int `envconfig:"MAX_IDLE_CONNS" default:"20"`
 }
 
+// SagaConfig represents order saga configuration.
+type SagaConfig struct {
+	// Timeout is time for saga participants to confirm order, after that order will be canceled.
+	Timeout       time.Duration `envconfig:"TIMEOUT" default:"5m"`
+	PollingPeriod time.Duration `envconfig:"POLLING_PERIOD" default:"10s"`
+	BatchSize     int           `envconfig:"BATCH_SIZE" default:"100"`
+}
+
+// IdempotencyConfig represents configuration of idempotency keys for API mutations.
+type IdempotencyConfig struct {
+	// TTL is time while response of request is replayed for the same key.
+	TTL time.Duration `envconfig:"TTL" default:"24h"`
+	// Lease is time while request without stored response is in progress,
+	// it must exceed duration of request handling.
+	Lease       time.Duration `envconfig:"LEASE" default:"1m"`
+	PurgePeriod time.Duration `envconfig:"PURGE_PERIOD" default:"1h"`
+}
+
 // HealthConfig represents health controller configuration.
 type HealthConfig struct {
 	Port          int           `envconfig:"PORT" default:"6060"`
@@ -65,8 +96,38 @@ type HealthConfig struct {
 type StreamConfig struct {
 	Host string `envconfig:"HOST"`
 	Port int    `envconfig:"PORT" default:"6379"`
+	// Consumer is name of replica in consumer groups, it must be stable
+	// across restarts, hostname of replica is used by default.
+	Consumer string `envconfig:"CONSUMER"`
 }
 
 func (c StreamConfig) Addr() string {
 	return fmt.Sprintf(`%s:%d`, c.Host, c.Port)
 }
+
+// ConsumerName returns name of replica in consumer groups.
+func (c StreamConfig) ConsumerName() string {
+	if c.Consumer != `` {
+		return c.Consumer
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return `default`
+	}
+	return hostname
+}
+
+// RetentionConfig represents retention of published events of outbox.
+type RetentionConfig struct {
+	// Policy is what happens to published events: delete or archive.
+	Policy string `envconfig:"POLICY" default:"delete"`
+	// Days is age of published events to be removed.
+	Days      int           `envconfig:"DAYS" default:"7"`
+	Period    time.Duration `envconfig:"PERIOD" default:"1h"`
+	BatchSize int           `envconfig:"BATCH_SIZE" default:"1000"`
+}
+
+// Age returns age of published events to be removed.
+func (cfg RetentionConfig) Age() time.Duration {
+	return time.Duration(cfg.Days) * 24 * time.Hour
+}
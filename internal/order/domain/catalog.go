@@ -0,0 +1,42 @@
+package domain
+
+import "github.com/moeryomenko/saga/pkg/money"
+
+// Product represents product from catalog.
+type Product struct {
+	// SKU is stock keeping unit of product.
+	SKU string
+	// Name is human readable name of product.
+	Name string
+	// Price is price of one product unit.
+	Price money.Money
+}
+
+// NewProduct returns validated catalog product.
+func NewProduct(sku, name string, price money.Money) (Product, error) {
+	if sku == `` {
+		return Product{}, ErrInvalidProduct
+	}
+	if price.IsNegative() || price.Currency == `` {
+		return Product{}, ErrInvalidPrice
+	}
+	return Product{SKU: sku, Name: name, Price: price}, nil
+}
+
+// Pricing is port to product catalog, which provides actual unit prices.
+type Pricing interface {
+	// UnitPrice returns price of one unit of product,
+	// or ErrUnknownProduct if catalog doesn't contain product.
+	UnitPrice(sku string) (money.Money, error)
+}
+
+// PriceList is fixed set of unit prices, e.g. prices snapshotted on order processing.
+type PriceList map[string]money.Money
+
+func (p PriceList) UnitPrice(sku string) (money.Money, error) {
+	price, ok := p[sku]
+	if !ok {
+		return money.Money{}, ErrUnknownProduct
+	}
+	return price, nil
+}
@@ -6,10 +6,34 @@ var (
 	ErrDomain = errors.New(`domain`)
 
 	ErrCancelOrder     = errors.New(`cancellation of an unfinished order`)
+	ErrCustomerCancel  = errors.New(`cancellation of a finished order`)
 	ErrAddItem         = errors.New(`adding a item to an order that is being processed`)
 	ErrRemoveItemEmpty = errors.New(`remove item from empty order`)
 	ErrRemoveItem      = errors.New(`removing a item from an order that is being processed`)
+	ErrItemNotFound    = errors.New(`item not found in order`)
+	ErrInvalidQuantity = errors.New(`invalid item quantity`)
 	ErrPayOrder        = errors.New(`payment for a prepared order`)
 	ErrStockOrder      = errors.New(`stocking of not prepared order`)
 	ErrEmptyOrder      = errors.New(`couldn't process empty order`)
+	ErrExpireOrder     = errors.New(`expiration of not processing order`)
+	ErrOrderNotExpired = errors.New(`order deadline has not passed yet`)
+	ErrOrderNotFound   = errors.New(`order not found`)
+	ErrInvalidCursor   = errors.New(`invalid pagination cursor`)
+	ErrUnknownProduct  = errors.New(`unknown product`)
+	ErrProductExists   = errors.New(`product already exists`)
+	ErrInvalidProduct  = errors.New(`product must have sku`)
+	ErrInvalidPrice    = errors.New(`product price must not be negative`)
+	ErrReturnOrder     = errors.New(`return of not completed order`)
+	ErrReturnExists    = errors.New(`return already exists`)
+	ErrReturnNotFound  = errors.New(`return not found`)
+	ErrInvalidReturn   = errors.New(`order has no such items to return`)
+	ErrMixedCurrencies = errors.New(`order items are priced in different currencies`)
+
+	ErrUnknownPromotion   = errors.New(`unknown discount code`)
+	ErrPromotionExists    = errors.New(`discount code already exists`)
+	ErrInvalidPromotion   = errors.New(`discount must have code, kind and positive value, fixed discount must have currency`)
+	ErrPromotionExpired   = errors.New(`discount code has expired`)
+	ErrPromotionExhausted = errors.New(`discount code usage limit is reached`)
+	ErrPromotionApplied   = errors.New(`discount code is already applied`)
+	ErrPromotionCurrency  = errors.New(`discount code isn't applicable to order currency`)
 )
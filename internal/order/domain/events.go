@@ -1,6 +1,10 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Event interface{}
 
@@ -10,14 +14,24 @@ type CreateOrder struct {
 }
 
 type AddItem struct {
-	Item string
+	Item Item
 }
 
+// RemoveItem removes units of item line, which is identified by SKU and attributes.
 type RemoveItem struct {
-	Item string
+	SKU        string
+	Quantity   int
+	Attributes map[string]string
 }
 
-type Process struct{}
+type Process struct {
+	Pricing    Pricing
+	Promotions Promotions
+	// Codes is discount codes entered by customer.
+	Codes    []string
+	Now      time.Time
+	Deadline time.Time
+}
 
 type ConfirmPayment struct {
 	PaymentID uuid.UUID
@@ -27,7 +41,31 @@ type ConfirmStock struct{}
 
 type RejectPayment struct{}
 
-type RejectStock struct{}
+// RejectStock is failure of stock reservation by reason.
+type RejectStock struct {
+	Reason string
+}
+
+type Cancel struct{}
+
+type Timeout struct {
+	Now time.Time
+}
+
+// RequestReturn starts return of completed order items,
+// empty items mean return of all not returned items.
+type RequestReturn struct {
+	ReturnID uuid.UUID
+	Items    []Item
+}
+
+type ConfirmRefund struct {
+	ReturnID uuid.UUID
+}
+
+type ConfirmRestock struct {
+	ReturnID uuid.UUID
+}
 
 func Apply(order Order, event Event) (Order, error) {
 	switch event := event.(type) {
@@ -39,15 +77,25 @@ func Apply(order Order, event Event) (Order, error) {
 	case AddItem:
 		return AddItemToOrder(order, event.Item)
 	case RemoveItem:
-		return RemoveItemFromOrder(order, event.Item)
+		return RemoveItemFromOrder(order, Item{SKU: event.SKU, Quantity: event.Quantity, Attributes: event.Attributes})
 	case Process:
-		return CalculatePrice(order)
+		return CalculatePrice(order, event.Pricing, event.Promotions, event.Codes, event.Now, event.Deadline)
 	case ConfirmPayment:
 		return AttachPayments(order, event.PaymentID)
 	case ConfirmStock:
 		return StockOrder(order)
 	case RejectPayment, RejectStock:
 		return CancelOrder(order)
+	case Cancel:
+		return CancelByCustomer(order)
+	case Timeout:
+		return ExpireOrder(order, event.Now)
+	case RequestReturn:
+		return ReturnItems(order, event.ReturnID, event.Items)
+	case ConfirmRefund:
+		return ConfirmReturn(order, event.ReturnID, true, false)
+	case ConfirmRestock:
+		return ConfirmReturn(order, event.ReturnID, false, true)
 	default:
 		panic(`bug: invalid event type`)
 	}
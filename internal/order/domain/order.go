@@ -1,8 +1,11 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
-	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/pkg/money"
 )
 
 type Order interface {
@@ -25,18 +28,35 @@ func (o EmptyOrder) GetCustomerID() uuid.UUID {
 	return o.CustomerID
 }
 
+// Item represents order line item.
+type Item struct {
+	// SKU is stock keeping unit of product, it identifies line into order.
+	SKU string
+	// Quantity is number of product units.
+	Quantity int
+	// UnitPrice is snapshot of product unit price at the moment of order processing.
+	UnitPrice money.Money
+	// Attributes is optional product attributes, e.g. size or color.
+	Attributes map[string]string
+}
+
 type ActiveOrder struct {
 	EmptyOrder
 
 	// Items is list of items into order.
-	Items []string
+	Items []Item
 }
 
 type PendingOrder struct {
 	ActiveOrder
 
-	// Price is sum of items price.
-	Price decimal.Decimal
+	// Discounts is promotions applied to order.
+	Discounts []Discount
+	// Price is sum of items price decreased by discounts,
+	// its currency is currency of the order.
+	Price money.Money
+	// Deadline is time until saga participants must confirm order.
+	Deadline time.Time
 }
 
 type StockedOrder struct {
@@ -45,6 +65,9 @@ type StockedOrder struct {
 
 type CanceledOrder struct {
 	PendingOrder
+
+	// PaymentID references payment of order canceled after payment, it's nil otherwise.
+	PaymentID uuid.UUID
 }
 
 type PaidOrder struct {
@@ -104,39 +127,84 @@ func CancelOrder(order Order) (CanceledOrder, error) {
 	case PendingOrder:
 		return CanceledOrder{PendingOrder: order}, nil
 	case PaidOrder:
-		return CanceledOrder{PendingOrder: order.PendingOrder}, nil
+		return CanceledOrder{PendingOrder: order.PendingOrder, PaymentID: order.PaymentID}, nil
 	case StockedOrder:
-		return CanceledOrder(order), nil
+		return CanceledOrder{PendingOrder: order.PendingOrder}, nil
 	default:
 		return CanceledOrder{}, ErrCancelOrder
 	}
 }
 
-// AddItemToOrder adds item to order.
-func AddItemToOrder(order Order, item string) (ActiveOrder, error) {
+// CancelByCustomer cancels order on customer request. Orders which are not
+// processed yet are canceled immediately, in-flight orders are compensated by saga.
+func CancelByCustomer(order Order) (CanceledOrder, error) {
+	switch order := order.(type) {
+	case EmptyOrder:
+		return CanceledOrder{PendingOrder: PendingOrder{ActiveOrder: ActiveOrder{EmptyOrder: order}}}, nil
+	case ActiveOrder:
+		return CanceledOrder{PendingOrder: PendingOrder{ActiveOrder: order}}, nil
+	case PendingOrder, PaidOrder, StockedOrder:
+		return CancelOrder(order)
+	default:
+		return CanceledOrder{}, ErrCustomerCancel
+	}
+}
+
+// ExpireOrder cancels order, which wasn't confirmed by saga participants until deadline.
+func ExpireOrder(order Order, now time.Time) (Order, error) {
+	var deadline time.Time
+	switch order := order.(type) {
+	case PendingOrder:
+		deadline = order.Deadline
+	case PaidOrder:
+		deadline = order.Deadline
+	case StockedOrder:
+		deadline = order.Deadline
+	default:
+		return nil, ErrExpireOrder
+	}
+
+	if now.Before(deadline) {
+		return nil, ErrOrderNotExpired
+	}
+
+	return CancelOrder(order)
+}
+
+// AddItemToOrder adds item to order, if order already contains item with same SKU
+// its quantity will be increased.
+func AddItemToOrder(order Order, item Item) (ActiveOrder, error) {
+	if item.Quantity <= 0 {
+		return ActiveOrder{}, ErrInvalidQuantity
+	}
+
 	switch order := any(order).(type) {
 	case EmptyOrder:
 		return ActiveOrder{
 			EmptyOrder: EmptyOrder{ID: order.ID, CustomerID: order.CustomerID},
-			Items:      append([]string{}, item),
+			Items:      []Item{item},
 		}, nil
 	case ActiveOrder:
 		return ActiveOrder{
 			EmptyOrder: EmptyOrder{ID: order.ID, CustomerID: order.CustomerID},
-			Items:      append(order.Items, item),
+			Items:      addItem(order.Items, item),
 		}, nil
 	default:
 		return ActiveOrder{}, ErrAddItem
 	}
 }
 
-// RemoveItemFromOrder remove given item from order.
-func RemoveItemFromOrder(order Order, item string) (Order, error) {
+// RemoveItemFromOrder decreases quantity of line of given item into order,
+// line will be removed from order when its quantity drops to zero.
+func RemoveItemFromOrder(order Order, item Item) (Order, error) {
 	switch order := order.(type) {
 	case EmptyOrder:
 		return nil, ErrRemoveItemEmpty
 	case ActiveOrder:
-		items := removeItem(order.Items, item)
+		items, err := removeItem(order.Items, item)
+		if err != nil {
+			return nil, err
+		}
 
 		if len(items) == 0 {
 			return EmptyOrder{
@@ -154,32 +222,120 @@ func RemoveItemFromOrder(order Order, item string) (Order, error) {
 	}
 }
 
-// CalculatePrice calculate price and close order to changes.
-func CalculatePrice(order Order) (Order, error) {
+// CalculatePrice snapshots unit prices of items from catalog, applies discount codes,
+// calculate price and close order to changes until given deadline.
+func CalculatePrice(order Order, pricing Pricing, promotions Promotions, codes []string, now, deadline time.Time) (Order, error) {
 	switch order := order.(type) {
 	case ActiveOrder:
+		items := make([]Item, 0, len(order.Items))
+		for _, item := range order.Items {
+			unitPrice, err := pricing.UnitPrice(item.SKU)
+			if err != nil {
+				return nil, err
+			}
+			item.UnitPrice = unitPrice
+			items = append(items, item)
+		}
+
+		price, err := Price(items)
+		if err != nil {
+			return nil, err
+		}
+
+		discounts, err := ApplyPromotions(price, order.CustomerID, promotions, codes, now)
+		if err != nil {
+			return nil, err
+		}
+
+		price, err = DiscountedPrice(price, discounts)
+		if err != nil {
+			return nil, err
+		}
+
 		return PendingOrder{
 			ActiveOrder: ActiveOrder{
 				EmptyOrder: order.EmptyOrder,
-				Items:      order.Items,
+				Items:      items,
 			},
-			Price: Price(order.Items),
+			Discounts: discounts,
+			Price:     price,
+			Deadline:  deadline,
 		}, nil
 	default:
 		return nil, ErrEmptyOrder
 	}
 }
 
-// Price retuns sum price of items.
-func Price(items []string) decimal.Decimal {
-	return decimal.NewFromFloat32(float32(len(items)) * 9.99) // dummy price.
+// Price retuns sum price of items, all items must be priced in the same currency.
+func Price(items []Item) (money.Money, error) {
+	if len(items) == 0 {
+		return money.Money{}, ErrEmptyOrder
+	}
+
+	price := money.Zero(items[0].UnitPrice.Currency)
+	for _, item := range items {
+		var err error
+		price, err = price.Add(item.UnitPrice.Mul(int64(item.Quantity)))
+		if err != nil {
+			return money.Money{}, ErrMixedCurrencies
+		}
+	}
+	return price, nil
 }
 
-func removeItem(items []string, item string) []string {
+// addItem merges item into line of the same product, lines of the same SKU
+// with different attributes, e.g. sizes, are different lines.
+func addItem(items []Item, item Item) []Item {
+	result := make([]Item, 0, len(items)+1)
+	added := false
+	for _, it := range items {
+		if !added && sameLine(it, item) {
+			it.Quantity += item.Quantity
+			added = true
+		}
+		result = append(result, it)
+	}
+	if !added {
+		result = append(result, item)
+	}
+	return result
+}
+
+// sameLine reports whether items are the same product with the same attributes.
+func sameLine(a, b Item) bool {
+	if a.SKU != b.SKU || len(a.Attributes) != len(b.Attributes) {
+		return false
+	}
+	for key, value := range a.Attributes {
+		if other, ok := b.Attributes[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// removeItem decreases quantity of line, which is the same as item.
+func removeItem(items []Item, item Item) ([]Item, error) {
+	quantity := item.Quantity
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
 	for i, it := range items {
-		if it == item {
-			return append(items[:i], items[i+1:]...)
+		if !sameLine(it, item) {
+			continue
+		}
+
+		switch {
+		case quantity > it.Quantity:
+			return nil, ErrInvalidQuantity
+		case quantity == it.Quantity:
+			return append(append([]Item{}, items[:i]...), items[i+1:]...), nil
+		default:
+			result := append([]Item{}, items...)
+			result[i].Quantity -= quantity
+			return result, nil
 		}
 	}
-	return items
+	return nil, ErrItemNotFound
 }
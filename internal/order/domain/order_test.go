@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/pkg/money"
+)
+
+func TestRemoveItemFromOrder(t *testing.T) {
+	red := Item{SKU: `shirt`, Quantity: 1, Attributes: map[string]string{`color`: `red`}}
+	blue := Item{SKU: `shirt`, Quantity: 2, Attributes: map[string]string{`color`: `blue`}}
+	plain := Item{SKU: `shirt`, Quantity: 1}
+	order := ActiveOrder{EmptyOrder: EmptyOrder{ID: uuid.New(), CustomerID: uuid.New()}, Items: []Item{red, blue, plain}}
+
+	testcases := map[string]struct {
+		event         RemoveItem
+		expectedItems []Item
+		expectedErr   error
+	}{
+		`remove line with attributes`: {
+			event:         RemoveItem{SKU: `shirt`, Quantity: 2, Attributes: map[string]string{`color`: `blue`}},
+			expectedItems: []Item{red, plain},
+		},
+		`decrease line with attributes`: {
+			event:         RemoveItem{SKU: `shirt`, Quantity: 1, Attributes: map[string]string{`color`: `blue`}},
+			expectedItems: []Item{red, {SKU: `shirt`, Quantity: 1, Attributes: map[string]string{`color`: `blue`}}, plain},
+		},
+		`remove line without attributes`: {
+			event:         RemoveItem{SKU: `shirt`, Quantity: 1},
+			expectedItems: []Item{red, blue},
+		},
+		`unknown attributes`: {
+			event:       RemoveItem{SKU: `shirt`, Quantity: 1, Attributes: map[string]string{`color`: `green`}},
+			expectedErr: ErrItemNotFound,
+		},
+		`quantity exceeds line`: {
+			event:       RemoveItem{SKU: `shirt`, Quantity: 2, Attributes: map[string]string{`color`: `red`}},
+			expectedErr: ErrInvalidQuantity,
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			result, err := Apply(order, tc.event)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedItems, result.(ActiveOrder).Items)
+		})
+	}
+}
+
+func TestReturnItems(t *testing.T) {
+	red := Item{SKU: `shirt`, Quantity: 1, UnitPrice: usd(10), Attributes: map[string]string{`color`: `red`}}
+	blue := Item{SKU: `shirt`, Quantity: 2, UnitPrice: usd(12), Attributes: map[string]string{`color`: `blue`}}
+	completed := CompletedOrder{PaidOrder: PaidOrder{PendingOrder: PendingOrder{
+		ActiveOrder: ActiveOrder{EmptyOrder: EmptyOrder{ID: uuid.New(), CustomerID: uuid.New()}, Items: []Item{red, blue}},
+		Price:       usd(34),
+	}}}
+	returnedRed := PartiallyReturnedOrder{CompletedOrder: completed, Returns: []Return{{ID: uuid.New(), Items: []Item{red}, Refund: usd(10)}}}
+
+	testcases := map[string]struct {
+		order          Order
+		items          []Item
+		expectedItems  []Item
+		expectedRefund money.Money
+		expectedErr    error
+	}{
+		`return spread across lines of SKU`: {
+			order:          completed,
+			items:          []Item{{SKU: `shirt`, Quantity: 2}},
+			expectedItems:  []Item{red, {SKU: `shirt`, Quantity: 1, UnitPrice: usd(12), Attributes: blue.Attributes}},
+			expectedRefund: usd(22),
+		},
+		`return of line with attributes`: {
+			order:          completed,
+			items:          []Item{{SKU: `shirt`, Quantity: 2, Attributes: map[string]string{`color`: `blue`}}},
+			expectedItems:  []Item{blue},
+			expectedRefund: usd(24),
+		},
+		`return of all units of SKU`: {
+			order:          completed,
+			items:          []Item{{SKU: `shirt`, Quantity: 3}},
+			expectedItems:  []Item{red, blue},
+			expectedRefund: usd(34),
+		},
+		`return of not returned units`: {
+			order:          returnedRed,
+			items:          []Item{{SKU: `shirt`, Quantity: 2}},
+			expectedItems:  []Item{blue},
+			expectedRefund: usd(24),
+		},
+		`return exceeds line with attributes`: {
+			order:       completed,
+			items:       []Item{{SKU: `shirt`, Quantity: 3, Attributes: map[string]string{`color`: `blue`}}},
+			expectedErr: ErrInvalidReturn,
+		},
+		`return exceeds not returned units`: {
+			order:       returnedRed,
+			items:       []Item{{SKU: `shirt`, Quantity: 3}},
+			expectedErr: ErrInvalidReturn,
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			returnID := uuid.New()
+			result, err := ReturnItems(tc.order, returnID, tc.items)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var returns []Return
+			switch result := result.(type) {
+			case PartiallyReturnedOrder:
+				returns = result.Returns
+			case ReturnedOrder:
+				returns = result.Returns
+			}
+			require.NotEmpty(t, returns)
+			last := returns[len(returns)-1]
+			require.Equal(t, returnID, last.ID)
+			require.Equal(t, tc.expectedItems, last.Items)
+			require.Equal(t, tc.expectedRefund.String(), last.Refund.String())
+		})
+	}
+}
+
+func usd(amount int64) money.Money {
+	return money.New(decimal.NewFromInt(amount), `USD`)
+}
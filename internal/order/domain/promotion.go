@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/pkg/money"
+)
+
+// DiscountKind is way to calculate discount amount.
+type DiscountKind string
+
+const (
+	// PercentageDiscount subtracts percent of order price.
+	PercentageDiscount DiscountKind = `percentage`
+	// FixedDiscount subtracts fixed amount from order price.
+	FixedDiscount DiscountKind = `fixed`
+)
+
+// Promotion represents discount code from marketing campaign.
+type Promotion struct {
+	// Code is discount code entered by customer.
+	Code string
+	Kind DiscountKind
+	// Value is percent for percentage discount or amount for fixed one.
+	Value decimal.Decimal
+	// Currency is currency of fixed discount amount, it's empty for percentage discount.
+	Currency money.Currency
+	// ExpiresAt is time after that code can't be applied, zero means code never expires.
+	ExpiresAt time.Time
+	// UsageLimit is how many times one customer can apply code, zero means unlimited.
+	UsageLimit int
+}
+
+// Discount is promotion applied to order, it's price line which decreases order price.
+type Discount struct {
+	Code   string
+	Amount money.Money
+}
+
+// Promotions is port to promotions, which provides discount codes.
+type Promotions interface {
+	// Promotion returns promotion by code and how many times customer has used it,
+	// or ErrUnknownPromotion if code doesn't exist.
+	Promotion(code string, customerID uuid.UUID) (Promotion, int, error)
+}
+
+// NewPromotion returns validated promotion.
+func NewPromotion(code string, kind DiscountKind, value decimal.Decimal, currency money.Currency, expiresAt time.Time, usageLimit int) (Promotion, error) {
+	if code == `` || usageLimit < 0 || !value.IsPositive() {
+		return Promotion{}, ErrInvalidPromotion
+	}
+	switch kind {
+	case PercentageDiscount:
+		if value.GreaterThan(decimal.NewFromInt(100)) {
+			return Promotion{}, ErrInvalidPromotion
+		}
+		currency = ``
+	case FixedDiscount:
+		if currency == `` {
+			return Promotion{}, ErrInvalidPromotion
+		}
+		value = money.New(value, currency).Amount
+	default:
+		return Promotion{}, ErrInvalidPromotion
+	}
+	return Promotion{Code: code, Kind: kind, Value: value, Currency: currency, ExpiresAt: expiresAt, UsageLimit: usageLimit}, nil
+}
+
+// ApplyPromotions validates codes for customer and calculates discounts,
+// discounts are applied one by one and can't make price negative.
+func ApplyPromotions(price money.Money, customerID uuid.UUID, promotions Promotions, codes []string, now time.Time) ([]Discount, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	discounts := make([]Discount, 0, len(codes))
+	applied := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		if _, ok := applied[code]; ok {
+			return nil, ErrPromotionApplied
+		}
+		applied[code] = struct{}{}
+
+		promotion, usages, err := promotions.Promotion(code, customerID)
+		if err != nil {
+			return nil, err
+		}
+		if !promotion.ExpiresAt.IsZero() && !now.Before(promotion.ExpiresAt) {
+			return nil, ErrPromotionExpired
+		}
+		if promotion.UsageLimit != 0 && usages >= promotion.UsageLimit {
+			return nil, ErrPromotionExhausted
+		}
+
+		var amount money.Money
+		switch promotion.Kind {
+		case PercentageDiscount:
+			amount = price.Percent(promotion.Value)
+		default:
+			if promotion.Currency != price.Currency {
+				return nil, ErrPromotionCurrency
+			}
+			amount = money.New(promotion.Value, promotion.Currency)
+			if amount.Amount.GreaterThan(price.Amount) {
+				amount = price
+			}
+		}
+		price, _ = price.Sub(amount)
+		discounts = append(discounts, Discount{Code: code, Amount: amount})
+	}
+	return discounts, nil
+}
+
+// DiscountedPrice returns price decreased by discounts.
+func DiscountedPrice(price money.Money, discounts []Discount) (money.Money, error) {
+	for _, discount := range discounts {
+		var err error
+		price, err = price.Sub(discount.Amount)
+		if err != nil {
+			return money.Money{}, ErrPromotionCurrency
+		}
+	}
+	return price, nil
+}
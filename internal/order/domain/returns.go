@@ -0,0 +1,207 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/moeryomenko/saga/pkg/money"
+)
+
+// Return is customer request to return items of completed order,
+// it's compensated by payment refund and restock of returned items.
+type Return struct {
+	ID uuid.UUID
+	// Items is returned items with unit prices of the order.
+	Items []Item
+	// Refund is part of order price returned to customer.
+	Refund money.Money
+	// Refunded is true when payment service has refunded payment.
+	Refunded bool
+	// Restocked is true when stock service has restocked items.
+	Restocked bool
+}
+
+// PartiallyReturnedOrder is completed order, which part of items was returned.
+type PartiallyReturnedOrder struct {
+	CompletedOrder
+
+	Returns []Return
+}
+
+// ReturnedOrder is completed order, which all items were returned.
+type ReturnedOrder struct {
+	CompletedOrder
+
+	Returns []Return
+}
+
+// ReturnItems starts return of given items, empty items mean return of all items
+// which weren't returned yet. Refund is share of order price, so discounts are
+// returned proportionally, the last return refunds rest of the price.
+func ReturnItems(order Order, returnID uuid.UUID, items []Item) (Order, error) {
+	var (
+		completed CompletedOrder
+		returns   []Return
+	)
+	switch order := order.(type) {
+	case CompletedOrder:
+		completed = order
+	case PartiallyReturnedOrder:
+		completed, returns = order.CompletedOrder, order.Returns
+	default:
+		return nil, ErrReturnOrder
+	}
+
+	for _, r := range returns {
+		if r.ID == returnID {
+			return nil, ErrReturnExists
+		}
+	}
+
+	remaining := remainingItems(completed.Items, returns)
+	if len(items) == 0 {
+		items = remaining
+	}
+	returned, rest, err := returnItems(remaining, items)
+	if err != nil {
+		return nil, err
+	}
+
+	refund, err := refundAmount(completed.PendingOrder, returns, returned, len(rest) == 0)
+	if err != nil {
+		return nil, err
+	}
+
+	returns = append(append([]Return{}, returns...), Return{ID: returnID, Items: returned, Refund: refund})
+	if len(rest) == 0 {
+		return ReturnedOrder{CompletedOrder: completed, Returns: returns}, nil
+	}
+	return PartiallyReturnedOrder{CompletedOrder: completed, Returns: returns}, nil
+}
+
+// ConfirmReturn marks return as refunded or restocked by saga participant.
+func ConfirmReturn(order Order, returnID uuid.UUID, refunded, restocked bool) (Order, error) {
+	confirm := func(returns []Return) ([]Return, error) {
+		confirmed := make([]Return, 0, len(returns))
+		found := false
+		for _, r := range returns {
+			if r.ID == returnID {
+				found = true
+				r.Refunded = r.Refunded || refunded
+				r.Restocked = r.Restocked || restocked
+			}
+			confirmed = append(confirmed, r)
+		}
+		if !found {
+			return nil, ErrReturnNotFound
+		}
+		return confirmed, nil
+	}
+
+	switch order := order.(type) {
+	case PartiallyReturnedOrder:
+		returns, err := confirm(order.Returns)
+		if err != nil {
+			return nil, err
+		}
+		return PartiallyReturnedOrder{CompletedOrder: order.CompletedOrder, Returns: returns}, nil
+	case ReturnedOrder:
+		returns, err := confirm(order.Returns)
+		if err != nil {
+			return nil, err
+		}
+		return ReturnedOrder{CompletedOrder: order.CompletedOrder, Returns: returns}, nil
+	default:
+		return nil, ErrReturnNotFound
+	}
+}
+
+// remainingItems returns order items without returned ones.
+func remainingItems(items []Item, returns []Return) []Item {
+	remaining := append([]Item{}, items...)
+	for _, r := range returns {
+		for _, returned := range r.Items {
+			remaining, _ = removeItem(remaining, returned)
+		}
+	}
+	return remaining
+}
+
+// returnItems removes returned items from remaining ones, returned items get unit prices
+// and attributes of the order lines. Item without attributes is returned from lines of
+// its SKU in order of the order, item with attributes is returned from its line.
+func returnItems(remaining, items []Item) (returned, rest []Item, err error) {
+	rest = remaining
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, nil, ErrInvalidReturn
+		}
+
+		available := 0
+		for _, line := range rest {
+			if returnsLine(line, item) {
+				available += line.Quantity
+			}
+		}
+		if available < item.Quantity {
+			return nil, nil, ErrInvalidReturn
+		}
+
+		quantity := item.Quantity
+		for _, line := range rest {
+			if quantity == 0 {
+				break
+			}
+			if !returnsLine(line, item) {
+				continue
+			}
+
+			part := Item{
+				SKU:        line.SKU,
+				Quantity:   line.Quantity,
+				UnitPrice:  line.UnitPrice,
+				Attributes: line.Attributes,
+			}
+			if quantity < part.Quantity {
+				part.Quantity = quantity
+			}
+			returned = addItem(returned, part)
+			rest, err = removeItem(rest, part)
+			if err != nil {
+				return nil, nil, err
+			}
+			quantity -= part.Quantity
+		}
+	}
+	return returned, rest, nil
+}
+
+// returnsLine reports whether returned item is taken from order line.
+func returnsLine(line, item Item) bool {
+	if len(item.Attributes) == 0 {
+		return line.SKU == item.SKU
+	}
+	return sameLine(line, item)
+}
+
+func refundAmount(order PendingOrder, returns []Return, returned []Item, last bool) (money.Money, error) {
+	if last {
+		refund := order.Price
+		for _, r := range returns {
+			refund, _ = refund.Sub(r.Refund)
+		}
+		return refund, nil
+	}
+
+	total, err := Price(order.Items)
+	if err != nil {
+		return money.Money{}, err
+	}
+	value, err := Price(returned)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if total.IsZero() {
+		return money.Zero(order.Price.Currency), nil
+	}
+	return money.New(value.Amount.Mul(order.Price.Amount).Div(total.Amount), order.Price.Currency), nil
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AnyVersion disables optimistic concurrency check of order version,
+// orders created before event store have version 0.
+const AnyVersion = -1
+
+// VersionConflict is returned when order was changed since expected version.
+type VersionConflict struct {
+	Expected int
+	Current  int
+}
+
+func (e VersionConflict) Error() string {
+	return fmt.Sprintf(`order version is %d, expected %d`, e.Current, e.Expected)
+}
+
+// OrderView is order state with its audit timestamps.
+type OrderView struct {
+	Order     Order
+	Version   int
+	CreatedAt time.Time
+	// UpdatedAt is zero if order wasn't changed after creation.
+	UpdatedAt time.Time
+}
+
+// OrdersFilter restricts listing of customer orders, zero fields are ignored.
+type OrdersFilter struct {
+	Kinds       []string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	// Cursor is position of the previous page end.
+	Cursor string
+	Limit  int
+}
+
+// OrdersPage is page of customer orders, NextCursor is empty on the last page.
+type OrdersPage struct {
+	Orders     []OrderView
+	NextCursor string
+}
+
+// StoredEvent is order event from event store.
+type StoredEvent struct {
+	Version   int
+	Type      string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
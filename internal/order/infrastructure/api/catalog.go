@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/internal/order/service"
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+func (RestController) GetProducts(w http.ResponseWriter, r *http.Request) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.ListProducts(ctx)
+	}), WithResponseMapper(mapProducts))
+}
+
+func (RestController) PostProducts(w http.ResponseWriter, r *http.Request) {
+	var product Product
+	handlerDecorator(w, r, WithRequestBody(&product), WithOperation(func(ctx context.Context) (any, error) {
+		name := ``
+		if product.Name != nil {
+			name = *product.Name
+		}
+		return service.CreateProduct(ctx, *product.Sku, name, mapMoney(*product.Price, *product.Currency))
+	}), WithResponseMapper(mapProduct), WithErrorMapper(mapCatalogError), WithDefaultStatus(http.StatusCreated))
+}
+
+func (RestController) GetProductsSku(w http.ResponseWriter, r *http.Request, sku string) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.GetProduct(ctx, sku)
+	}), WithResponseMapper(mapProduct), WithErrorMapper(mapCatalogError))
+}
+
+func (RestController) PutProductsSku(w http.ResponseWriter, r *http.Request, sku string) {
+	var product UpdateProduct
+	handlerDecorator(w, r, WithRequestBody(&product), WithOperation(func(ctx context.Context) (any, error) {
+		name := ``
+		if product.Name != nil {
+			name = *product.Name
+		}
+		return service.UpdateProduct(ctx, sku, name, mapMoney(*product.Price, *product.Currency))
+	}), WithResponseMapper(mapProduct), WithErrorMapper(mapCatalogError))
+}
+
+func (RestController) DeleteProductsSku(w http.ResponseWriter, r *http.Request, sku string) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return nil, service.DeleteProduct(ctx, sku)
+	}), WithErrorMapper(mapCatalogError), WithDefaultStatus(http.StatusNoContent))
+}
+
+func mapCatalogError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUnknownProduct):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrProductExists):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrDomain):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -2,8 +2,8 @@ package api
 
 import (
 	"context"
-	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
@@ -11,55 +11,178 @@ import (
 	"github.com/moeryomenko/saga/internal/order/config"
 	"github.com/moeryomenko/saga/internal/order/domain"
 	"github.com/moeryomenko/saga/internal/order/service"
+	"github.com/moeryomenko/saga/pkg/errors"
 )
 
 func New(cfg *config.Config) *http.Server {
+	handler := HandlerWithOptions(RestController{sagaTimeout: cfg.Saga.Timeout}, ChiServerOptions{
+		Middlewares: []MiddlewareFunc{idempotency(cfg.Idempotency.TTL, cfg.Idempotency.Lease)},
+	})
 	return &http.Server{
 		ReadHeaderTimeout: 1 * time.Minute,
-		Handler:           Handler(RestController{}),
+		Handler:           handler,
 		Addr:              cfg.Addr(),
 	}
 }
 
-type RestController struct{}
+type RestController struct {
+	sagaTimeout time.Duration
+}
 
 func (RestController) PostOrder(w http.ResponseWriter, r *http.Request) {
 	var createOrder CreateOrder
 	handlerDecorator(w, r, WithRequestBody(&createOrder), WithOperation(func(ctx context.Context) (any, error) {
 		orderID := uuid.New()
-		return service.HandleEvent(ctx, orderID, domain.CreateOrder{
+		return service.HandleCommand(ctx, orderID, domain.AnyVersion, domain.CreateOrder{
 			OrderID:    orderID,
 			CustomerID: *createOrder.CustomerId,
 		})
-	}), WithResponseMapper(mapOrder), WithDefaultStatus(http.StatusCreated))
+	}), WithResponseMapper(mapOrder), WithETag(orderETag), WithDefaultStatus(http.StatusCreated))
+}
+
+func (RestController) GetOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, params GetOrderOrderIDParams) {
+	if params.Version != nil && *params.Version < 1 {
+		apiError(r.Context(), w, `version must be positive`, http.StatusBadRequest)
+		return
+	}
+
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		if params.Version != nil {
+			return service.GetOrderVersion(ctx, orderID, *params.Version)
+		}
+		return service.GetOrder(ctx, orderID)
+	}), WithResponseMapper(mapOrder), WithETag(orderETag), WithErrorMapper(mapQueryError))
+}
+
+func (RestController) GetOrderOrderIDEvents(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.GetOrderEvents(ctx, orderID)
+	}), WithResponseMapper(mapOrderEvents), WithErrorMapper(mapQueryError))
+}
+
+func (RestController) GetOrderOrderIDSagas(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.GetOrderSagas(ctx, orderID)
+	}), WithResponseMapper(mapSagas), WithErrorMapper(mapQueryError))
 }
 
-func (RestController) PostOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID) {
+func (RestController) GetCustomersCustomerIDOrders(w http.ResponseWriter, r *http.Request, customerID openapi_types.UUID, params GetCustomersCustomerIDOrdersParams) {
+	err := params.Validate()
+	if err != nil {
+		apiError(r.Context(), w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
-		return service.HandleEvent(ctx, orderID, domain.Process{})
-	}), WithResponseMapper(mapOrder), WithErrorMapper(mapDomainError))
+		return service.ListCustomerOrders(ctx, customerID, mapOrdersFilter(params))
+	}), WithResponseMapper(mapOrdersPage), WithErrorMapper(mapQueryError))
+}
+
+func (c RestController) PostOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, params PostOrderOrderIDParams) {
+	var process ProcessOrder
+	version := domain.AnyVersion
+	handlerDecorator(w, r, WithRequestBody(&process), WithIfMatch(params.IfMatch, &version), WithOperation(func(ctx context.Context) (any, error) {
+		var codes []string
+		if process.PromoCodes != nil {
+			codes = *process.PromoCodes
+		}
+		return service.ProcessOrder(ctx, orderID, version, codes, c.sagaTimeout)
+	}), WithResponseMapper(mapOrder), WithETag(orderETag), WithErrorMapper(mapDomainError))
 }
 
-func (RestController) PutOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID) {
+func (RestController) PostOrderOrderIDReturns(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, params PostOrderOrderIDReturnsParams) {
+	var request ReturnOrder
+	version := domain.AnyVersion
+	handlerDecorator(w, r, WithRequestBody(&request), WithIfMatch(params.IfMatch, &version), WithOperation(func(ctx context.Context) (any, error) {
+		var items []domain.Item
+		if request.Items != nil {
+			for _, item := range *request.Items {
+				items = append(items, mapItemToDomain(item))
+			}
+		}
+		return service.HandleCommand(ctx, orderID, version, domain.RequestReturn{
+			ReturnID: uuid.New(),
+			Items:    items,
+		})
+	}), WithResponseMapper(mapOrder), WithETag(orderETag), WithErrorMapper(mapDomainError))
+}
+
+func (RestController) DeleteOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, params DeleteOrderOrderIDParams) {
+	version := domain.AnyVersion
+	handlerDecorator(w, r, WithIfMatch(params.IfMatch, &version), WithOperation(func(ctx context.Context) (any, error) {
+		return service.HandleCommand(ctx, orderID, version, domain.Cancel{})
+	}), WithResponseMapper(mapOrder), WithETag(orderETag), WithErrorMapper(mapDomainError))
+}
+
+func (RestController) PutOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, params PutOrderOrderIDParams) {
 	var item Item
-	handlerDecorator(w, r, WithRequestBody(&item), WithOperation(func(ctx context.Context) (any, error) {
-		return service.HandleEvent(ctx, orderID, domain.AddItem{
-			Item: *item.Name,
+	version := domain.AnyVersion
+	handlerDecorator(w, r, WithRequestBody(&item), WithIfMatch(params.IfMatch, &version), WithOperation(func(ctx context.Context) (any, error) {
+		return service.HandleCommand(ctx, orderID, version, domain.AddItem{
+			Item: mapItemToDomain(item),
 		})
-	}), WithResponseMapper(mapOrder), WithErrorMapper(mapDomainError))
+	}), WithResponseMapper(mapOrder), WithETag(orderETag), WithErrorMapper(mapDomainError))
 }
 
-func (RestController) DeleteOrderOrderIDItem(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, item string) {
-	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
-		return service.HandleEvent(ctx, orderID, domain.RemoveItem{
-			Item: item,
+func (RestController) DeleteOrderOrderIDItem(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, item string, params DeleteOrderOrderIDItemParams) {
+	attributes, err := mapAttributes(params.Attribute)
+	if err != nil {
+		apiError(r.Context(), w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version := domain.AnyVersion
+	handlerDecorator(w, r, WithIfMatch(params.IfMatch, &version), WithOperation(func(ctx context.Context) (any, error) {
+		return service.HandleCommand(ctx, orderID, version, domain.RemoveItem{
+			SKU:        item,
+			Quantity:   quantityOrDefault(params.Quantity),
+			Attributes: attributes,
 		})
-	}), WithResponseMapper(mapOrder), WithErrorMapper(mapDomainError))
+	}), WithResponseMapper(mapOrder), WithETag(orderETag), WithErrorMapper(mapDomainError))
+}
+
+// orderETag returns version of order, or current version of order on version conflict.
+func orderETag(resp any) string {
+	switch resp := resp.(type) {
+	case domain.OrderView:
+		return strconv.Itoa(resp.Version)
+	case error:
+		var conflict domain.VersionConflict
+		if errors.As(resp, &conflict) {
+			return strconv.Itoa(conflict.Current)
+		}
+	}
+	return ``
+}
+
+func mapQueryError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidCursor):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 func mapDomainError(err error) int {
-	if errors.Is(err, domain.ErrDomain) {
+	var conflict domain.VersionConflict
+	switch {
+	case errors.As(err, &conflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrUnknownProduct),
+		errors.Is(err, domain.ErrUnknownPromotion),
+		errors.Is(err, domain.ErrPromotionExpired),
+		errors.Is(err, domain.ErrPromotionExhausted),
+		errors.Is(err, domain.ErrPromotionApplied),
+		errors.Is(err, domain.ErrPromotionCurrency),
+		errors.Is(err, domain.ErrMixedCurrencies),
+		errors.Is(err, domain.ErrInvalidReturn):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrDomain):
 		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
 	}
-	return http.StatusInternalServerError
 }
@@ -3,8 +3,13 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
 )
 
 type HandlerDecorator struct {
@@ -13,6 +18,9 @@ type HandlerDecorator struct {
 	onSuccess      int
 	operation      func(context.Context) (any, error)
 	responseMapper func(any) any
+	ifMatch        *string
+	version        *int
+	etag           func(any) string
 }
 
 type Option func(*HandlerDecorator)
@@ -41,6 +49,23 @@ func WithRequestBody(request Validated) Option {
 	}
 }
 
+// WithIfMatch parses version from If-Match header before operation,
+// version is left untouched if header is absent or matches any version.
+func WithIfMatch(header *string, version *int) Option {
+	return func(hd *HandlerDecorator) {
+		hd.ifMatch = header
+		hd.version = version
+	}
+}
+
+// WithETag sets ETag header from response or error of operation,
+// empty tag means response has no version.
+func WithETag(tag func(any) string) Option {
+	return func(hd *HandlerDecorator) {
+		hd.etag = tag
+	}
+}
+
 func WithOperation(op func(context.Context) (any, error)) Option {
 	return func(hd *HandlerDecorator) {
 		hd.operation = op
@@ -67,23 +92,50 @@ func handlerDecorator(w http.ResponseWriter, r *http.Request, opts ...Option) {
 			return
 		}
 
-		err = json.Unmarshal(body, decorator.requestBody)
+		// empty body is checked by validation, e.g. it's allowed for optional body.
+		if len(body) != 0 {
+			err = json.Unmarshal(body, decorator.requestBody)
+			if err != nil {
+				apiError(ctx, w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		err = decorator.requestBody.Validate()
 		if err != nil {
 			apiError(ctx, w, err.Error(), http.StatusBadRequest)
 			return
 		}
+	}
 
-		err = decorator.requestBody.Validate()
+	if decorator.ifMatch != nil && decorator.version != nil {
+		version, err := parseIfMatch(*decorator.ifMatch)
 		if err != nil {
 			apiError(ctx, w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		*decorator.version = version
 	}
 
 	resp, err := decorator.operation(ctx)
+	if decorator.etag != nil {
+		var tag string
+		if err != nil {
+			tag = decorator.etag(err)
+		} else {
+			tag = decorator.etag(resp)
+		}
+		if tag != `` {
+			w.Header().Set("ETag", strconv.Quote(tag))
+		}
+	}
+
 	switch err {
 	case nil:
-		apiSuccess(ctx, w, decorator.onSuccess, decorator.responseMapper(resp))
+		if decorator.responseMapper != nil {
+			resp = decorator.responseMapper(resp)
+		}
+		apiSuccess(ctx, w, decorator.onSuccess, resp)
 	default:
 		status := http.StatusInternalServerError
 		if decorator.errMapper != nil {
@@ -93,6 +145,18 @@ func handlerDecorator(w http.ResponseWriter, r *http.Request, opts ...Option) {
 	}
 }
 
+func parseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == `*` {
+		return domain.AnyVersion, nil
+	}
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, `W/`), `"`))
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf(`invalid If-Match header %q`, header)
+	}
+	return version, nil
+}
+
 func apiError(ctx context.Context, w http.ResponseWriter, err string, status int) {
 	body, _ := json.Marshal(Error{
 		Errors: &[]string{err},
@@ -103,6 +167,11 @@ func apiError(ctx context.Context, w http.ResponseWriter, err string, status int
 }
 
 func apiSuccess(ctx context.Context, w http.ResponseWriter, status int, resp any) {
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+
 	body, _ := json.Marshal(resp)
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(status)
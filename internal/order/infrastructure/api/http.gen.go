@@ -7,11 +7,13 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/deepmap/oapi-codegen/pkg/runtime"
 	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
@@ -19,11 +21,40 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+// Defines values for OrderKind.
+const (
+	Active            OrderKind = "active"
+	Canceled          OrderKind = "canceled"
+	Completed         OrderKind = "completed"
+	Empty             OrderKind = "empty"
+	Paid              OrderKind = "paid"
+	PartiallyReturned OrderKind = "partially_returned"
+	Pending           OrderKind = "pending"
+	Returned          OrderKind = "returned"
+	Stocked           OrderKind = "stocked"
+)
+
+// Defines values for PromotionKind.
+const (
+	Fixed      PromotionKind = "fixed"
+	Percentage PromotionKind = "percentage"
+)
+
 // CreateOrder defines model for CreateOrder.
 type CreateOrder struct {
 	CustomerId *openapi_types.UUID `json:"customer_id,omitempty"`
 }
 
+// ISO 4217 currency code of price, amounts are rounded to currency minor units. Order is priced in currency of its items, fixed discount is applicable only to orders in its currency.
+type Currency = string
+
+// Discount defines model for Discount.
+type Discount struct {
+	// amount subtracted from order price
+	Amount *string `json:"amount,omitempty"`
+	Code   *string `json:"code,omitempty"`
+}
+
 // Error defines model for Error.
 type Error struct {
 	Errors *[]string `json:"errors,omitempty"`
@@ -31,60 +62,752 @@ type Error struct {
 
 // Item defines model for Item.
 type Item struct {
-	Name *string `json:"name,omitempty"`
+	Attributes *Item_Attributes `json:"attributes,omitempty"`
+
+	// number of product units, by default one unit
+	Quantity *int    `json:"quantity,omitempty"`
+	Sku      *string `json:"sku,omitempty"`
+
+	// price of one unit, fixed on order processing
+	UnitPrice *string `json:"unit_price,omitempty"`
+}
+
+// Item_Attributes defines model for Item.Attributes.
+type Item_Attributes struct {
+	AdditionalProperties map[string]string `json:"-"`
 }
 
 // Order defines model for Order.
 type Order struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// ISO 4217 currency code of price, amounts are rounded to currency minor units. Order is priced in currency of its items, fixed discount is applicable only to orders in its currency.
+	Currency   *Currency           `json:"currency,omitempty"`
 	CustomerId *openapi_types.UUID `json:"customer_id,omitempty"`
+	Discounts  *[]Discount         `json:"discounts,omitempty"`
 	Id         *openapi_types.UUID `json:"id,omitempty"`
-	Items      *[]string           `json:"items,omitempty"`
+	Items      *[]Item             `json:"items,omitempty"`
 	PaymentId  *openapi_types.UUID `json:"payment_id,omitempty"`
+
+	// total price of order, fixed on order processing
+	Price     *string    `json:"price,omitempty"`
+	Returns   *[]Return  `json:"returns,omitempty"`
+	State     *OrderKind `json:"state,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// OrderHistoryEvent defines model for OrderHistoryEvent.
+type OrderHistoryEvent struct {
+	CreatedAt *time.Time                 `json:"created_at,omitempty"`
+	Payload   *OrderHistoryEvent_Payload `json:"payload,omitempty"`
+	Type      *string                    `json:"type,omitempty"`
+
+	// version of order after event
+	Version *int `json:"version,omitempty"`
+}
+
+// OrderHistoryEvent_Payload defines model for OrderHistoryEvent.Payload.
+type OrderHistoryEvent_Payload struct {
+	AdditionalProperties map[string]interface{} `json:"-"`
+}
+
+// OrderKind defines model for OrderKind.
+type OrderKind string
+
+// OrderList defines model for OrderList.
+type OrderList struct {
+	// cursor of the next page, absent on the last page
+	NextCursor *string  `json:"next_cursor,omitempty"`
+	Orders     *[]Order `json:"orders,omitempty"`
+}
+
+// ProcessOrder defines model for ProcessOrder.
+type ProcessOrder struct {
+	PromoCodes *[]string `json:"promo_codes,omitempty"`
+}
+
+// Product defines model for Product.
+type Product struct {
+	// ISO 4217 currency code of price, amounts are rounded to currency minor units. Order is priced in currency of its items, fixed discount is applicable only to orders in its currency.
+	Currency *Currency `json:"currency,omitempty"`
+	Name     *string   `json:"name,omitempty"`
+
+	// price of one unit
+	Price *string `json:"price,omitempty"`
+	Sku   *string `json:"sku,omitempty"`
+}
+
+// Promotion defines model for Promotion.
+type Promotion struct {
+	Code *string `json:"code,omitempty"`
+
+	// ISO 4217 currency code of price, amounts are rounded to currency minor units. Order is priced in currency of its items, fixed discount is applicable only to orders in its currency.
+	Currency  *Currency      `json:"currency,omitempty"`
+	ExpiresAt *time.Time     `json:"expires_at,omitempty"`
+	Kind      *PromotionKind `json:"kind,omitempty"`
+
+	// how many times one customer can use code, zero means unlimited
+	UsageLimit *int `json:"usage_limit,omitempty"`
+
+	// percent for percentage discount or amount for fixed one
+	Value *string `json:"value,omitempty"`
+}
+
+// PromotionKind defines model for Promotion.Kind.
+type PromotionKind string
+
+// Return defines model for Return.
+type Return struct {
+	Id    *openapi_types.UUID `json:"id,omitempty"`
+	Items *[]Item             `json:"items,omitempty"`
+
+	// amount refunded to customer
+	Refund    *string `json:"refund,omitempty"`
+	Refunded  *bool   `json:"refunded,omitempty"`
+	Restocked *bool   `json:"restocked,omitempty"`
+}
+
+// ReturnOrder defines model for ReturnOrder.
+type ReturnOrder struct {
+	Items *[]Item `json:"items,omitempty"`
+}
+
+// Saga defines model for Saga.
+type Saga struct {
+	CorrelationId *string             `json:"correlation_id,omitempty"`
+	CreatedAt     *time.Time          `json:"created_at,omitempty"`
+	Id            *openapi_types.UUID `json:"id,omitempty"`
+	Name          *string             `json:"name,omitempty"`
+
+	// running, completed or aborted
+	State     *string     `json:"state,omitempty"`
+	Steps     *[]SagaStep `json:"steps,omitempty"`
+	UpdatedAt *time.Time  `json:"updated_at,omitempty"`
+}
+
+// SagaStep defines model for SagaStep.
+type SagaStep struct {
+	Attempts      *int    `json:"attempts,omitempty"`
+	CorrelationId *string `json:"correlation_id,omitempty"`
+	LastError     *string `json:"last_error,omitempty"`
+	Name          *string `json:"name,omitempty"`
+
+	// pending, succeeded, failed or compensated
+	Status    *string    `json:"status,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// UpdateProduct defines model for UpdateProduct.
+type UpdateProduct struct {
+	// ISO 4217 currency code of price, amounts are rounded to currency minor units. Order is priced in currency of its items, fixed discount is applicable only to orders in its currency.
+	Currency *Currency `json:"currency,omitempty"`
+	Name     *string   `json:"name,omitempty"`
+
+	// price of one unit
+	Price *string `json:"price,omitempty"`
+}
+
+// IfMatch defines model for IfMatch.
+type IfMatch = string
+
+// GetCustomersCustomerIDOrdersParams defines parameters for GetCustomersCustomerIDOrders.
+type GetCustomersCustomerIDOrdersParams struct {
+	// opaque cursor of the next page returned by previous request
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// page size, by default 20 orders
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// filter orders by state
+	Kind        *[]OrderKind `form:"kind,omitempty" json:"kind,omitempty"`
+	CreatedFrom *time.Time   `form:"created_from,omitempty" json:"created_from,omitempty"`
+	CreatedTo   *time.Time   `form:"created_to,omitempty" json:"created_to,omitempty"`
+	UpdatedFrom *time.Time   `form:"updated_from,omitempty" json:"updated_from,omitempty"`
+	UpdatedTo   *time.Time   `form:"updated_to,omitempty" json:"updated_to,omitempty"`
+}
+
+// DeleteOrderOrderIDParams defines parameters for DeleteOrderOrderID.
+type DeleteOrderOrderIDParams struct {
+	// order version from ETag, order is changed only if it has this version
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// GetOrderOrderIDParams defines parameters for GetOrderOrderID.
+type GetOrderOrderIDParams struct {
+	// version of order from its history, by default the latest state
+	Version *int `form:"version,omitempty" json:"version,omitempty"`
+}
+
+// PostOrderOrderIDJSONBody defines parameters for PostOrderOrderID.
+type PostOrderOrderIDJSONBody = ProcessOrder
+
+// PostOrderOrderIDParams defines parameters for PostOrderOrderID.
+type PostOrderOrderIDParams struct {
+	// order version from ETag, order is changed only if it has this version
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// PutOrderOrderIDJSONBody defines parameters for PutOrderOrderID.
+type PutOrderOrderIDJSONBody = Item
+
+// PutOrderOrderIDParams defines parameters for PutOrderOrderID.
+type PutOrderOrderIDParams struct {
+	// order version from ETag, order is changed only if it has this version
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// PostOrderOrderIDReturnsJSONBody defines parameters for PostOrderOrderIDReturns.
+type PostOrderOrderIDReturnsJSONBody = ReturnOrder
+
+// PostOrderOrderIDReturnsParams defines parameters for PostOrderOrderIDReturns.
+type PostOrderOrderIDReturnsParams struct {
+	// order version from ETag, order is changed only if it has this version
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// DeleteOrderOrderIDItemParams defines parameters for DeleteOrderOrderIDItem.
+type DeleteOrderOrderIDItemParams struct {
+	// number of units to remove, by default one unit
+	Quantity *int `form:"quantity,omitempty" json:"quantity,omitempty"`
+
+	// attribute of item line as name:value, e.g. color:red, by default line without attributes
+	Attribute *[]string `form:"attribute,omitempty" json:"attribute,omitempty"`
+
+	// order version from ETag, order is changed only if it has this version
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// PostProductsJSONBody defines parameters for PostProducts.
+type PostProductsJSONBody = Product
+
+// PutProductsSkuJSONBody defines parameters for PutProductsSku.
+type PutProductsSkuJSONBody = UpdateProduct
+
+// PostPromotionsJSONBody defines parameters for PostPromotions.
+type PostPromotionsJSONBody = Promotion
+
+// PostOrderOrderIDJSONRequestBody defines body for PostOrderOrderID for application/json ContentType.
+type PostOrderOrderIDJSONRequestBody = PostOrderOrderIDJSONBody
+
+// PutOrderOrderIDJSONRequestBody defines body for PutOrderOrderID for application/json ContentType.
+type PutOrderOrderIDJSONRequestBody = PutOrderOrderIDJSONBody
+
+// PostOrderOrderIDReturnsJSONRequestBody defines body for PostOrderOrderIDReturns for application/json ContentType.
+type PostOrderOrderIDReturnsJSONRequestBody = PostOrderOrderIDReturnsJSONBody
+
+// PostProductsJSONRequestBody defines body for PostProducts for application/json ContentType.
+type PostProductsJSONRequestBody = PostProductsJSONBody
+
+// PutProductsSkuJSONRequestBody defines body for PutProductsSku for application/json ContentType.
+type PutProductsSkuJSONRequestBody = PutProductsSkuJSONBody
+
+// PostPromotionsJSONRequestBody defines body for PostPromotions for application/json ContentType.
+type PostPromotionsJSONRequestBody = PostPromotionsJSONBody
+
+// Getter for additional properties for Item_Attributes. Returns the specified
+// element and whether it was found
+func (a Item_Attributes) Get(fieldName string) (value string, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for Item_Attributes
+func (a *Item_Attributes) Set(fieldName string, value string) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]string)
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for Item_Attributes to handle AdditionalProperties
+func (a *Item_Attributes) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]string)
+		for fieldName, fieldBuf := range object {
+			var fieldVal string
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for Item_Attributes to handle AdditionalProperties
+func (a Item_Attributes) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// Getter for additional properties for OrderHistoryEvent_Payload. Returns the specified
+// element and whether it was found
+func (a OrderHistoryEvent_Payload) Get(fieldName string) (value interface{}, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for OrderHistoryEvent_Payload
+func (a *OrderHistoryEvent_Payload) Set(fieldName string, value interface{}) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]interface{})
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for OrderHistoryEvent_Payload to handle AdditionalProperties
+func (a *OrderHistoryEvent_Payload) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]interface{})
+		for fieldName, fieldBuf := range object {
+			var fieldVal interface{}
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for OrderHistoryEvent_Payload to handle AdditionalProperties
+func (a OrderHistoryEvent_Payload) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// List customer orders, newest first
+	// (GET /customers/{customerID}/orders)
+	GetCustomersCustomerIDOrders(w http.ResponseWriter, r *http.Request, customerID openapi_types.UUID, params GetCustomersCustomerIDOrdersParams)
+	// Create new order
+	// (POST /order)
+	PostOrder(w http.ResponseWriter, r *http.Request)
+	// cancel order, order being processed will be compensated by saga
+	// (DELETE /order/{orderID})
+	DeleteOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, params DeleteOrderOrderIDParams)
+	// Get order with its saga outcome
+	// (GET /order/{orderID})
+	GetOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, params GetOrderOrderIDParams)
+	// close order and send to process payments
+	// (POST /order/{orderID})
+	PostOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, params PostOrderOrderIDParams)
+	// Add item to order
+	// (PUT /order/{orderID})
+	PutOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, params PutOrderOrderIDParams)
+	// History of order changes
+	// (GET /order/{orderID}/events)
+	GetOrderOrderIDEvents(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID)
+	// return items of completed order, payment is refunded and items restocked by saga
+	// (POST /order/{orderID}/returns)
+	PostOrderOrderIDReturns(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, params PostOrderOrderIDReturnsParams)
+	// Sagas of order with status of their steps
+	// (GET /order/{orderID}/sagas)
+	GetOrderOrderIDSagas(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID)
+	// Remove item from order
+	// (DELETE /order/{orderID}/{item})
+	DeleteOrderOrderIDItem(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, item string, params DeleteOrderOrderIDItemParams)
+	// List catalog products
+	// (GET /products)
+	GetProducts(w http.ResponseWriter, r *http.Request)
+	// Add product to catalog
+	// (POST /products)
+	PostProducts(w http.ResponseWriter, r *http.Request)
+	// Remove product from catalog
+	// (DELETE /products/{sku})
+	DeleteProductsSku(w http.ResponseWriter, r *http.Request, sku string)
+	// Get catalog product
+	// (GET /products/{sku})
+	GetProductsSku(w http.ResponseWriter, r *http.Request, sku string)
+	// Update catalog product
+	// (PUT /products/{sku})
+	PutProductsSku(w http.ResponseWriter, r *http.Request, sku string)
+	// List discount codes
+	// (GET /promotions)
+	GetPromotions(w http.ResponseWriter, r *http.Request)
+	// Create discount code
+	// (POST /promotions)
+	PostPromotions(w http.ResponseWriter, r *http.Request)
+	// Delete discount code
+	// (DELETE /promotions/{code})
+	DeletePromotionsCode(w http.ResponseWriter, r *http.Request, code string)
+	// Get discount code
+	// (GET /promotions/{code})
+	GetPromotionsCode(w http.ResponseWriter, r *http.Request, code string)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.HandlerFunc) http.HandlerFunc
+
+// GetCustomersCustomerIDOrders operation middleware
+func (siw *ServerInterfaceWrapper) GetCustomersCustomerIDOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "customerID" -------------
+	var customerID openapi_types.UUID
+
+	err = runtime.BindStyledParameter("simple", false, "customerID", chi.URLParam(r, "customerID"), &customerID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "customerID", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetCustomersCustomerIDOrdersParams
+
+	// ------------- Optional query parameter "cursor" -------------
+	if paramValue := r.URL.Query().Get("cursor"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+	if paramValue := r.URL.Query().Get("limit"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "kind" -------------
+	if paramValue := r.URL.Query().Get("kind"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "kind", r.URL.Query(), &params.Kind)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "kind", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "created_from" -------------
+	if paramValue := r.URL.Query().Get("created_from"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "created_from", r.URL.Query(), &params.CreatedFrom)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "created_from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "created_to" -------------
+	if paramValue := r.URL.Query().Get("created_to"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "created_to", r.URL.Query(), &params.CreatedTo)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "created_to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "updated_from" -------------
+	if paramValue := r.URL.Query().Get("updated_from"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "updated_from", r.URL.Query(), &params.UpdatedFrom)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "updated_from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "updated_to" -------------
+	if paramValue := r.URL.Query().Get("updated_to"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "updated_to", r.URL.Query(), &params.UpdatedTo)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "updated_to", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetCustomersCustomerIDOrders(w, r, customerID, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostOrder operation middleware
+func (siw *ServerInterfaceWrapper) PostOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostOrder(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// DeleteOrderOrderID operation middleware
+func (siw *ServerInterfaceWrapper) DeleteOrderOrderID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "orderID" -------------
+	var orderID openapi_types.UUID
+
+	err = runtime.BindStyledParameter("simple", false, "orderID", chi.URLParam(r, "orderID"), &orderID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "orderID", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteOrderOrderIDParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteOrderOrderID(w, r, orderID, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
 }
 
-// PutOrderOrderIDJSONBody defines parameters for PutOrderOrderID.
-type PutOrderOrderIDJSONBody = Item
+// GetOrderOrderID operation middleware
+func (siw *ServerInterfaceWrapper) GetOrderOrderID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "orderID" -------------
+	var orderID openapi_types.UUID
+
+	err = runtime.BindStyledParameter("simple", false, "orderID", chi.URLParam(r, "orderID"), &orderID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "orderID", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetOrderOrderIDParams
+
+	// ------------- Optional query parameter "version" -------------
+	if paramValue := r.URL.Query().Get("version"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "version", r.URL.Query(), &params.Version)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "version", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetOrderOrderID(w, r, orderID, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostOrderOrderID operation middleware
+func (siw *ServerInterfaceWrapper) PostOrderOrderID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "orderID" -------------
+	var orderID openapi_types.UUID
+
+	err = runtime.BindStyledParameter("simple", false, "orderID", chi.URLParam(r, "orderID"), &orderID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "orderID", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostOrderOrderIDParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostOrderOrderID(w, r, orderID, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PutOrderOrderID operation middleware
+func (siw *ServerInterfaceWrapper) PutOrderOrderID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "orderID" -------------
+	var orderID openapi_types.UUID
+
+	err = runtime.BindStyledParameter("simple", false, "orderID", chi.URLParam(r, "orderID"), &orderID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "orderID", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PutOrderOrderIDParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
 
-// DeleteOrderOrderIDItemJSONBody defines parameters for DeleteOrderOrderIDItem.
-type DeleteOrderOrderIDItemJSONBody = Item
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
 
-// PutOrderOrderIDJSONRequestBody defines body for PutOrderOrderID for application/json ContentType.
-type PutOrderOrderIDJSONRequestBody = PutOrderOrderIDJSONBody
+		params.IfMatch = &IfMatch
 
-// DeleteOrderOrderIDItemJSONRequestBody defines body for DeleteOrderOrderIDItem for application/json ContentType.
-type DeleteOrderOrderIDItemJSONRequestBody = DeleteOrderOrderIDItemJSONBody
+	}
 
-// ServerInterface represents all server handlers.
-type ServerInterface interface {
-	// Create new order
-	// (POST /order)
-	PostOrder(w http.ResponseWriter, r *http.Request)
-	// close order and send to process payments
-	// (POST /order/{orderID})
-	PostOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID)
-	// Add item to order
-	// (PUT /order/{orderID})
-	PutOrderOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID)
-	// Remove item from order
-	// (DELETE /order/{orderID}/{item})
-	DeleteOrderOrderIDItem(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID, item string)
-}
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutOrderOrderID(w, r, orderID, params)
+	}
 
-// ServerInterfaceWrapper converts contexts to parameters.
-type ServerInterfaceWrapper struct {
-	Handler            ServerInterface
-	HandlerMiddlewares []MiddlewareFunc
-	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
-}
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
 
-type MiddlewareFunc func(http.HandlerFunc) http.HandlerFunc
+	handler(w, r.WithContext(ctx))
+}
 
-// PostOrder operation middleware
-func (siw *ServerInterfaceWrapper) PostOrder(w http.ResponseWriter, r *http.Request) {
+// GetOrderOrderIDEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetOrderOrderIDEvents(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	var err error
+
+	// ------------- Path parameter "orderID" -------------
+	var orderID openapi_types.UUID
+
+	err = runtime.BindStyledParameter("simple", false, "orderID", chi.URLParam(r, "orderID"), &orderID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "orderID", Err: err})
+		return
+	}
+
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostOrder(w, r)
+		siw.Handler.GetOrderOrderIDEvents(w, r, orderID)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -94,8 +817,8 @@ func (siw *ServerInterfaceWrapper) PostOrder(w http.ResponseWriter, r *http.Requ
 	handler(w, r.WithContext(ctx))
 }
 
-// PostOrderOrderID operation middleware
-func (siw *ServerInterfaceWrapper) PostOrderOrderID(w http.ResponseWriter, r *http.Request) {
+// PostOrderOrderIDReturns operation middleware
+func (siw *ServerInterfaceWrapper) PostOrderOrderIDReturns(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
@@ -109,8 +832,32 @@ func (siw *ServerInterfaceWrapper) PostOrderOrderID(w http.ResponseWriter, r *ht
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostOrderOrderIDReturnsParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostOrderOrderID(w, r, orderID)
+		siw.Handler.PostOrderOrderIDReturns(w, r, orderID, params)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -120,8 +867,8 @@ func (siw *ServerInterfaceWrapper) PostOrderOrderID(w http.ResponseWriter, r *ht
 	handler(w, r.WithContext(ctx))
 }
 
-// PutOrderOrderID operation middleware
-func (siw *ServerInterfaceWrapper) PutOrderOrderID(w http.ResponseWriter, r *http.Request) {
+// GetOrderOrderIDSagas operation middleware
+func (siw *ServerInterfaceWrapper) GetOrderOrderIDSagas(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
@@ -136,7 +883,7 @@ func (siw *ServerInterfaceWrapper) PutOrderOrderID(w http.ResponseWriter, r *htt
 	}
 
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PutOrderOrderID(w, r, orderID)
+		siw.Handler.GetOrderOrderIDSagas(w, r, orderID)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -170,8 +917,244 @@ func (siw *ServerInterfaceWrapper) DeleteOrderOrderIDItem(w http.ResponseWriter,
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteOrderOrderIDItemParams
+
+	// ------------- Optional query parameter "quantity" -------------
+	if paramValue := r.URL.Query().Get("quantity"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "quantity", r.URL.Query(), &params.Quantity)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "quantity", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "attribute" -------------
+	if paramValue := r.URL.Query().Get("attribute"); paramValue != "" {
+
+	}
+
+	err = runtime.BindQueryParameter("form", true, false, "attribute", r.URL.Query(), &params.Attribute)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "attribute", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteOrderOrderIDItem(w, r, orderID, item, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetProducts operation middleware
+func (siw *ServerInterfaceWrapper) GetProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetProducts(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostProducts operation middleware
+func (siw *ServerInterfaceWrapper) PostProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostProducts(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// DeleteProductsSku operation middleware
+func (siw *ServerInterfaceWrapper) DeleteProductsSku(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sku" -------------
+	var sku string
+
+	err = runtime.BindStyledParameter("simple", false, "sku", chi.URLParam(r, "sku"), &sku)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sku", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteProductsSku(w, r, sku)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetProductsSku operation middleware
+func (siw *ServerInterfaceWrapper) GetProductsSku(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sku" -------------
+	var sku string
+
+	err = runtime.BindStyledParameter("simple", false, "sku", chi.URLParam(r, "sku"), &sku)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sku", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetProductsSku(w, r, sku)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PutProductsSku operation middleware
+func (siw *ServerInterfaceWrapper) PutProductsSku(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sku" -------------
+	var sku string
+
+	err = runtime.BindStyledParameter("simple", false, "sku", chi.URLParam(r, "sku"), &sku)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sku", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutProductsSku(w, r, sku)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetPromotions operation middleware
+func (siw *ServerInterfaceWrapper) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetPromotions(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostPromotions operation middleware
+func (siw *ServerInterfaceWrapper) PostPromotions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPromotions(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// DeletePromotionsCode operation middleware
+func (siw *ServerInterfaceWrapper) DeletePromotionsCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "code" -------------
+	var code string
+
+	err = runtime.BindStyledParameter("simple", false, "code", chi.URLParam(r, "code"), &code)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "code", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeletePromotionsCode(w, r, code)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetPromotionsCode operation middleware
+func (siw *ServerInterfaceWrapper) GetPromotionsCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "code" -------------
+	var code string
+
+	err = runtime.BindStyledParameter("simple", false, "code", chi.URLParam(r, "code"), &code)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "code", Err: err})
+		return
+	}
+
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.DeleteOrderOrderIDItem(w, r, orderID, item)
+		siw.Handler.GetPromotionsCode(w, r, code)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -294,18 +1277,63 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/customers/{customerID}/orders", wrapper.GetCustomersCustomerIDOrders)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/order", wrapper.PostOrder)
 	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/order/{orderID}", wrapper.DeleteOrderOrderID)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/order/{orderID}", wrapper.GetOrderOrderID)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/order/{orderID}", wrapper.PostOrderOrderID)
 	})
 	r.Group(func(r chi.Router) {
 		r.Put(options.BaseURL+"/order/{orderID}", wrapper.PutOrderOrderID)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/order/{orderID}/events", wrapper.GetOrderOrderIDEvents)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/order/{orderID}/returns", wrapper.PostOrderOrderIDReturns)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/order/{orderID}/sagas", wrapper.GetOrderOrderIDSagas)
+	})
 	r.Group(func(r chi.Router) {
 		r.Delete(options.BaseURL+"/order/{orderID}/{item}", wrapper.DeleteOrderOrderIDItem)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/products", wrapper.GetProducts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/products", wrapper.PostProducts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/products/{sku}", wrapper.DeleteProductsSku)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/products/{sku}", wrapper.GetProductsSku)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/products/{sku}", wrapper.PutProductsSku)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/promotions", wrapper.GetPromotions)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/promotions", wrapper.PostPromotions)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/promotions/{code}", wrapper.DeletePromotionsCode)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/promotions/{code}", wrapper.GetPromotionsCode)
+	})
 
 	return r
 }
@@ -313,17 +1341,52 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xVwW7bMAz9FYPb0ajTrrv41q075NRiOw7FoNpMq8ISNZLOEAT+90Gyk6azswVF1+2Q",
-	"U2Safnp8fGTWUJEL5NGrQLkGqe7RmXT8yGgUr7hGjo+BKSCrxfSyakXJIX+zdXxcEDujUELb2hpy0FVA",
-	"KEGUrb+DrttG6PYBK4Uuh0/MNAGMMZxOVtGlwy9gWyzDbFbT4HNFN8b2xuEE4BTAi5Sdw6Fpm1IPrTmH",
-	"YFYOvT5X/xiyfkGpKPJqKoVyHfOsNjExCSCZIC9thdnF9RxyWCKLJQ8lnJ7MTmaRBwX0Jlgo4V0KRWZ6",
-	"n2ooaCsiSYSPyWzUkp/XUMI1ifY658D4vUXRD1SvNpTQp29MCLZKHxUPEu/emDSe3jIuoIQ3xaOLi8HC",
-	"xa5/U701SsU2aF9BepFF4YbrLWMNpXKLXQxIIC99189mp2NSzfNY7eXzpRVBkUXbNKusStyzXsAuh/ez",
-	"2Ysx6AdvgsHcK7I3zbbpOGTmIK1zhldQDlsh8/hjw67Lh04X6/Qzv+wO6PlVn5r8wsahYpz6r2uwkUr0",
-	"EOTDwAJtc5/2Kd+p+E8TcDPq6Xn8eapAuigT9DXWmVIWmCoUGUbw/PTs7zeht2XrzW2DkUJ1b/wdyv9l",
-	"gqohGdyZGV8nxXb0yobllFiHdsoG7T90wW82zTPVTH83E2LGdX7oipm98ooxdZ0lfkqPe+Zo8Y3FL8by",
-	"TCy6Yh1zun6TNKg4tvpliu+6PbnltRyfTyLbnsJ+2OPg7B0cRkdL7M2xYHLH6RlPz+c9GqUs5OXG9C03",
-	"UEIB3U33MwAA//88DVJRigwAAA==",
+	"H4sIAAAAAAAC/+xbX2/bOBL/KgTvgHtRYzeXw2H9ttcUe0FvkaC5Pm2LgJHGNjcSqfJPUq3h737gkJIs",
+	"i3KUf4732qemEjUczvxm5schvaKpLEopQBhNZyu6BJaBwj/f/5ct3L8Z6FTx0nAp6IymVikQhtyC0lwK",
+	"IudEqgwUTahOl1Aw94mpSqAzqo3iYkHX63VCS6ZYASbIPpv/yky67ItHWY3wuZIFcXokfhLCNUmXTCwg",
+	"I1LkFeFzwg1ZMk3Mkuv6O5pQ7oT5xdCEClY4fc7mb/y09+jqX6Ki7xQwA+e4wtmKlkqWoAwHfJlabWQB",
+	"6opn7r9zqQpm6IxayzOa9CQ3T+T175Aauk7oO7RmWvUNcXZ5Tk6O3/6TpGEISWUGztyl4ikkhBXSCqMJ",
+	"U0CUtCKDjBjZDi+4kIpYwY0+Iue19fDjjHDRDpTOhppwA4VOyJx/g4xkXKdOvPuElWXOU3adg7e5kd4Z",
+	"2klxX9aSjmhC4Rsrytwt8tPlad8GCT0NkvvW9AvqW8I/J9peG8VSA5mHhQcEric2j7NWzL0RJ7xXSka8",
+	"C+4x/oWmiQhrZDGlWBUXfmagiKzVGMWvrQn/yzLuFsvyi86ooela6V8tE4abCH6ELa5BebzIzKbGQyEh",
+	"1xXJYM5sbogUgE9b+3FhYAHKidY3NqqD++DKm703KT52c9aSazxJ0fhLpqC1k5VQBSw7F3lFZ0ZZGBUw",
+	"Q5GIYZpdMdMJxIwZeGN4AfdPltB0Ixb/qmBOZ/QvkzY7TkJWmDQxu04emAESWsdVF1a7ZmviZT24hIC+",
+	"hI5Uopl3lAII4HV/spJVBQgzduUDiDHSsJy0uHHufRJo3ABjlRi/wI84fox9tWEG7hOHCP3ARYbBUmZP",
+	"xOVgEPybayNV9f4WhHlUQPRdxKpcsmw4I3UUbNXxDyKpoi7HPa9vkwfC5gYUAVxLPxkN2gDNPFtRELag",
+	"s98oFKWpaEJZavitW2MJIvOw0UamN+CgWTJEqHNdDgYfpUykkIe3ynCW59WVxxE+bP78EjEbavIfriNe",
+	"EPDNXKVWaan6RvDPnQ3MEogbSkq2cJX9WoNw2Rlf5Ez7FzGX+UI8Guqo6ri6deFjbiDflkoW8srV2CcX",
+	"yAtfnmLs6uH52BO91ej00ytYMSPHK+HAWgppAuS3VhPnI48rO/Ct5Ar0g6L7ZitWSlApCOOBhQk3im6r",
+	"2QKucl7wCDVbyjtSMFERN6lGI9YVkaRMEKsBaWtC/gAlSQFMaGIFSoMsSjtuWW5jjvLakrlUpNW85alS",
+	"BUKMI+r6AeNYeKgAPZ/ttZ4qmFuR9VceluVf1xzf25hGq58ft4G1aylzYKHC1Xkw8nrYNANZ4DkWHpv0",
+	"ki1YLIKUgpw5swTS0Y+lR1S9kU4ezCwNKei6TVkhuFgkpKkziNFrqTrI35QD5XhjOhNdGihjSBpBOkYE",
+	"RTNDbPPiCu1mwt8I4BFuciXtCupd18NMbXUsOWCVT4i2aQqQQZaQOeO5t7mzHgjNBuz+PNb6hFIOvZb1",
+	"NXePuJhLH2DCMNR+nWzJ/tUaZrhYEAVfLWijCUtTKA2RpaeJ5CyDopTGKf/mA1TE916OyEfQpRQaap4z",
+	"50qbzyLIIXfcLPH5DVSu2aCgzFnl9vhSEQVGcXAVw/AcR4TC58TaVuYNVJ+F+4AJaZagai29QLdQyPxM",
+	"J8fHCYqtyN2S59CqVH/zWXBsbJRKLhRoHZEx/enos3DG5QY7Hee+G6JB3ToP/HxxRje4L317ND2aIlkr",
+	"QbCS0xn9Oz5yZNMsER+TOqXryar+8+x0PWn53QIQVg5UGFtnGZ3RX8C8qz9813zm9aHdnttvK98Tc1O2",
+	"HbF2LmS5Xy1XkNVEv+2R3dvZ6rXxSvbVAhmguKTm064ZUSq45dLq2gF18+6rBVVtaupE7ezc9bTAuTT/",
+	"Azpdj+Np6F8NzOS5zuZEBfvGC8ea3k6nCS24CP+L7VS2dZjz3IAKMzo1fL2IT40cbXPm8ay+3nFuV9dV",
+	"3JqhULpOGo16emf+2y3UyGcTWSfnZ9WzFvoYPb8gh8KMhn45nk7r1Bn24aFd6tw/+V37rUA7yb1+xI0k",
+	"puUuji4cluW85dcBw+uEnjyjDr4XGpn/TNyynGekwXMIbqfAP/ajgAHlKk2dZyGMTKi2RcFURWfUWW/b",
+	"RAkRcOeqAeZ5/MDnVSzTUkfy6oXU5jycaoS09C+ZVf1FPm6Nm4cKkZXiC1cAi15SXvfg9/Z54RfT59Jq",
+	"DVrPbZ5XxAd5c+YTOS+KzRGGTXDMen1QmPHecCAJq2oRMlnhP2ena0+3HI/vo+UUn6P5zv3wfu2Nqd8O",
+	"mdTnYeskWqZlI/bxNfrFM9cwlJGWNwBqe22PBs/J2+OXB4/X3Qo8+TIyKO4yHzfkjrUnkZqLFLpnls1J",
+	"40EhvVkBNtnxH3INjtaHDjtS3Dwn17C5a0LW4rbk62SQhu5G/wtBOrm3r4zO4EaTpe+Wd4ig764aVxp2",
+	"kbL2ULnVbCcHfL1Ie1JMTU/2FVNCujadFdlhhccvYAJscJ/nYONgT6Q1qSwAt9676cKhp/8dTOaRFu8c",
+	"E0QM3zRo8azA3yXwTUxZn0VsxcrJ0KUQDXX/s3Me+CcrIlgz9MOryMnx3rR1czCOnfobIe9EfYMg8XdE",
+	"XHck4/M54D2g0Nnifk0dd7uK+Tfjiklw+4FVw1xqqM8fRYb42kAXCQfcuMUqbSzs7fca9b6V37e7w8fY",
+	"jct0zxsXlmWI3zb3fDe543Bi7ueeE2KbrQleBNjZ9twMvPd+9L5Y51Pp3fieXueKR7+3NwAYbzxvXk/e",
+	"awb7g+XRGQ1GbfcIIaziSNy4TDSO+30MH3w/xWDziDjilKbXHy6YbmzAWJ77p+4wJl2SO1DgGEPzSQWG",
+	"vkrl8GvqNi6C7f40nQuunS07x88HSzkzCUFbpJ6kCxqnulkCV6S573pQKcVrW+s679gcWy2BSvqzxHCP",
+	"w1FO/0VzKaNttMRSkXszuiZe4uD/q5J4GVpQ46ogWmuzCGrDlMGLSocFnkuv6Hyz9eAvOoRzU66IvxsS",
+	"RcXK2e6B7Wmk7wdYoHr9vMsPn/zPE1DfyKzhzfCU907R3pPH+/GOmCoo5C0M3ZSPtQibtBSZesfZcPMr",
+	"gHqRJOcCCNPEyZ3hVbiEwNHiiKQyl2qmIOuohcMdYqQ1pJE2dLDdDIgfMd93d/PLq2/hvGO8pdrfgPzY",
+	"x+0/aX2MewIzVOgW7SxVF/WYfRSOMNmY2oHnx+1vZvQhnm4zw3K56Kg4vDXpGPpF2r/etP0VBf32f469",
+	"Q6deQ6hsobHXWxSdeX96+XmDTQjLFbDM3aPj2ujD6w3VoDGyxnk3p0xW+saOYDs17C9vLB1zwhArMx0f",
+	"nezPRwfaN/nYsYrP+o2PknszfdQT030E/cUPP26fcm7VELoes1HUN7aXx3dR7S/DxybboHj+wtS9j/30",
+	"8jTde3nytxQPo0L9iBqPp37ghOLkf/V1H+WtR+2J9PrpxtDe084p/QGS3qyn4E7Ku2noFyG9tWmjOPYv",
+	"X4X4Dut12j2Z91e1XyOrtKjcC/PtrvuQ+W+4h9qB+naCmazc03EEOHzyzokZ9VsQP/BBBf5+Zt21v1c6",
+	"21tV6c5+oLXFe2zb8cmISrJP305fIU/9wMk2c9/ODm4AqNva9VbldEYndP1l/b8BABLiaYfaSQAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
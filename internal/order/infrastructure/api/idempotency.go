@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/moeryomenko/saga/internal/order/service"
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+const idempotencyKeyHeader = `Idempotency-Key`
+
+// replayedHeaders are response headers stored with idempotent response.
+var replayedHeaders = []string{`Content-Type`, `ETag`}
+
+// storeTimeout limits storing of response, which outlives request context.
+const storeTimeout = 5 * time.Second
+
+// idempotency replays stored response for mutating requests with the same
+// Idempotency-Key header, requests without key are passed as is.
+func idempotency(ttl, lease time.Duration) MiddlewareFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == `` || r.Method == http.MethodGet || r.Method == http.MethodHead {
+				next(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				apiError(ctx, w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_ = r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			stored, token, err := service.ReserveIdempotencyKey(ctx, key, fingerprint(r, body), ttl, lease)
+			switch {
+			case err == nil:
+			case errors.Is(err, service.ErrIdempotencyKeyReused):
+				apiError(ctx, w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			case errors.Is(err, service.ErrRequestInProgress):
+				apiError(ctx, w, err.Error(), http.StatusConflict)
+				return
+			default:
+				apiError(ctx, w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if stored != nil {
+				for header, value := range stored.Headers {
+					w.Header().Set(header, value)
+				}
+				w.WriteHeader(stored.Status)
+				_, _ = w.Write(stored.Body)
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next(recorder, r)
+
+			// response is stored even if client is gone, since order was already changed.
+			storeCtx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+			defer cancel()
+
+			// server errors aren't cached, so client is able to retry request.
+			if recorder.status >= http.StatusInternalServerError {
+				err = service.ReleaseIdempotencyKey(storeCtx, key, token)
+			} else {
+				err = service.SaveIdempotentResponse(storeCtx, key, token, recorder.response())
+			}
+			// key of lost lease belongs to retry, which stores its own response.
+			if err != nil && !errors.Is(err, service.ErrLeaseLost) {
+				_ = service.ReleaseIdempotencyKey(storeCtx, key, token)
+			}
+		}
+	}
+}
+
+// fingerprint identifies request by its method, target, precondition and body.
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	for _, part := range []string{r.Method, r.URL.RequestURI(), r.Header.Get(`If-Match`)} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) response() service.IdempotentResponse {
+	headers := make(map[string]string, len(replayedHeaders))
+	for _, header := range replayedHeaders {
+		if value := r.Header().Get(header); value != `` {
+			headers[header] = value
+		}
+	}
+	return service.IdempotentResponse{
+		Status:  r.status,
+		Headers: headers,
+		Body:    r.body.Bytes(),
+	}
+}
@@ -1,6 +1,18 @@
 package api
 
-import "github.com/moeryomenko/saga/internal/order/domain"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/money"
+	"github.com/moeryomenko/saga/pkg/saga"
+)
 
 func mapOrder(order any) any {
 	switch order := order.(type) {
@@ -8,45 +20,359 @@ func mapOrder(order any) any {
 		return Order{
 			Id:         &order.ID,
 			CustomerId: &order.CustomerID,
+			State:      kind(Empty),
 		}
 	case domain.ActiveOrder:
 		return Order{
 			Id:         &order.ID,
 			CustomerId: &order.CustomerID,
-			Items:      &order.Items,
+			Items:      mapItems(order.Items),
+			State:      kind(Active),
 		}
 	case domain.PendingOrder:
 		return Order{
 			Id:         &order.ID,
 			CustomerId: &order.CustomerID,
-			Items:      &order.Items,
+			Items:      mapItems(order.Items),
+			Price:      mapPrice(order.Price),
+			Currency:   mapCurrency(order.Price.Currency),
+			Discounts:  mapDiscounts(order.Discounts),
+			State:      kind(Pending),
 		}
 	case domain.PaidOrder:
 		return Order{
 			Id:         &order.ID,
 			CustomerId: &order.CustomerID,
-			Items:      &order.Items,
+			Items:      mapItems(order.Items),
+			Price:      mapPrice(order.Price),
+			Currency:   mapCurrency(order.Price.Currency),
+			Discounts:  mapDiscounts(order.Discounts),
 			PaymentId:  &order.PaymentID,
+			State:      kind(Paid),
 		}
 	case domain.StockedOrder:
 		return Order{
 			Id:         &order.ID,
 			CustomerId: &order.CustomerID,
-			Items:      &order.Items,
+			Items:      mapItems(order.Items),
+			Price:      mapPrice(order.Price),
+			Currency:   mapCurrency(order.Price.Currency),
+			Discounts:  mapDiscounts(order.Discounts),
+			State:      kind(Stocked),
 		}
 	case domain.CompletedOrder:
 		return Order{
 			Id:         &order.ID,
 			CustomerId: &order.CustomerID,
-			Items:      &order.Items,
+			Items:      mapItems(order.Items),
+			Price:      mapPrice(order.Price),
+			Currency:   mapCurrency(order.Price.Currency),
+			Discounts:  mapDiscounts(order.Discounts),
 			PaymentId:  &order.PaymentID,
+			State:      kind(Completed),
 		}
 	case domain.CanceledOrder:
-		return Order{
+		result := Order{
 			Id:         &order.ID,
 			CustomerId: &order.CustomerID,
-			Items:      &order.Items,
+			Items:      mapItems(order.Items),
+			Price:      mapPrice(order.Price),
+			Currency:   mapCurrency(order.Price.Currency),
+			Discounts:  mapDiscounts(order.Discounts),
+			State:      kind(Canceled),
+		}
+		if order.PaymentID != uuid.Nil {
+			result.PaymentId = &order.PaymentID
+		}
+		return result
+	case domain.PartiallyReturnedOrder:
+		result := mapOrder(order.CompletedOrder).(Order)
+		result.Returns = mapReturns(order.Returns)
+		result.State = kind(PartiallyReturned)
+		return result
+	case domain.ReturnedOrder:
+		result := mapOrder(order.CompletedOrder).(Order)
+		result.Returns = mapReturns(order.Returns)
+		result.State = kind(Returned)
+		return result
+	case domain.OrderView:
+		result := mapOrder(order.Order).(Order)
+		if !order.CreatedAt.IsZero() {
+			result.CreatedAt = &order.CreatedAt
+		}
+		if !order.UpdatedAt.IsZero() {
+			result.UpdatedAt = &order.UpdatedAt
 		}
+		return result
 	}
 	return Order{}
 }
+
+func mapOrdersPage(page any) any {
+	switch page := page.(type) {
+	case domain.OrdersPage:
+		orders := make([]Order, 0, len(page.Orders))
+		for _, order := range page.Orders {
+			orders = append(orders, mapOrder(order).(Order))
+		}
+		result := OrderList{Orders: &orders}
+		if page.NextCursor != `` {
+			result.NextCursor = &page.NextCursor
+		}
+		return result
+	}
+	return OrderList{}
+}
+
+func mapOrderEvents(events any) any {
+	switch events := events.(type) {
+	case []domain.StoredEvent:
+		result := make([]OrderHistoryEvent, 0, len(events))
+		for _, event := range events {
+			event := event
+			payload := map[string]any{}
+			_ = json.Unmarshal(event.Payload, &payload)
+			result = append(result, OrderHistoryEvent{
+				Version:   &event.Version,
+				Type:      &event.Type,
+				Payload:   &OrderHistoryEvent_Payload{AdditionalProperties: payload},
+				CreatedAt: &event.CreatedAt,
+			})
+		}
+		return result
+	}
+	return []OrderHistoryEvent{}
+}
+
+func mapSagas(sagas any) any {
+	switch sagas := sagas.(type) {
+	case []saga.Instance:
+		result := make([]Saga, 0, len(sagas))
+		for _, instance := range sagas {
+			instance := instance
+			state := string(instance.State)
+			steps := make([]SagaStep, 0, len(instance.Steps))
+			for _, step := range instance.Steps {
+				step := step
+				status := string(step.Status)
+				steps = append(steps, SagaStep{
+					Name:          &step.Name,
+					Status:        &status,
+					Attempts:      &step.Attempts,
+					LastError:     stringOrNil(step.LastError),
+					CorrelationId: stringOrNil(step.CorrelationID),
+					UpdatedAt:     &step.UpdatedAt,
+				})
+			}
+			result = append(result, Saga{
+				Id:            &instance.ID,
+				Name:          &instance.Saga,
+				CorrelationId: &instance.CorrelationID,
+				State:         &state,
+				Steps:         &steps,
+				CreatedAt:     &instance.CreatedAt,
+				UpdatedAt:     &instance.UpdatedAt,
+			})
+		}
+		return result
+	}
+	return []Saga{}
+}
+
+func mapOrdersFilter(params GetCustomersCustomerIDOrdersParams) domain.OrdersFilter {
+	filter := domain.OrdersFilter{
+		CreatedFrom: timeOrZero(params.CreatedFrom),
+		CreatedTo:   timeOrZero(params.CreatedTo),
+		UpdatedFrom: timeOrZero(params.UpdatedFrom),
+		UpdatedTo:   timeOrZero(params.UpdatedTo),
+	}
+	if params.Cursor != nil {
+		filter.Cursor = *params.Cursor
+	}
+	if params.Limit != nil {
+		filter.Limit = *params.Limit
+	}
+	if params.Kind != nil {
+		for _, kind := range *params.Kind {
+			filter.Kinds = append(filter.Kinds, string(kind))
+		}
+	}
+	return filter
+}
+
+func mapDiscounts(discounts []domain.Discount) *[]Discount {
+	if len(discounts) == 0 {
+		return nil
+	}
+
+	result := make([]Discount, 0, len(discounts))
+	for _, discount := range discounts {
+		discount := discount
+		result = append(result, Discount{Code: &discount.Code, Amount: mapPrice(discount.Amount)})
+	}
+	return &result
+}
+
+func mapReturns(returns []domain.Return) *[]Return {
+	result := make([]Return, 0, len(returns))
+	for _, r := range returns {
+		r := r
+		result = append(result, Return{
+			Id:        &r.ID,
+			Items:     mapItems(r.Items),
+			Refund:    mapPrice(r.Refund),
+			Refunded:  &r.Refunded,
+			Restocked: &r.Restocked,
+		})
+	}
+	return &result
+}
+
+func mapPromotion(promotion any) any {
+	switch promotion := promotion.(type) {
+	case domain.Promotion:
+		kind := PromotionKind(promotion.Kind)
+		value := promotion.Value.String()
+		result := Promotion{
+			Code:       &promotion.Code,
+			Kind:       &kind,
+			Value:      &value,
+			Currency:   mapCurrency(promotion.Currency),
+			UsageLimit: &promotion.UsageLimit,
+		}
+		if !promotion.ExpiresAt.IsZero() {
+			result.ExpiresAt = &promotion.ExpiresAt
+		}
+		return result
+	}
+	return Promotion{}
+}
+
+func mapPromotions(promotions any) any {
+	switch promotions := promotions.(type) {
+	case []domain.Promotion:
+		result := make([]Promotion, 0, len(promotions))
+		for _, promotion := range promotions {
+			result = append(result, mapPromotion(promotion).(Promotion))
+		}
+		return result
+	}
+	return []Promotion{}
+}
+
+func kind(k OrderKind) *OrderKind {
+	return &k
+}
+
+// mapPrice returns amount with all minor units of currency, e.g. "10.50".
+func mapPrice(price money.Money) *string {
+	result := price.Amount.StringFixed(price.Currency.MinorUnits())
+	return &result
+}
+
+func mapCurrency(currency money.Currency) *Currency {
+	if currency == `` {
+		return nil
+	}
+	result := Currency(currency)
+	return &result
+}
+
+// mapMoney maps validated price to money.
+func mapMoney(price string, currency Currency) money.Money {
+	code, _ := money.ParseCurrency(currency)
+	return money.New(decimal.RequireFromString(price), code)
+}
+
+func stringOrNil(s string) *string {
+	if s == `` {
+		return nil
+	}
+	return &s
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func mapItems(items []domain.Item) *[]Item {
+	result := make([]Item, 0, len(items))
+	for _, item := range items {
+		item := item
+		apiItem := Item{
+			Sku:      &item.SKU,
+			Quantity: &item.Quantity,
+		}
+		if !item.UnitPrice.IsZero() {
+			apiItem.UnitPrice = mapPrice(item.UnitPrice)
+		}
+		if len(item.Attributes) != 0 {
+			apiItem.Attributes = &Item_Attributes{AdditionalProperties: item.Attributes}
+		}
+		result = append(result, apiItem)
+	}
+	return &result
+}
+
+func mapItemToDomain(item Item) domain.Item {
+	domainItem := domain.Item{
+		SKU:      *item.Sku,
+		Quantity: quantityOrDefault(item.Quantity),
+	}
+	if item.Attributes != nil {
+		domainItem.Attributes = item.Attributes.AdditionalProperties
+	}
+	return domainItem
+}
+
+// mapAttributes returns attributes of item line given as name:value.
+func mapAttributes(attributes *[]string) (map[string]string, error) {
+	if attributes == nil || len(*attributes) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]string, len(*attributes))
+	for _, attribute := range *attributes {
+		name, value, ok := strings.Cut(attribute, `:`)
+		if !ok || name == `` {
+			return nil, fmt.Errorf(`invalid attribute %q, expected name:value`, attribute)
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+func quantityOrDefault(quantity *int) int {
+	if quantity == nil {
+		return 1
+	}
+	return *quantity
+}
+
+func mapProduct(product any) any {
+	switch product := product.(type) {
+	case domain.Product:
+		return Product{
+			Sku:      &product.SKU,
+			Name:     &product.Name,
+			Price:    mapPrice(product.Price),
+			Currency: mapCurrency(product.Price.Currency),
+		}
+	}
+	return Product{}
+}
+
+func mapProducts(products any) any {
+	switch products := products.(type) {
+	case []domain.Product:
+		result := make([]Product, 0, len(products))
+		for _, product := range products {
+			result = append(result, mapProduct(product).(Product))
+		}
+		return result
+	}
+	return []Product{}
+}
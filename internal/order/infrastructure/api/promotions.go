@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/internal/order/service"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/money"
+)
+
+func (RestController) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.ListPromotions(ctx)
+	}), WithResponseMapper(mapPromotions))
+}
+
+func (RestController) PostPromotions(w http.ResponseWriter, r *http.Request) {
+	var promotion Promotion
+	handlerDecorator(w, r, WithRequestBody(&promotion), WithOperation(func(ctx context.Context) (any, error) {
+		var (
+			currency   money.Currency
+			expiresAt  time.Time
+			usageLimit int
+		)
+		if promotion.Currency != nil {
+			currency, _ = money.ParseCurrency(*promotion.Currency)
+		}
+		if promotion.ExpiresAt != nil {
+			expiresAt = *promotion.ExpiresAt
+		}
+		if promotion.UsageLimit != nil {
+			usageLimit = *promotion.UsageLimit
+		}
+		return service.CreatePromotion(ctx, *promotion.Code, domain.DiscountKind(*promotion.Kind),
+			decimal.RequireFromString(*promotion.Value), currency, expiresAt, usageLimit)
+	}), WithResponseMapper(mapPromotion), WithErrorMapper(mapPromotionError), WithDefaultStatus(http.StatusCreated))
+}
+
+func (RestController) GetPromotionsCode(w http.ResponseWriter, r *http.Request, code string) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.GetPromotion(ctx, code)
+	}), WithResponseMapper(mapPromotion), WithErrorMapper(mapPromotionError))
+}
+
+func (RestController) DeletePromotionsCode(w http.ResponseWriter, r *http.Request, code string) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return nil, service.DeletePromotion(ctx, code)
+	}), WithErrorMapper(mapPromotionError), WithDefaultStatus(http.StatusNoContent))
+}
+
+func mapPromotionError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUnknownPromotion):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrPromotionExists):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrDomain):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 
 	multierror "github.com/hashicorp/go-multierror"
+	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/pkg/money"
 )
 
 type Validated interface {
@@ -23,9 +26,127 @@ func (r *CreateOrder) Validate() error {
 func (r *Item) Validate() error {
 	var err *multierror.Error
 
-	if r.Name == nil || *r.Name == `` {
-		err = multierror.Append(err, fmt.Errorf(`item must have name`))
+	if r.Sku == nil || *r.Sku == `` {
+		err = multierror.Append(err, fmt.Errorf(`item must have sku`))
+	}
+
+	return err.ErrorOrNil()
+}
+
+func (r *Product) Validate() error {
+	var err *multierror.Error
+
+	if r.Sku == nil || *r.Sku == `` {
+		err = multierror.Append(err, fmt.Errorf(`product must have sku`))
+	}
+
+	err = multierror.Append(err, validatePrice(r.Price))
+	err = multierror.Append(err, validateCurrency(r.Currency))
+
+	return err.ErrorOrNil()
+}
+
+func (r *UpdateProduct) Validate() error {
+	var err *multierror.Error
+
+	err = multierror.Append(err, validatePrice(r.Price))
+	err = multierror.Append(err, validateCurrency(r.Currency))
+
+	return err.ErrorOrNil()
+}
+
+func (r *ProcessOrder) Validate() error {
+	var err *multierror.Error
+
+	if r.PromoCodes != nil {
+		for _, code := range *r.PromoCodes {
+			if code == `` {
+				err = multierror.Append(err, fmt.Errorf(`discount code must not be empty`))
+			}
+		}
 	}
 
 	return err.ErrorOrNil()
 }
+
+func (r *ReturnOrder) Validate() error {
+	var err *multierror.Error
+
+	if r.Items != nil {
+		for _, item := range *r.Items {
+			item := item
+			err = multierror.Append(err, item.Validate())
+			if item.Quantity != nil && *item.Quantity < 1 {
+				err = multierror.Append(err, fmt.Errorf(`returned quantity must be positive`))
+			}
+		}
+	}
+
+	return err.ErrorOrNil()
+}
+
+func (r *Promotion) Validate() error {
+	var err *multierror.Error
+
+	if r.Code == nil || *r.Code == `` {
+		err = multierror.Append(err, fmt.Errorf(`promotion must have code`))
+	}
+	if r.Kind == nil {
+		err = multierror.Append(err, fmt.Errorf(`promotion must have kind`))
+	}
+	if r.Value == nil {
+		err = multierror.Append(err, fmt.Errorf(`promotion must have value`))
+	} else if _, parseErr := decimal.NewFromString(*r.Value); parseErr != nil {
+		err = multierror.Append(err, fmt.Errorf(`promotion value must be decimal number`))
+	}
+	if r.Kind != nil && *r.Kind == Fixed {
+		err = multierror.Append(err, validateCurrency(r.Currency))
+	}
+
+	return err.ErrorOrNil()
+}
+
+func (r GetCustomersCustomerIDOrdersParams) Validate() error {
+	var err *multierror.Error
+
+	if r.Limit != nil && (*r.Limit < 1 || *r.Limit > 100) {
+		err = multierror.Append(err, fmt.Errorf(`limit must be between 1 and 100`))
+	}
+	if r.Kind != nil {
+		for _, kind := range *r.Kind {
+			switch kind {
+			case Empty, Active, Pending, Stocked, Paid, Completed, Canceled, PartiallyReturned, Returned:
+			default:
+				err = multierror.Append(err, fmt.Errorf(`unknown order kind %q`, kind))
+			}
+		}
+	}
+
+	return err.ErrorOrNil()
+}
+
+func validateCurrency(currency *string) error {
+	if currency == nil {
+		return fmt.Errorf(`price must have currency`)
+	}
+
+	_, err := money.ParseCurrency(*currency)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validatePrice(price *string) error {
+	if price == nil {
+		return fmt.Errorf(`product must have price`)
+	}
+
+	_, err := decimal.NewFromString(*price)
+	if err != nil {
+		return fmt.Errorf(`product price must be decimal number`)
+	}
+
+	return nil
+}
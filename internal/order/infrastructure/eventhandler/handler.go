@@ -2,63 +2,36 @@ package eventhandler
 
 import (
 	"context"
-	"log"
-	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 
 	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/inbox"
+	"github.com/moeryomenko/saga/pkg/saga"
 )
 
-type EventHandler func(context.Context, uuid.UUID, domain.Event) (domain.Order, error)
+// EventHandler handles event of order, message of event is received by inbox.
+type EventHandler func(context.Context, inbox.Message, uuid.UUID, domain.Event) error
 
+// HandleEvents handles replies of saga participants.
 func HandleEvents(handler EventHandler) func(context.Context) error {
 	return func(ctx context.Context) error {
-		for initStreams(ctx) != nil {
-		}
-
-		consumerID := uuid.New().String()
-		for {
-			select {
-			case <-ctx.Done():
-				return nil
-			default:
-				events, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
-					Group:    OrderGroup,
-					Consumer: consumerID,
-					Streams:  []string{ConfirmStream, `>`},
-					Block:    0,
-					Count:    2,
-					NoAck:    false,
-				}).Result()
-				if err != nil {
-					<-time.After(time.Second)
-					continue
-				}
-
-				for _, msg := range events[0].Messages {
-					err = handleMessage(ctx, msg, handler)
-					if err != nil {
-						log.Println(err)
-					}
-
-					_, err = client.XAck(ctx, ConfirmStream, OrderGroup, msg.ID).Result()
-					if err != nil {
-						log.Println(err)
-					}
-				}
-			}
-		}
+		return streams.Subscribe(OrderGroup, saga.ReplyStream, func(ctx context.Context, id string, values map[string]any) error {
+			return handleMessage(ctx, inbox.MessageOf(saga.ReplyStream, id, values), values, handler)
+		})(ctx)
 	}
 }
 
-func handleMessage(ctx context.Context, msg redis.XMessage, eventHandler EventHandler) error {
-	orderID, event, err := mapToDomainEvent(msg.Values)
+func handleMessage(ctx context.Context, msg inbox.Message, values map[string]any, eventHandler EventHandler) error {
+	orderID, event, err := mapToDomainEvent(values)
 	if err != nil {
-		return err
+		return errors.MarkAndWrapError(err, saga.ErrRejected, `invalid reply`)
 	}
 
-	_, err = eventHandler(ctx, orderID, event)
+	err = eventHandler(ctx, msg, orderID, event)
+	if errors.Is(err, domain.ErrDomain) {
+		return errors.MarkAndWrapError(err, saga.ErrRejected, `reply is rejected`)
+	}
 	return err
 }
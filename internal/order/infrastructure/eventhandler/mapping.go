@@ -1,20 +1,26 @@
 package eventhandler
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/google/uuid"
 
 	"github.com/moeryomenko/saga/internal/order/domain"
 	"github.com/moeryomenko/saga/schema"
 )
 
+// ErrUnknownReply is returned for reply of unknown type, such reply is rejected.
+var ErrUnknownReply = errors.New(`unknown reply`)
+
 func mapToDomainEvent(event map[string]any) (uuid.UUID, domain.Event, error) {
 	switch kind := schema.GetEventType(event); kind {
-	case schema.PaymentsConfirmed, schema.PaymentsFailed:
+	case schema.PaymentsConfirmed, schema.PaymentsFailed, schema.PaymentsRefunded:
 		return mapPaymentEventToDomain(schema.ToPaymentsEvent(event))
-	case schema.StockConfirmed, schema.StockFailed:
+	case schema.StockConfirmed, schema.StockFailed, schema.StockReturned:
 		return mapStockEventToDomain(schema.ToStockEvent(event))
 	default:
-		panic(`bug: invalid event type`)
+		return uuid.UUID{}, nil, fmt.Errorf(`%w: type %q`, ErrUnknownReply, kind)
 	}
 }
 
@@ -28,6 +34,8 @@ func mapPaymentEventToDomain(event schema.PaymentsEvent, err error) (uuid.UUID,
 		return event.OrderID, domain.ConfirmPayment{PaymentID: event.PaymentsID}, nil
 	case schema.PaymentsFailed:
 		return event.OrderID, domain.RejectPayment{}, nil
+	case schema.PaymentsRefunded:
+		return event.OrderID, domain.ConfirmRefund{ReturnID: event.ReturnID}, nil
 	}
 
 	return uuid.UUID{}, nil, nil
@@ -42,7 +50,9 @@ func mapStockEventToDomain(event schema.StockEvent, err error) (uuid.UUID, domai
 	case schema.StockConfirmed:
 		return event.OrderID, domain.ConfirmStock{}, nil
 	case schema.StockFailed:
-		return event.OrderID, domain.RejectStock{}, nil
+		return event.OrderID, domain.RejectStock{Reason: event.Reason}, nil
+	case schema.StockReturned:
+		return event.OrderID, domain.ConfirmRestock{ReturnID: event.ReturnID}, nil
 	}
 
 	return uuid.UUID{}, nil, nil
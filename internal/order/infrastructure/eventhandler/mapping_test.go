@@ -1,10 +1,14 @@
 package eventhandler
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/inbox"
+	"github.com/moeryomenko/saga/pkg/saga"
 	"github.com/moeryomenko/saga/schema"
 	"github.com/stretchr/testify/require"
 )
@@ -61,6 +65,41 @@ func Test_mapToDomainEvent(t *testing.T) {
 				return domain.RejectStock{}
 			},
 		},
+		`stock failed with reason`: {
+			orderID: uuid.New(),
+			getEvent: func(orderID, paymentID uuid.UUID) map[string]any {
+				event := schema.StockEvent{OrderID: orderID, Reason: `unknown product test`}
+				event.SetType(schema.StockFailed)
+				return mapToEvent(event.Map())
+			},
+			expectedDomainEvent: func(orderID, paymentID uuid.UUID) domain.Event {
+				return domain.RejectStock{Reason: `unknown product test`}
+			},
+		},
+		`payment refunded`: {
+			orderID:   uuid.New(),
+			paymentID: uuid.MustParse(`5a4f7fa4-4f5e-4d3c-9a86-6e2a1c3c9b11`),
+			getEvent: func(orderID, returnID uuid.UUID) map[string]any {
+				event := schema.PaymentsEvent{OrderID: orderID, ReturnID: returnID}
+				event.SetType(schema.PaymentsRefunded)
+				return mapToEvent(event.Map())
+			},
+			expectedDomainEvent: func(orderID, returnID uuid.UUID) domain.Event {
+				return domain.ConfirmRefund{ReturnID: returnID}
+			},
+		},
+		`stock returned`: {
+			orderID:   uuid.New(),
+			paymentID: uuid.MustParse(`0d9a2f47-8c1e-4b52-a0f3-2f6c8d1e7a90`),
+			getEvent: func(orderID, returnID uuid.UUID) map[string]any {
+				event := schema.StockEvent{OrderID: orderID, ReturnID: returnID}
+				event.SetType(schema.StockReturned)
+				return mapToEvent(event.Map())
+			},
+			expectedDomainEvent: func(orderID, returnID uuid.UUID) domain.Event {
+				return domain.ConfirmRestock{ReturnID: returnID}
+			},
+		},
 	}
 
 	for name, tc := range testcases {
@@ -81,3 +120,24 @@ func mapToEvent(event map[string]string) map[string]any {
 	}
 	return mappedEvent
 }
+
+func Test_handleUnknownReply(t *testing.T) {
+	testcases := map[string]map[string]any{
+		`unknown type`: {`type`: `unknown`, `order_id`: uuid.NewString()},
+		`missing type`: {`order_id`: uuid.NewString()},
+	}
+
+	for name, values := range testcases {
+		values := values
+		t.Run(name, func(t *testing.T) {
+			_, _, err := mapToDomainEvent(values)
+			require.ErrorIs(t, err, ErrUnknownReply)
+
+			err = handleMessage(context.Background(), inbox.Message{}, values, func(context.Context, inbox.Message, uuid.UUID, domain.Event) error {
+				t.Fatal(`unknown reply is handled`)
+				return nil
+			})
+			require.True(t, errors.Is(err, saga.ErrRejected))
+		})
+	}
+}
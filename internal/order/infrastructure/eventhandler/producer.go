@@ -3,15 +3,13 @@ package eventhandler
 import (
 	"context"
 
-	redis "github.com/go-redis/redis/v8"
-
-	"github.com/moeryomenko/saga/schema"
+	"github.com/moeryomenko/saga/pkg/outbox"
+	"github.com/moeryomenko/saga/pkg/saga"
 )
 
-func Produce(ctx context.Context, event schema.OrderEvent) error {
-	_, err := client.XAdd(ctx, &redis.XAddArgs{
-		Stream: OrderStream,
-		Values: event.Map(),
-	}).Result()
-	return err
+// Publisher publishes relayed events to saga.CommandStream.
+func Publisher() outbox.Publisher {
+	return outbox.PublisherFunc(func(ctx context.Context, messages []map[string]string) (int, error) {
+		return streams.PublishBatch(ctx, saga.CommandStream, messages)
+	})
 }
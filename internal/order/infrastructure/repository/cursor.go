@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+)
+
+// cursor is keyset position of the last order on the page.
+type cursor struct {
+	createdAt time.Time
+	orderID   uuid.UUID
+}
+
+func (c cursor) String() string {
+	raw := c.createdAt.UTC().Format(time.RFC3339Nano) + `,` + c.orderID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func parseCursor(s string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, domain.ErrInvalidCursor
+	}
+	createdAt, orderID, ok := strings.Cut(string(raw), `,`)
+	if !ok {
+		return cursor{}, domain.ErrInvalidCursor
+	}
+
+	var c cursor
+	c.createdAt, err = time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return cursor{}, domain.ErrInvalidCursor
+	}
+	c.orderID, err = uuid.Parse(orderID)
+	if err != nil {
+		return cursor{}, domain.ErrInvalidCursor
+	}
+	return c, nil
+}
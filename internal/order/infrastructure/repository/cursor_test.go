@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+)
+
+func Test_parseCursor(t *testing.T) {
+	expected := cursor{
+		createdAt: time.Date(2022, time.August, 1, 12, 30, 0, 123456000, time.UTC),
+		orderID:   genUUID(t),
+	}
+
+	actual, err := parseCursor(expected.String())
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	for _, invalid := range []string{`not base64!`, `dGVzdA`, expected.String()[1:]} {
+		_, err = parseCursor(invalid)
+		require.ErrorIs(t, err, domain.ErrInvalidCursor)
+	}
+}
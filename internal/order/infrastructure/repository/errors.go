@@ -1,9 +1,19 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/moeryomenko/saga/pkg/inbox"
+	"github.com/moeryomenko/saga/pkg/outbox"
+)
 
 var (
 	ErrInfrastructure = errors.New(`infrastructure`)
 
-	ErrNoEvents = errors.New(`no new event into log`)
+	ErrNoEvents         = outbox.ErrNoEvents
+	ErrDuplicateMessage = inbox.ErrDuplicate
+
+	ErrIdempotencyKeyReused = errors.New(`idempotency key was used by another request`)
+	ErrRequestInProgress    = errors.New(`request with idempotency key is in progress`)
+	ErrLeaseLost            = errors.New(`lease of idempotency key was taken over`)
 )
@@ -2,98 +2,80 @@ package repository
 
 import (
 	"context"
-	"encoding/json"
-	"strings"
+	"fmt"
 
-	"github.com/jackc/pgtype"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/moeryomenko/saga/internal/order/domain"
 	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/outbox"
+	"github.com/moeryomenko/saga/pkg/saga"
 	"github.com/moeryomenko/saga/schema"
 )
 
-func GetEvent(ctx context.Context) (offset int, event schema.OrderEvent, err error) {
-	var (
-		payload   pgtype.JSONB
-		eventType schema.EventType
-	)
-	err = pool.AcquireFunc(ctx, func(conn *pgxpool.Conn) error {
-		return conn.QueryRow(ctx, selectEventFromLog).Scan(&offset, &payload, &eventType)
-	})
-	switch err {
-	case nil:
-	case pgx.ErrNoRows:
-		return 0, schema.OrderEvent{}, ErrNoEvents
-	default:
-		return 0, schema.OrderEvent{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't get event`)
-	}
-	err = json.Unmarshal(payload.Bytes, &event)
-	if err != nil {
-		return 0, schema.OrderEvent{}, errors.MarkAndWrapError(err, ErrInfrastructure, `invalid event payload`)
-	}
-	event.SetType(eventType)
-	return offset, event, nil
-}
-
-func Ack(ctx context.Context, id int) error {
-	err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) (err error) {
-		_, err = tx.Exec(ctx, submitOffset, id)
-		return err
-	})
-	if err != nil {
-		return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't submit offset`)
-	}
-	return nil
+// EventLog is outbox of saga commands.
+func EventLog() outbox.Outbox[schema.OrderEvent] {
+	return outbox.New[schema.OrderEvent](pool)
 }
 
-func mapToEvent(order domain.Order) (schema.OrderEvent, bool) {
+// mapToEvent maps saga command to event with order details.
+func mapToEvent(order domain.Order, sagaID uuid.UUID, command saga.MessageType) (schema.OrderEvent, error) {
 	event := schema.OrderEvent{
 		OrderID:    order.GetID(),
 		CustomerID: order.GetCustomerID(),
 	}
+	event.SetType(schema.EventType(command))
 
 	switch order := order.(type) {
 	case domain.PendingOrder:
-		event.SetType(schema.NewOrder)
-		event.Items = strings.Join(order.Items, `,`)
+		event.Items = mapItemsToEvent(order.Items)
 		event.Price = order.Price
 	case domain.CompletedOrder:
-		event.SetType(schema.CompleteOrder)
-		event.Items = strings.Join(order.Items, `,`)
+		event.Items = mapItemsToEvent(order.Items)
 		event.Price = order.Price
 		event.PaymentID = order.PaymentID
 	case domain.CanceledOrder:
-		event.SetType(schema.CancelOrder)
-		event.Items = strings.Join(order.Items, `,`)
+		event.Items = mapItemsToEvent(order.Items)
 		event.Price = order.Price
+		event.PaymentID = order.PaymentID
+	case domain.PartiallyReturnedOrder:
+		return returnEvent(event, order.CompletedOrder, order.Returns, sagaID)
+	case domain.ReturnedOrder:
+		return returnEvent(event, order.CompletedOrder, order.Returns, sagaID)
 	default:
-		return schema.OrderEvent{}, false
+		return schema.OrderEvent{}, fmt.Errorf(`order can't be sent by %s command`, command)
 	}
 
-	return event, true
+	return event, nil
 }
 
-func insertEvent(ctx context.Context, tx pgx.Tx, order domain.Order) error {
-	event, ok := mapToEvent(order)
-	if !ok {
-		return nil
-	}
-	payload, err := json.Marshal(event)
-	if err != nil {
-		return errors.MarkAndWrapError(err, ErrInfrastructure, `invalid event type`)
+// returnEvent maps return of completed order, return is saga of its own.
+func returnEvent(event schema.OrderEvent, order domain.CompletedOrder, returns []domain.Return, returnID uuid.UUID) (schema.OrderEvent, error) {
+	for _, r := range returns {
+		if r.ID != returnID {
+			continue
+		}
+		event.ReturnID = r.ID
+		event.Items = mapItemsToEvent(r.Items)
+		event.Price = r.Refund
+		event.PaymentID = order.PaymentID
+		return event, nil
 	}
+	return schema.OrderEvent{}, domain.ErrReturnNotFound
+}
 
-	_, err = tx.Exec(ctx, insertEventToLog, pgtype.JSONB{Bytes: payload, Status: pgtype.Present}, event.Type)
-	return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't insert event to log`)
+func mapItemsToEvent(items []domain.Item) schema.Items {
+	eventItems := make(schema.Items, 0, len(items))
+	for _, item := range items {
+		eventItems = append(eventItems, schema.Item{SKU: item.SKU, Quantity: item.Quantity})
+	}
+	return eventItems
 }
 
-const (
-	insertEventToLog   = `INSERT INTO event_log(payload, event_kind) VALUES ($1, $2)`
-	selectEventFromLog = `
-	SELECT id, payload, event_kind
-	FROM event_log
-	WHERE id > (SELECT offset_acked FROM event_offset)
-	ORDER BY id ASC LIMIT 1`
-	submitOffset = `UPDATE event_offset SET offset_acked = $1`
-)
+func insertEvent(ctx context.Context, tx pgx.Tx, event schema.OrderEvent) error {
+	err := EventLog().Write(ctx, tx, event)
+	if err != nil {
+		return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't insert event to log`)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/schema"
+)
+
+func TestIntegration_EventLogCommitOrder(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	_, err = pool.Exec(ctx, `TRUNCATE event_log`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
+	require.NoError(t, err)
+
+	const insertEvent = `INSERT INTO event_log(payload, event_kind) VALUES ('{"type": "test"}', 'test') RETURNING id`
+
+	// the first transaction takes lower id, but commits after the second one.
+	first, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = first.Rollback(ctx)
+	}()
+	var firstID, secondID int
+	require.NoError(t, first.QueryRow(ctx, insertEvent).Scan(&firstID))
+	require.NoError(t, pool.QueryRow(ctx, insertEvent).Scan(&secondID))
+	require.Less(t, firstID, secondID)
+
+	// event of committed transaction waits for running one, so it isn't acknowledged before it.
+	_, _, err = getEvent(ctx)
+	require.ErrorIs(t, err, ErrNoEvents)
+
+	require.NoError(t, first.Commit(ctx))
+	for _, expected := range []int{firstID, secondID} {
+		offset, _, err := getEvent(ctx)
+		require.NoError(t, err)
+		require.Equal(t, expected, offset)
+		require.NoError(t, EventLog().Ack(ctx, offset))
+	}
+	_, _, err = getEvent(ctx)
+	require.ErrorIs(t, err, ErrNoEvents)
+
+	// running transaction of other database of cluster doesn't hold events.
+	payments, err := pgx.Connect(ctx, `user=test password=pass host=localhost port=5432 dbname=payments`)
+	require.NoError(t, err)
+	defer payments.Close(ctx)
+	foreign, err := payments.Begin(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = foreign.Rollback(ctx)
+	}()
+	_, err = foreign.Exec(ctx, `SELECT pg_current_xact_id()`)
+	require.NoError(t, err)
+
+	var thirdID int
+	require.NoError(t, pool.QueryRow(ctx, insertEvent).Scan(&thirdID))
+	offset, _, err := getEvent(ctx)
+	require.NoError(t, err)
+	require.Equal(t, thirdID, offset)
+	require.NoError(t, EventLog().Ack(ctx, offset))
+
+	_, err = pool.Exec(ctx, `TRUNCATE event_log`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
+	require.NoError(t, err)
+}
+
+// getEvent returns the oldest not acknowledged event of log.
+func getEvent(ctx context.Context) (int, schema.OrderEvent, error) {
+	entries, err := EventLog().Read(ctx, 1)
+	if err != nil {
+		return 0, schema.OrderEvent{}, err
+	}
+	return entries[0].Offset, entries[0].Event, nil
+}
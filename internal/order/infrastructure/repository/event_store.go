@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/money"
+)
+
+// snapshotInterval is number of events between order snapshots,
+// it bounds number of events replayed on order loading.
+const snapshotInterval = 10
+
+const (
+	orderCreated     = `created`
+	itemAdded        = `item_added`
+	itemRemoved      = `item_removed`
+	orderProcessed   = `processed`
+	paymentConfirmed = `payment_confirmed`
+	stockConfirmed   = `stock_confirmed`
+	paymentRejected  = `payment_rejected`
+	stockRejected    = `stock_rejected`
+	orderCanceled    = `canceled`
+	orderTimedOut    = `timed_out`
+	returnRequested  = `return_requested`
+	refundConfirmed  = `refund_confirmed`
+	restockConfirmed = `restock_confirmed`
+)
+
+// eventPayload is serialized domain event, it contains all data
+// which was used by event, so event can be replayed without external ports.
+type eventPayload struct {
+	OrderID    *uuid.UUID                 `json:"order_id,omitempty"`
+	CustomerID *uuid.UUID                 `json:"customer_id,omitempty"`
+	Item       *Item                      `json:"item,omitempty"`
+	SKU        string                     `json:"sku,omitempty"`
+	Quantity   int                        `json:"quantity,omitempty"`
+	Prices     map[string]decimal.Decimal `json:"prices,omitempty"`
+	Discounts  []Discount                 `json:"discounts,omitempty"`
+	Currency   money.Currency             `json:"currency,omitempty"`
+	Deadline   *time.Time                 `json:"deadline,omitempty"`
+	PaymentID  *uuid.UUID                 `json:"payment_id,omitempty"`
+	Now        *time.Time                 `json:"now,omitempty"`
+	ReturnID   *uuid.UUID                 `json:"return_id,omitempty"`
+	Items      []Item                     `json:"items,omitempty"`
+	Reason     string                     `json:"reason,omitempty"`
+	Attributes map[string]string          `json:"attributes,omitempty"`
+}
+
+// appliedDiscounts replays discounts applied on order processing as fixed ones,
+// so replay doesn't depend on current promotions state.
+type appliedDiscounts map[string]money.Money
+
+func (d appliedDiscounts) Promotion(code string, _ uuid.UUID) (domain.Promotion, int, error) {
+	amount, ok := d[code]
+	if !ok {
+		return domain.Promotion{}, 0, domain.ErrUnknownPromotion
+	}
+	return domain.Promotion{Code: code, Kind: domain.FixedDiscount, Value: amount.Amount, Currency: amount.Currency}, 0, nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// LoadOrder rebuilds order at given version, zero version means the latest one.
+func LoadOrder(ctx context.Context, orderID uuid.UUID, version int) (domain.Order, error) {
+	order, current, err := loadOrder(ctx, pool, orderID, version)
+	switch {
+	case err != nil:
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't load order`)
+	case order == nil, version != 0 && current != version:
+		return nil, errors.MarkAndWrapError(domain.ErrOrderNotFound, domain.ErrDomain, fmt.Sprintf(`%s version %d`, orderID, version))
+	default:
+		return order, nil
+	}
+}
+
+// OrderEvents returns history of order changes.
+func OrderEvents(ctx context.Context, orderID uuid.UUID) ([]domain.StoredEvent, error) {
+	rows, err := pool.Query(ctx, orderEventsQuery, orderID.String(), 0, 0)
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find order events`)
+	}
+	defer rows.Close()
+
+	events := []domain.StoredEvent{}
+	for rows.Next() {
+		var (
+			event     domain.StoredEvent
+			payload   pgtype.JSONB
+			createdAt pgtype.Timestamp
+		)
+		err = rows.Scan(&event.Version, &event.Type, &payload, &createdAt)
+		if err != nil {
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan order event`)
+		}
+		event.Payload = payload.Bytes
+		event.CreatedAt = createdAt.Time
+		events = append(events, event)
+	}
+	if rows.Err() != nil {
+		return nil, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't find order events`)
+	}
+	if len(events) == 0 {
+		return nil, errors.MarkAndWrapError(domain.ErrOrderNotFound, domain.ErrDomain, orderID.String())
+	}
+	return events, nil
+}
+
+// loadOrder folds order events from the latest snapshot until given version,
+// returns nil order if order has neither snapshots nor events.
+func loadOrder(ctx context.Context, q querier, orderID uuid.UUID, version int) (domain.Order, int, error) {
+	snapshot := &Order{OrderID: pgtype.UUID{Bytes: orderID, Status: pgtype.Present}}
+	current := 0
+	err := q.QueryRow(ctx, findSnapshotQuery, orderID.String(), version).Scan(
+		&current,
+		&snapshot.CustomerID,
+		&snapshot.Items,
+		&snapshot.Price,
+		&snapshot.Currency,
+		&snapshot.PaymentID,
+		&snapshot.Deadline,
+		&snapshot.Discounts,
+		&snapshot.Returns,
+		&snapshot.Kind,
+	)
+	var order domain.Order
+	switch err {
+	case nil:
+		order = mapToDomain(snapshot)
+	case pgx.ErrNoRows:
+	default:
+		return nil, 0, err
+	}
+
+	rows, err := q.Query(ctx, orderEventsQuery, orderID.String(), current, version)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			kind      string
+			payload   pgtype.JSONB
+			createdAt pgtype.Timestamp
+		)
+		err = rows.Scan(&current, &kind, &payload, &createdAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		event, err := eventFromModel(kind, payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		order, err = domain.Apply(order, event)
+		if err != nil {
+			return nil, 0, fmt.Errorf(`couldn't replay event %d: %w`, current, err)
+		}
+	}
+	return order, current, rows.Err()
+}
+
+func appendEvent(ctx context.Context, tx pgx.Tx, version int, event domain.Event, order domain.Order) error {
+	kind, payload, err := eventToModel(event, order)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, insertOrderEventQuery, order.GetID(), version, kind, payload)
+	if err != nil {
+		return err
+	}
+
+	if version%snapshotInterval != 0 {
+		return nil
+	}
+	model, err := mapToModel(order)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, insertSnapshotQuery, model.OrderID, version, model.CustomerID, model.Items, model.Price, model.Currency, model.PaymentID, model.Deadline, model.Discounts, model.Returns, model.Kind)
+	return err
+}
+
+// eventToModel serializes event, order is result of event applying.
+func eventToModel(event domain.Event, order domain.Order) (string, pgtype.JSONB, error) {
+	var (
+		kind    string
+		payload eventPayload
+	)
+	switch event := event.(type) {
+	case domain.CreateOrder:
+		kind = orderCreated
+		payload.OrderID = &event.OrderID
+		payload.CustomerID = &event.CustomerID
+	case domain.AddItem:
+		kind = itemAdded
+		item := itemsToModels([]domain.Item{event.Item})[0]
+		payload.Item = &item
+	case domain.RemoveItem:
+		kind = itemRemoved
+		payload.SKU = event.SKU
+		payload.Quantity = event.Quantity
+		payload.Attributes = event.Attributes
+	case domain.Process:
+		kind = orderProcessed
+		pending, ok := order.(domain.PendingOrder)
+		if !ok {
+			return ``, pgtype.JSONB{}, fmt.Errorf(`processed order must be pending, got %T`, order)
+		}
+		payload.Prices = make(map[string]decimal.Decimal, len(pending.Items))
+		for _, item := range pending.Items {
+			payload.Prices[item.SKU] = item.UnitPrice.Amount
+		}
+		for _, discount := range pending.Discounts {
+			payload.Discounts = append(payload.Discounts, Discount{Code: discount.Code, Amount: discount.Amount.Amount})
+		}
+		payload.Currency = pending.Price.Currency
+		payload.Deadline = &event.Deadline
+	case domain.ConfirmPayment:
+		kind = paymentConfirmed
+		payload.PaymentID = &event.PaymentID
+	case domain.ConfirmStock:
+		kind = stockConfirmed
+	case domain.RejectPayment:
+		kind = paymentRejected
+	case domain.RejectStock:
+		kind = stockRejected
+		payload.Reason = event.Reason
+	case domain.Cancel:
+		kind = orderCanceled
+	case domain.Timeout:
+		kind = orderTimedOut
+		payload.Now = &event.Now
+	case domain.RequestReturn:
+		kind = returnRequested
+		payload.ReturnID = &event.ReturnID
+		payload.Items = itemsToModels(event.Items)
+	case domain.ConfirmRefund:
+		kind = refundConfirmed
+		payload.ReturnID = &event.ReturnID
+	case domain.ConfirmRestock:
+		kind = restockConfirmed
+		payload.ReturnID = &event.ReturnID
+	default:
+		return ``, pgtype.JSONB{}, fmt.Errorf(`unknown event %T`, event)
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return ``, pgtype.JSONB{}, err
+	}
+	return kind, pgtype.JSONB{Bytes: b, Status: pgtype.Present}, nil
+}
+
+func eventFromModel(kind string, raw pgtype.JSONB) (domain.Event, error) {
+	var payload eventPayload
+	err := json.Unmarshal(raw.Bytes, &payload)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case orderCreated:
+		if payload.OrderID == nil || payload.CustomerID == nil {
+			return nil, fmt.Errorf(`invalid %s event`, kind)
+		}
+		return domain.CreateOrder{OrderID: *payload.OrderID, CustomerID: *payload.CustomerID}, nil
+	case itemAdded:
+		if payload.Item == nil {
+			return nil, fmt.Errorf(`invalid %s event`, kind)
+		}
+		return domain.AddItem{Item: modelsToItems([]Item{*payload.Item}, ``)[0]}, nil
+	case itemRemoved:
+		return domain.RemoveItem{SKU: payload.SKU, Quantity: payload.Quantity, Attributes: payload.Attributes}, nil
+	case orderProcessed:
+		prices := make(domain.PriceList, len(payload.Prices))
+		for sku, price := range payload.Prices {
+			prices[sku] = money.New(price, payload.Currency)
+		}
+		event := domain.Process{Pricing: prices}
+		if len(payload.Discounts) != 0 {
+			discounts := appliedDiscounts{}
+			for _, discount := range payload.Discounts {
+				event.Codes = append(event.Codes, discount.Code)
+				discounts[discount.Code] = money.New(discount.Amount, payload.Currency)
+			}
+			event.Promotions = discounts
+		}
+		if payload.Deadline != nil {
+			event.Deadline = *payload.Deadline
+		}
+		return event, nil
+	case paymentConfirmed:
+		if payload.PaymentID == nil {
+			return nil, fmt.Errorf(`invalid %s event`, kind)
+		}
+		return domain.ConfirmPayment{PaymentID: *payload.PaymentID}, nil
+	case stockConfirmed:
+		return domain.ConfirmStock{}, nil
+	case paymentRejected:
+		return domain.RejectPayment{}, nil
+	case stockRejected:
+		return domain.RejectStock{Reason: payload.Reason}, nil
+	case orderCanceled:
+		return domain.Cancel{}, nil
+	case orderTimedOut:
+		if payload.Now == nil {
+			return nil, fmt.Errorf(`invalid %s event`, kind)
+		}
+		return domain.Timeout{Now: *payload.Now}, nil
+	case returnRequested, refundConfirmed, restockConfirmed:
+		if payload.ReturnID == nil {
+			return nil, fmt.Errorf(`invalid %s event`, kind)
+		}
+		switch kind {
+		case returnRequested:
+			return domain.RequestReturn{ReturnID: *payload.ReturnID, Items: modelsToItems(payload.Items, ``)}, nil
+		case refundConfirmed:
+			return domain.ConfirmRefund{ReturnID: *payload.ReturnID}, nil
+		default:
+			return domain.ConfirmRestock{ReturnID: *payload.ReturnID}, nil
+		}
+	default:
+		return nil, fmt.Errorf(`unknown event type %s`, kind)
+	}
+}
+
+const (
+	insertOrderEventQuery = `INSERT INTO order_events(order_id, version, event_type, payload) VALUES ($1, $2, $3, $4)`
+	insertSnapshotQuery   = `
+	INSERT INTO order_snapshots(order_id, version, customer_id, items, price, currency, payment_id, deadline, discounts, returns, kind)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	findSnapshotQuery = `
+	SELECT version, customer_id, items, price, currency, payment_id, deadline, discounts, returns, kind
+	FROM order_snapshots
+	WHERE order_id = $1 AND ($2 = 0 OR version <= $2)
+	ORDER BY version DESC LIMIT 1`
+	orderEventsQuery = `
+	SELECT version, event_type, payload, created_at
+	FROM order_events
+	WHERE order_id = $1 AND version > $2 AND ($3 = 0 OR version <= $3)
+	ORDER BY version ASC`
+)
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/money"
+)
+
+func Test_eventToModel(t *testing.T) {
+	deadline := time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC)
+	pending := domain.PendingOrder{
+		ActiveOrder: domain.ActiveOrder{
+			EmptyOrder: domain.EmptyOrder{ID: genUUID(t), CustomerID: genUUID(t)},
+			Items:      []domain.Item{{SKU: `test`, Quantity: 2, UnitPrice: money.New(decimal.NewFromFloat(9.99), `USD`)}},
+		},
+		Price:    money.New(decimal.NewFromFloat(19.98), `USD`),
+		Deadline: deadline,
+	}
+	discounted := pending
+	discounted.Discounts = []domain.Discount{{Code: `SALE`, Amount: money.New(decimal.NewFromFloat(2), `USD`)}}
+
+	testcases := map[string]struct {
+		event    domain.Event
+		order    domain.Order
+		expected domain.Event
+	}{
+		`create order`: {
+			event: domain.CreateOrder{OrderID: pending.ID, CustomerID: pending.CustomerID},
+		},
+		`add item`: {
+			event: domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 2, Attributes: map[string]string{`size`: `L`}}},
+		},
+		`remove item`: {
+			event: domain.RemoveItem{SKU: `test`, Quantity: 1},
+		},
+		`remove item with attributes`: {
+			event: domain.RemoveItem{SKU: `test`, Quantity: 1, Attributes: map[string]string{`size`: `L`}},
+		},
+		`process order`: {
+			event:    domain.Process{Deadline: deadline},
+			order:    pending,
+			expected: domain.Process{Pricing: domain.PriceList{`test`: money.New(decimal.NewFromFloat(9.99), `USD`)}, Deadline: deadline},
+		},
+		`process order with discount`: {
+			event: domain.Process{Codes: []string{`SALE`}, Deadline: deadline},
+			order: discounted,
+			expected: domain.Process{
+				Pricing:    domain.PriceList{`test`: money.New(decimal.NewFromFloat(9.99), `USD`)},
+				Promotions: appliedDiscounts{`SALE`: money.New(decimal.NewFromFloat(2), `USD`)},
+				Codes:      []string{`SALE`},
+				Deadline:   deadline,
+			},
+		},
+		`confirm payment`: {
+			event: domain.ConfirmPayment{PaymentID: genUUID(t)},
+		},
+		`reject stock`: {
+			event: domain.RejectStock{},
+		},
+		`reject stock with reason`: {
+			event: domain.RejectStock{Reason: `insufficient stock of test`},
+		},
+		`cancel order`: {
+			event: domain.Cancel{},
+		},
+		`timeout`: {
+			event: domain.Timeout{Now: deadline},
+		},
+		`request return`: {
+			event: domain.RequestReturn{ReturnID: genUUID(t), Items: []domain.Item{{SKU: `test`, Quantity: 1}}},
+		},
+		`confirm refund`: {
+			event: domain.ConfirmRefund{ReturnID: genUUID(t)},
+		},
+		`confirm restock`: {
+			event: domain.ConfirmRestock{ReturnID: genUUID(t)},
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			kind, payload, err := eventToModel(tc.event, tc.order)
+			require.NoError(t, err)
+
+			event, err := eventFromModel(kind, payload)
+			require.NoError(t, err)
+
+			expected := tc.expected
+			if expected == nil {
+				expected = tc.event
+			}
+			require.Equal(t, expected, event)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+// IdempotentResponse is stored response of request with idempotency key.
+type IdempotentResponse struct {
+	Status  int
+	Headers map[string]string
+	Body    []byte
+}
+
+// ReserveIdempotencyKey reserves key for request with given fingerprint until key expires.
+// If key was already used by the same request it returns stored response,
+// ErrIdempotencyKeyReused if key was used by another request and
+// ErrRequestInProgress if response of the first request isn't stored yet.
+// Request without response is in progress until its lease expires, then key
+// is taken over by retry of the same request. Reserved key is returned with
+// token of lease, which is required to store response or release key.
+func ReserveIdempotencyKey(ctx context.Context, key, fingerprint string, now time.Time, ttl, lease time.Duration) (*IdempotentResponse, uuid.UUID, error) {
+	var (
+		stored      string
+		status      pgtype.Int4
+		headers     pgtype.JSONB
+		body        []byte
+		reservedKey string
+	)
+	token := uuid.New()
+	err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, reserveKeyQuery, key, fingerprint, timestampToModel(now), timestampToModel(now.Add(ttl)), timestampToModel(now.Add(lease)), token).Scan(&reservedKey)
+		switch err {
+		case nil:
+			return nil
+		case pgx.ErrNoRows:
+			// key is used by not expired request.
+		default:
+			return err
+		}
+		return tx.QueryRow(ctx, findKeyQuery, key).Scan(&stored, &status, &headers, &body)
+	})
+	switch {
+	case err != nil:
+		return nil, uuid.Nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't reserve idempotency key`)
+	case reservedKey != ``:
+		return nil, token, nil
+	case stored != fingerprint:
+		return nil, uuid.Nil, ErrIdempotencyKeyReused
+	case status.Status != pgtype.Present:
+		return nil, uuid.Nil, ErrRequestInProgress
+	}
+
+	resp := &IdempotentResponse{Status: int(status.Int), Body: body}
+	err = headers.AssignTo(&resp.Headers)
+	if err != nil {
+		return nil, uuid.Nil, errors.MarkAndWrapError(err, ErrInfrastructure, `invalid stored response headers`)
+	}
+	return resp, uuid.Nil, nil
+}
+
+// SaveIdempotentResponse stores response of request, which holds lease of key,
+// ErrLeaseLost is returned if lease was taken over by retry of the request.
+func SaveIdempotentResponse(ctx context.Context, key string, token uuid.UUID, resp IdempotentResponse) error {
+	headers := pgtype.JSONB{}
+	err := headers.Set(resp.Headers)
+	if err != nil {
+		return errors.MarkAndWrapError(err, ErrInfrastructure, `invalid response headers`)
+	}
+	tag, err := pool.Exec(ctx, saveResponseQuery, key, resp.Status, headers, resp.Body, token)
+	switch {
+	case err != nil:
+		return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't save idempotent response`)
+	case tag.RowsAffected() == 0:
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey removes reservation of key, which holds lease, so request can be retried.
+func ReleaseIdempotencyKey(ctx context.Context, key string, token uuid.UUID) error {
+	_, err := pool.Exec(ctx, releaseKeyQuery, key, token)
+	if err != nil {
+		return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't release idempotency key`)
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeys removes expired keys and returns number of removed keys.
+func PurgeIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := pool.Exec(ctx, purgeKeysQuery, timestampToModel(now))
+	if err != nil {
+		return 0, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't purge idempotency keys`)
+	}
+	return tag.RowsAffected(), nil
+}
+
+const (
+	// reserveKeyQuery inserts new key or takes over expired one or one with expired lease of the same request.
+	reserveKeyQuery = `
+	INSERT INTO idempotency_keys(key, fingerprint, expires_at, locked_until, lease_token) VALUES ($1, $2, $4, $5, $6)
+	ON CONFLICT (key) DO UPDATE
+	SET fingerprint = EXCLUDED.fingerprint, status = NULL, headers = NULL, body = NULL, created_at = CURRENT_TIMESTAMP,
+		expires_at = EXCLUDED.expires_at, locked_until = EXCLUDED.locked_until, lease_token = EXCLUDED.lease_token
+	WHERE idempotency_keys.expires_at <= $3
+		OR idempotency_keys.status IS NULL AND idempotency_keys.locked_until <= $3
+		AND idempotency_keys.fingerprint = EXCLUDED.fingerprint
+	RETURNING key`
+	findKeyQuery      = `SELECT fingerprint, status, headers, body FROM idempotency_keys WHERE key = $1`
+	saveResponseQuery = `
+	UPDATE idempotency_keys SET status = $2, headers = $3, body = $4
+	WHERE key = $1 AND lease_token = $5 AND status IS NULL`
+	releaseKeyQuery = `DELETE FROM idempotency_keys WHERE key = $1 AND lease_token = $2 AND status IS NULL`
+	purgeKeysQuery  = `DELETE FROM idempotency_keys WHERE expires_at <= $1`
+)
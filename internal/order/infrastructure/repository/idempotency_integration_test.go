@@ -0,0 +1,80 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_IdempotencyKeys(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	key := genUUID(t).String()
+	now := time.Now()
+
+	stored, crashed, err := ReserveIdempotencyKey(ctx, key, `first`, now, time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, stored)
+
+	_, _, err = ReserveIdempotencyKey(ctx, key, `first`, now, time.Hour, time.Minute)
+	require.ErrorIs(t, err, ErrRequestInProgress)
+
+	// lease of crashed request is taken over only by the same request.
+	_, _, err = ReserveIdempotencyKey(ctx, key, `second`, now.Add(2*time.Minute), time.Hour, time.Minute)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+	stored, token, err := ReserveIdempotencyKey(ctx, key, `first`, now.Add(2*time.Minute), time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, stored)
+	require.NotEqual(t, crashed, token)
+
+	resp := IdempotentResponse{
+		Status:  http.StatusCreated,
+		Headers: map[string]string{`ETag`: `"1"`},
+		Body:    []byte(`{"id":"test"}`),
+	}
+	// request, which lost its lease, neither stores response nor releases key.
+	err = SaveIdempotentResponse(ctx, key, crashed, IdempotentResponse{Status: http.StatusOK})
+	require.ErrorIs(t, err, ErrLeaseLost)
+	require.NoError(t, ReleaseIdempotencyKey(ctx, key, crashed))
+	err = SaveIdempotentResponse(ctx, key, token, resp)
+	require.NoError(t, err)
+	err = SaveIdempotentResponse(ctx, key, crashed, IdempotentResponse{Status: http.StatusOK})
+	require.ErrorIs(t, err, ErrLeaseLost)
+
+	stored, _, err = ReserveIdempotencyKey(ctx, key, `first`, now, time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, &resp, stored)
+
+	_, _, err = ReserveIdempotencyKey(ctx, key, `second`, now, time.Hour, time.Minute)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	// expired key is taken over by new request.
+	stored, token, err = ReserveIdempotencyKey(ctx, key, `second`, now.Add(2*time.Hour), time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, stored)
+
+	err = ReleaseIdempotencyKey(ctx, key, token)
+	require.NoError(t, err)
+	stored, _, err = ReserveIdempotencyKey(ctx, key, `third`, now, time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, stored)
+
+	purged, err := PurgeIdempotencyKeys(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, int64(1))
+}
@@ -0,0 +1,55 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/inbox"
+	"github.com/moeryomenko/saga/pkg/saga"
+)
+
+func TestIntegration_RedeliveredReply(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	_, err = pool.Exec(ctx, `
+	INSERT INTO products(sku, name, price) VALUES ('test', 'test', 9.99)
+	ON CONFLICT (sku) DO UPDATE SET price = EXCLUDED.price`)
+	require.NoError(t, err)
+
+	orderID := genUUID(t)
+	for _, event := range []domain.Event{
+		domain.CreateOrder{OrderID: orderID, CustomerID: genUUID(t)},
+		domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+		domain.Process{},
+	} {
+		_, _, err = PersistOrder(ctx, orderID, domain.AnyVersion, event)
+		require.NoError(t, err)
+	}
+
+	msg := inbox.Message{Stream: saga.ReplyStream, ID: genUUID(t).String()}
+	_, err = PersistReply(ctx, msg, orderID, domain.ConfirmStock{})
+	require.NoError(t, err)
+
+	// reply is redelivered, when order service crashed before acknowledgment.
+	_, err = PersistReply(ctx, msg, orderID, domain.ConfirmStock{})
+	require.ErrorIs(t, err, ErrDuplicateMessage)
+
+	var events int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT count(*) FROM order_events WHERE order_id = $1`, orderID).Scan(&events))
+	require.Equal(t, 4, events)
+}
@@ -0,0 +1,59 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_RelayLeadership(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	leader, err := EventLog().TryLead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, leader)
+	require.NoError(t, leader.Check(ctx))
+
+	// leadership is held by session, so other replica can't take it.
+	follower, err := EventLog().TryLead(ctx)
+	require.NoError(t, err)
+	require.Nil(t, follower)
+
+	leader.Release()
+	follower, err = EventLog().TryLead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, follower)
+	follower.Release()
+
+	// ack of relay, which lost leadership, doesn't move offset back.
+	_, err = pool.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
+	require.NoError(t, err)
+	var ids []int
+	for i := 0; i < 2; i++ {
+		var id int
+		err = pool.QueryRow(ctx, `INSERT INTO event_log(payload, event_kind) VALUES ('{"type": "test"}', 'test') RETURNING id`).Scan(&id)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	require.NoError(t, EventLog().Ack(ctx, ids[1]))
+	require.NoError(t, EventLog().Ack(ctx, ids[0]))
+
+	var offset int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT offset_acked FROM event_offset`).Scan(&offset))
+	require.Equal(t, ids[1], offset)
+	_, err = pool.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
+	require.NoError(t, err)
+}
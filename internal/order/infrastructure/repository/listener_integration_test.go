@@ -0,0 +1,57 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+)
+
+func TestIntegration_EventListener(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	_, err = pool.Exec(ctx, `
+	INSERT INTO products(sku, name, price) VALUES ('test', 'test', 9.99)
+	ON CONFLICT (sku) DO UPDATE SET price = EXCLUDED.price`)
+	require.NoError(t, err)
+
+	listener, err := EventLog().Listen(ctx)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// timeout isn't error, relay falls back to polling.
+	require.NoError(t, listener.Wait(ctx, 10*time.Millisecond))
+
+	orderID := genUUID(t)
+	for _, event := range []domain.Event{
+		domain.CreateOrder{OrderID: orderID, CustomerID: genUUID(t)},
+		domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+		domain.Process{},
+	} {
+		_, _, err = PersistOrder(ctx, orderID, domain.AnyVersion, event)
+		require.NoError(t, err)
+	}
+
+	started := time.Now()
+	require.NoError(t, listener.Wait(ctx, time.Minute))
+	require.Less(t, time.Since(started), time.Minute)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, listener.Wait(canceled, time.Minute), context.Canceled)
+}
@@ -3,11 +3,14 @@ package repository
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgtype"
 	"github.com/shopspring/decimal"
 
 	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/money"
 )
 
 const (
@@ -18,31 +21,190 @@ const (
 	paid      = `paid`
 	complited = `completed`
 	canceled  = `canceled`
+
+	partiallyReturned = `partially_returned`
+	returned          = `returned`
 )
 
 type Order struct {
 	OrderID    pgtype.UUID
 	CustomerID pgtype.UUID
 	Items      pgtype.JSONB
+	Discounts  pgtype.JSONB
+	Returns    pgtype.JSONB
 	Price      *decimal.Decimal
+	Currency   *string
 	PaymentID  pgtype.UUID
+	Deadline   pgtype.Timestamp
 	Kind       string
+	Version    int
+	CreatedAt  pgtype.Timestamp
+	UpdatedAt  pgtype.Timestamp
+}
+
+type Item struct {
+	SKU        string            `json:"sku"`
+	Quantity   int               `json:"quantity"`
+	UnitPrice  *decimal.Decimal  `json:"unit_price,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
-func itemsMap(i pgtype.JSONB) []string {
-	var items []string
-	_ = json.Unmarshal(i.Bytes, &items)
+// itemsMap maps items, unit prices are in currency of order.
+func itemsMap(i pgtype.JSONB, currency money.Currency) []domain.Item {
+	var models []Item
+	_ = json.Unmarshal(i.Bytes, &models)
+	if models == nil {
+		return nil
+	}
+	return modelsToItems(models, currency)
+}
+
+func itemsToModel(items []domain.Item) pgtype.JSONB {
+	b, err := json.Marshal(itemsToModels(items))
+	if err != nil {
+		return pgtype.JSONB{Status: pgtype.Null}
+	}
+	return pgtype.JSONB{Bytes: b, Status: pgtype.Present}
+}
+
+func modelsToItems(models []Item, currency money.Currency) []domain.Item {
+	items := make([]domain.Item, 0, len(models))
+	for _, model := range models {
+		item := domain.Item{
+			SKU:        model.SKU,
+			Quantity:   model.Quantity,
+			Attributes: model.Attributes,
+		}
+		if model.UnitPrice != nil {
+			item.UnitPrice = money.New(*model.UnitPrice, currency)
+		}
+		items = append(items, item)
+	}
 	return items
 }
 
-func itemsToModel(items []string) pgtype.JSONB {
-	b, err := json.Marshal(items)
+func itemsToModels(items []domain.Item) []Item {
+	models := make([]Item, 0, len(items))
+	for _, item := range items {
+		model := Item{
+			SKU:        item.SKU,
+			Quantity:   item.Quantity,
+			Attributes: item.Attributes,
+		}
+		if !item.UnitPrice.IsZero() {
+			price := item.UnitPrice.Amount
+			model.UnitPrice = &price
+		}
+		models = append(models, model)
+	}
+	return models
+}
+
+type Discount struct {
+	Code   string          `json:"code"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+func discountsMap(d pgtype.JSONB, currency money.Currency) []domain.Discount {
+	var models []Discount
+	_ = json.Unmarshal(d.Bytes, &models)
+	if len(models) == 0 {
+		return nil
+	}
+
+	discounts := make([]domain.Discount, 0, len(models))
+	for _, model := range models {
+		discounts = append(discounts, domain.Discount{Code: model.Code, Amount: money.New(model.Amount, currency)})
+	}
+	return discounts
+}
+
+func discountsToModel(discounts []domain.Discount) pgtype.JSONB {
+	if len(discounts) == 0 {
+		return pgtype.JSONB{Status: pgtype.Null}
+	}
+
+	models := make([]Discount, 0, len(discounts))
+	for _, discount := range discounts {
+		models = append(models, Discount{Code: discount.Code, Amount: discount.Amount.Amount})
+	}
+	b, err := json.Marshal(models)
+	if err != nil {
+		return pgtype.JSONB{Status: pgtype.Null}
+	}
+	return pgtype.JSONB{Bytes: b, Status: pgtype.Present}
+}
+
+func currencyToModel(currency money.Currency) *string {
+	if currency == `` {
+		return nil
+	}
+	code := string(currency)
+	return &code
+}
+
+type Return struct {
+	ID        uuid.UUID       `json:"id"`
+	Items     []Item          `json:"items"`
+	Refund    decimal.Decimal `json:"refund"`
+	Refunded  bool            `json:"refunded"`
+	Restocked bool            `json:"restocked"`
+}
+
+func returnsMap(r pgtype.JSONB, currency money.Currency) []domain.Return {
+	var models []Return
+	_ = json.Unmarshal(r.Bytes, &models)
+
+	returns := make([]domain.Return, 0, len(models))
+	for _, model := range models {
+		returns = append(returns, domain.Return{
+			ID:        model.ID,
+			Items:     modelsToItems(model.Items, currency),
+			Refund:    money.New(model.Refund, currency),
+			Refunded:  model.Refunded,
+			Restocked: model.Restocked,
+		})
+	}
+	return returns
+}
+
+func returnsToModel(returns []domain.Return) pgtype.JSONB {
+	models := make([]Return, 0, len(returns))
+	for _, r := range returns {
+		models = append(models, Return{
+			ID:        r.ID,
+			Items:     itemsToModels(r.Items),
+			Refund:    r.Refund.Amount,
+			Refunded:  r.Refunded,
+			Restocked: r.Restocked,
+		})
+	}
+	b, err := json.Marshal(models)
 	if err != nil {
 		return pgtype.JSONB{Status: pgtype.Null}
 	}
 	return pgtype.JSONB{Bytes: b, Status: pgtype.Present}
 }
 
+func timestampToModel(t time.Time) pgtype.Timestamp {
+	if t.IsZero() {
+		return pgtype.Timestamp{Status: pgtype.Null}
+	}
+	return pgtype.Timestamp{Time: t.UTC(), Status: pgtype.Present}
+}
+
+func mapToView(o *Order) domain.OrderView {
+	view := domain.OrderView{
+		Order:     mapToDomain(o),
+		Version:   o.Version,
+		CreatedAt: o.CreatedAt.Time,
+	}
+	if o.UpdatedAt.Status == pgtype.Present {
+		view.UpdatedAt = o.UpdatedAt.Time
+	}
+	return view
+}
+
 func mapToDomain(o *Order) domain.Order {
 	if o == nil {
 		return nil
@@ -60,80 +222,75 @@ func mapToDomain(o *Order) domain.Order {
 				ID:         o.OrderID.Bytes,
 				CustomerID: o.CustomerID.Bytes,
 			},
-			Items: itemsMap(o.Items),
+			Items: itemsMap(o.Items, ``),
 		}
 	case pending:
-		return domain.PendingOrder{
-			ActiveOrder: domain.ActiveOrder{
-				EmptyOrder: domain.EmptyOrder{
-					ID:         o.OrderID.Bytes,
-					CustomerID: o.CustomerID.Bytes,
-				},
-				Items: itemsMap(o.Items),
-			},
-			Price: *o.Price,
-		}
+		return pendingOrder(o)
 	case stocked:
 		return domain.StockedOrder{
-			PendingOrder: domain.PendingOrder{
-				ActiveOrder: domain.ActiveOrder{
-					EmptyOrder: domain.EmptyOrder{
-						ID:         o.OrderID.Bytes,
-						CustomerID: o.CustomerID.Bytes,
-					},
-					Items: itemsMap(o.Items),
-				},
-				Price: *o.Price,
-			},
+			PendingOrder: pendingOrder(o),
 		}
 	case paid:
 		return domain.PaidOrder{
-			PendingOrder: domain.PendingOrder{
-				ActiveOrder: domain.ActiveOrder{
-					EmptyOrder: domain.EmptyOrder{
-						ID:         o.OrderID.Bytes,
-						CustomerID: o.CustomerID.Bytes,
-					},
-					Items: itemsMap(o.Items),
-				},
-				Price: *o.Price,
-			},
-			PaymentID: o.PaymentID.Bytes,
+			PendingOrder: pendingOrder(o),
+			PaymentID:    o.PaymentID.Bytes,
 		}
 	case complited:
-		return domain.CompletedOrder{
-			PaidOrder: domain.PaidOrder{
-				PendingOrder: domain.PendingOrder{
-					ActiveOrder: domain.ActiveOrder{
-						EmptyOrder: domain.EmptyOrder{
-							ID:         o.OrderID.Bytes,
-							CustomerID: o.CustomerID.Bytes,
-						},
-						Items: itemsMap(o.Items),
-					},
-					Price: *o.Price,
-				},
-				PaymentID: o.PaymentID.Bytes,
-			},
-		}
+		return completedOrder(o, pendingOrder(o))
 	case canceled:
 		return domain.CanceledOrder{
-			PendingOrder: domain.PendingOrder{
-				ActiveOrder: domain.ActiveOrder{
-					EmptyOrder: domain.EmptyOrder{
-						ID:         o.OrderID.Bytes,
-						CustomerID: o.CustomerID.Bytes,
-					},
-					Items: itemsMap(o.Items),
-				},
-				Price: *o.Price,
-			},
+			PendingOrder: pendingOrder(o),
+			PaymentID:    o.PaymentID.Bytes,
+		}
+	case partiallyReturned:
+		order := pendingOrder(o)
+		return domain.PartiallyReturnedOrder{
+			CompletedOrder: completedOrder(o, order),
+			Returns:        returnsMap(o.Returns, order.Price.Currency),
+		}
+	case returned:
+		order := pendingOrder(o)
+		return domain.ReturnedOrder{
+			CompletedOrder: completedOrder(o, order),
+			Returns:        returnsMap(o.Returns, order.Price.Currency),
 		}
 	}
 
 	return nil
 }
 
+func pendingOrder(o *Order) domain.PendingOrder {
+	var currency money.Currency
+	if o.Currency != nil {
+		currency = money.Currency(*o.Currency)
+	}
+	return domain.PendingOrder{
+		ActiveOrder: domain.ActiveOrder{
+			EmptyOrder: domain.EmptyOrder{
+				ID:         o.OrderID.Bytes,
+				CustomerID: o.CustomerID.Bytes,
+			},
+			Items: itemsMap(o.Items, currency),
+		},
+		Discounts: discountsMap(o.Discounts, currency),
+		Price:     money.New(*o.Price, currency),
+		Deadline:  o.Deadline.Time,
+	}
+}
+
+func completedOrder(o *Order, order domain.PendingOrder) domain.CompletedOrder {
+	return domain.CompletedOrder{
+		PaidOrder: domain.PaidOrder{
+			PendingOrder: order,
+			PaymentID:    o.PaymentID.Bytes,
+		},
+	}
+}
+
+func priceToModel(price money.Money) (*decimal.Decimal, *string) {
+	return &price.Amount, currencyToModel(price.Currency)
+}
+
 func mapToModel(o domain.Order) (*Order, error) {
 	if o == nil {
 		return nil, errors.New(`invalid order`)
@@ -143,7 +300,10 @@ func mapToModel(o domain.Order) (*Order, error) {
 		OrderID:    pgtype.UUID{Bytes: o.GetID(), Status: pgtype.Present},
 		CustomerID: pgtype.UUID{Bytes: o.GetCustomerID(), Status: pgtype.Present},
 		Items:      pgtype.JSONB{Status: pgtype.Null},
+		Discounts:  pgtype.JSONB{Status: pgtype.Null},
+		Returns:    pgtype.JSONB{Status: pgtype.Null},
 		PaymentID:  pgtype.UUID{Status: pgtype.Null},
+		Deadline:   pgtype.Timestamp{Status: pgtype.Null},
 	}
 
 	switch o := any(o).(type) {
@@ -154,26 +314,55 @@ func mapToModel(o domain.Order) (*Order, error) {
 		order.Kind = active
 	case domain.PendingOrder:
 		order.Items = itemsToModel(o.Items)
-		order.Price = &o.Price
+		order.Price, order.Currency = priceToModel(o.Price)
+		order.Discounts = discountsToModel(o.Discounts)
+		order.Deadline = timestampToModel(o.Deadline)
 		order.Kind = pending
 	case domain.StockedOrder:
 		order.Items = itemsToModel(o.Items)
-		order.Price = &o.Price
+		order.Price, order.Currency = priceToModel(o.Price)
+		order.Discounts = discountsToModel(o.Discounts)
+		order.Deadline = timestampToModel(o.Deadline)
 		order.Kind = stocked
 	case domain.PaidOrder:
 		order.Items = itemsToModel(o.Items)
-		order.Price = &o.Price
+		order.Price, order.Currency = priceToModel(o.Price)
+		order.Discounts = discountsToModel(o.Discounts)
+		order.Deadline = timestampToModel(o.Deadline)
 		order.PaymentID = pgtype.UUID{Bytes: o.PaymentID, Status: pgtype.Present}
 		order.Kind = paid
 	case domain.CompletedOrder:
 		order.Items = itemsToModel(o.Items)
-		order.Price = &o.Price
+		order.Price, order.Currency = priceToModel(o.Price)
+		order.Discounts = discountsToModel(o.Discounts)
+		order.Deadline = timestampToModel(o.Deadline)
 		order.PaymentID = pgtype.UUID{Bytes: o.PaymentID, Status: pgtype.Present}
 		order.Kind = complited
 	case domain.CanceledOrder:
 		order.Items = itemsToModel(o.Items)
-		order.Price = &o.Price
+		order.Price, order.Currency = priceToModel(o.Price)
+		order.Discounts = discountsToModel(o.Discounts)
+		order.Deadline = timestampToModel(o.Deadline)
+		if o.PaymentID != uuid.Nil {
+			order.PaymentID = pgtype.UUID{Bytes: o.PaymentID, Status: pgtype.Present}
+		}
 		order.Kind = canceled
+	case domain.PartiallyReturnedOrder:
+		order.Items = itemsToModel(o.Items)
+		order.Price, order.Currency = priceToModel(o.Price)
+		order.Discounts = discountsToModel(o.Discounts)
+		order.Deadline = timestampToModel(o.Deadline)
+		order.PaymentID = pgtype.UUID{Bytes: o.PaymentID, Status: pgtype.Present}
+		order.Returns = returnsToModel(o.Returns)
+		order.Kind = partiallyReturned
+	case domain.ReturnedOrder:
+		order.Items = itemsToModel(o.Items)
+		order.Price, order.Currency = priceToModel(o.Price)
+		order.Discounts = discountsToModel(o.Discounts)
+		order.Deadline = timestampToModel(o.Deadline)
+		order.PaymentID = pgtype.UUID{Bytes: o.PaymentID, Status: pgtype.Present}
+		order.Returns = returnsToModel(o.Returns)
+		order.Kind = returned
 	default:
 		return nil, errors.New(`invalid order`)
 	}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/money"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
@@ -28,7 +30,7 @@ func Test_mapToModel(t *testing.T) {
 					ID:         genUUID(t),
 					CustomerID: genUUID(t),
 				},
-				Items: []string{`test`},
+				Items: []domain.Item{{SKU: `test`, Quantity: 1}},
 			},
 		},
 		{
@@ -39,9 +41,10 @@ func Test_mapToModel(t *testing.T) {
 						ID:         genUUID(t),
 						CustomerID: genUUID(t),
 					},
-					Items: []string{`test`},
+					Items: []domain.Item{{SKU: `test`, Quantity: 1, UnitPrice: money.New(decimal.NewFromFloat32(9.99), `USD`)}},
 				},
-				Price: decimal.NewFromFloat32(9.99),
+				Price:    money.New(decimal.NewFromFloat32(9.99), `USD`),
+				Deadline: time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC),
 			},
 		},
 		{
@@ -53,9 +56,10 @@ func Test_mapToModel(t *testing.T) {
 							ID:         genUUID(t),
 							CustomerID: genUUID(t),
 						},
-						Items: []string{`test`},
+						Items: []domain.Item{{SKU: `test`, Quantity: 1, UnitPrice: money.New(decimal.NewFromFloat32(9.99), `USD`)}},
 					},
-					Price: decimal.NewFromFloat32(9.99),
+					Price:    money.New(decimal.NewFromFloat32(9.99), `USD`),
+					Deadline: time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC),
 				},
 			},
 		},
@@ -68,9 +72,10 @@ func Test_mapToModel(t *testing.T) {
 							ID:         genUUID(t),
 							CustomerID: genUUID(t),
 						},
-						Items: []string{`test`},
+						Items: []domain.Item{{SKU: `test`, Quantity: 1, UnitPrice: money.New(decimal.NewFromFloat32(9.99), `USD`)}},
 					},
-					Price: decimal.NewFromFloat32(9.99),
+					Price:    money.New(decimal.NewFromFloat32(9.99), `USD`),
+					Deadline: time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC),
 				},
 				PaymentID: genUUID(t),
 			},
@@ -85,9 +90,10 @@ func Test_mapToModel(t *testing.T) {
 								ID:         genUUID(t),
 								CustomerID: genUUID(t),
 							},
-							Items: []string{`test`},
+							Items: []domain.Item{{SKU: `test`, Quantity: 1, UnitPrice: money.New(decimal.NewFromFloat32(9.99), `USD`)}},
 						},
-						Price: decimal.NewFromFloat32(9.99),
+						Price:    money.New(decimal.NewFromFloat32(9.99), `USD`),
+						Deadline: time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC),
 					},
 					PaymentID: genUUID(t),
 				},
@@ -102,10 +108,67 @@ func Test_mapToModel(t *testing.T) {
 							ID:         genUUID(t),
 							CustomerID: genUUID(t),
 						},
-						Items: []string{`test`},
+						Items: []domain.Item{{SKU: `test`, Quantity: 1, UnitPrice: money.New(decimal.NewFromFloat32(9.99), `USD`)}},
 					},
-					Price: decimal.NewFromFloat32(9.99),
+					Price:    money.New(decimal.NewFromFloat32(9.99), `USD`),
+					Deadline: time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC),
 				},
+				PaymentID: genUUID(t),
+			},
+		},
+		{
+			expected: partiallyReturned,
+			order: domain.PartiallyReturnedOrder{
+				CompletedOrder: domain.CompletedOrder{
+					PaidOrder: domain.PaidOrder{
+						PendingOrder: domain.PendingOrder{
+							ActiveOrder: domain.ActiveOrder{
+								EmptyOrder: domain.EmptyOrder{
+									ID:         genUUID(t),
+									CustomerID: genUUID(t),
+								},
+								Items: []domain.Item{{SKU: `test`, Quantity: 2, UnitPrice: money.New(decimal.NewFromFloat32(9.99), `USD`)}},
+							},
+							Price:    money.New(decimal.NewFromFloat32(19.98), `USD`),
+							Deadline: time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC),
+						},
+						PaymentID: genUUID(t),
+					},
+				},
+				Returns: []domain.Return{{
+					ID:       genUUID(t),
+					Items:    []domain.Item{{SKU: `test`, Quantity: 1, UnitPrice: money.New(decimal.NewFromFloat32(9.99), `USD`)}},
+					Refund:   money.New(decimal.NewFromFloat32(9.99), `USD`),
+					Refunded: true,
+				}},
+			},
+		},
+		{
+			expected: returned,
+			order: domain.ReturnedOrder{
+				CompletedOrder: domain.CompletedOrder{
+					PaidOrder: domain.PaidOrder{
+						PendingOrder: domain.PendingOrder{
+							ActiveOrder: domain.ActiveOrder{
+								EmptyOrder: domain.EmptyOrder{
+									ID:         genUUID(t),
+									CustomerID: genUUID(t),
+								},
+								Items: []domain.Item{{SKU: `test`, Quantity: 1, UnitPrice: money.New(decimal.NewFromFloat32(9.99), `USD`)}},
+							},
+							Price:    money.New(decimal.NewFromFloat32(9.99), `USD`),
+							Deadline: time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC),
+						},
+						PaymentID: genUUID(t),
+					},
+				},
+				Returns: []domain.Return{{
+					ID:        genUUID(t),
+					Items:     []domain.Item{{SKU: `test`, Quantity: 1, UnitPrice: money.New(decimal.NewFromFloat32(9.99), `USD`)}},
+					Refund:    money.New(decimal.NewFromFloat32(9.99), `USD`),
+					Refunded:  true,
+					Restocked: true,
+				}},
 			},
 		},
 	}
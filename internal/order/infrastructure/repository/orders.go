@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgtype"
@@ -9,50 +10,104 @@ import (
 
 	"github.com/moeryomenko/saga/internal/order/domain"
 	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/inbox"
 )
 
-func PersistOrder(ctx context.Context, orderID uuid.UUID, event domain.Event) (domain.Order, error) {
-	var order domain.Order
+// PersistOrder applies event to order rebuilt from event store, appends event
+// to the store and updates orders projection in the same transaction.
+// If expected version isn't domain.AnyVersion and order was changed since that version
+// it returns domain.VersionConflict. It returns order with its new version.
+// Process event without pricing and promotions is priced by catalog and
+// discount codes in the same transaction.
+func PersistOrder(ctx context.Context, orderID uuid.UUID, expected int, event domain.Event) (domain.Order, int, error) {
+	var (
+		order   domain.Order
+		version int
+	)
 	err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) (err error) {
-		order, err = findOrderByID(ctx, tx, orderID)
-		switch err {
-		case nil, pgx.ErrNoRows:
-			// it's ok for CreateOrder event.
-		default:
-			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find order`)
-		}
-
-		order, err = domain.Apply(order, event)
-		if err != nil {
-			return errors.MarkAndWrapError(err, domain.ErrDomain, `couldn't apply event`)
-		}
+		order, version, err = persistOrder(ctx, tx, orderID, expected, event)
+		return err
+	})
+	return order, version, err
+}
 
-		err = saveOrder(ctx, tx, order)
-		if err != nil {
-			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't save order`)
+// PersistReply persists event of saga reply like PersistOrder, message of reply is received
+// by inbox in the same transaction, so redelivered reply returns ErrDuplicateMessage.
+func PersistReply(ctx context.Context, msg inbox.Message, orderID uuid.UUID, event domain.Event) (domain.Order, error) {
+	var order domain.Order
+	err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		err := inbox.Receive(ctx, tx, msg)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrDuplicateMessage):
+			return err
+		default:
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't receive message`)
 		}
 
-		return insertEvent(ctx, tx, order)
+		order, _, err = persistOrder(ctx, tx, orderID, domain.AnyVersion, event)
+		return err
 	})
 	return order, err
 }
 
-func findOrderByID(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) (domain.Order, error) {
-	order := &Order{OrderID: pgtype.UUID{Bytes: orderID}}
-	err := tx.QueryRow(ctx, findOrderQuery, orderID.String()).Scan(
-		&order.CustomerID,
-		&order.Items,
-		&order.Price,
-		&order.PaymentID,
-		&order.Kind,
-	)
+// persistOrder applies event to order in transaction, see PersistOrder.
+func persistOrder(ctx context.Context, tx pgx.Tx, orderID uuid.UUID, expected int, event domain.Event) (domain.Order, int, error) {
+	// serializes concurrent changes of the same order.
+	_, err := tx.Exec(ctx, lockOrderQuery, orderID.String())
+	if err != nil {
+		return nil, 0, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't lock order`)
+	}
+
+	prev, current, err := loadOrder(ctx, tx, orderID, 0)
+	if err != nil {
+		return nil, 0, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't load order`)
+	}
+	if expected != domain.AnyVersion && expected != current {
+		return nil, 0, domain.VersionConflict{Expected: expected, Current: current}
+	}
+
+	if process, ok := event.(domain.Process); ok {
+		if process.Pricing == nil {
+			process.Pricing, err = catalogPrices(ctx, tx, prev)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		if process.Promotions == nil {
+			process.Promotions, err = lockPromotions(ctx, tx, prev, process.Codes)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		event = process
+	}
+
+	order, err := domain.Apply(prev, event)
+	if err != nil {
+		return nil, 0, errors.MarkAndWrapError(err, domain.ErrDomain, `couldn't apply event`)
+	}
+
+	version := current + 1
+	err = appendEvent(ctx, tx, version, event, order)
+	if err != nil {
+		return nil, 0, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't append event to store`)
+	}
+
+	err = saveOrder(ctx, tx, order, version)
+	if err != nil {
+		return nil, 0, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't save order`)
+	}
+
+	err = saveUsages(ctx, tx, prev, order)
 	if err != nil {
-		return nil, err
+		return nil, 0, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't save promotion usages`)
 	}
-	return mapToDomain(order), nil
+
+	return order, version, orchestrate(ctx, tx, order, event)
 }
 
-func saveOrder(ctx context.Context, tx pgx.Tx, order domain.Order) error {
+func saveOrder(ctx context.Context, tx pgx.Tx, order domain.Order, version int) error {
 	model, err := mapToModel(order)
 	if err != nil {
 		return err
@@ -64,16 +119,149 @@ func saveOrder(ctx context.Context, tx pgx.Tx, order domain.Order) error {
 	default:
 		query = updateOrderQuery
 	}
-	_, err = tx.Exec(ctx, query, model.OrderID, model.CustomerID, model.Items, model.Price, model.PaymentID, model.Kind)
+	_, err = tx.Exec(ctx, query, model.OrderID, model.CustomerID, model.Items, model.Price, model.PaymentID, model.Deadline, model.Kind, version, model.Discounts, model.Currency, model.Returns)
 	return err
 }
 
+// FindExpiredOrders returns ids of orders, which saga deadline passed.
+func FindExpiredOrders(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	rows, err := pool.Query(ctx, findExpiredOrdersQuery, now.UTC(), limit)
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find expired orders`)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan expired order`)
+		}
+		ids = append(ids, id.Bytes)
+	}
+	if rows.Err() != nil {
+		return nil, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't find expired orders`)
+	}
+	return ids, nil
+}
+
+// defaultPageSize is number of orders on page if limit isn't set.
+const defaultPageSize = 20
+
+func FindOrder(ctx context.Context, orderID uuid.UUID) (domain.OrderView, error) {
+	order := &Order{}
+	err := scanOrder(pool.QueryRow(ctx, getOrderQuery, orderID.String()), order)
+	switch err {
+	case nil:
+		return mapToView(order), nil
+	case pgx.ErrNoRows:
+		return domain.OrderView{}, errors.MarkAndWrapError(domain.ErrOrderNotFound, domain.ErrDomain, orderID.String())
+	default:
+		return domain.OrderView{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find order`)
+	}
+}
+
+// ListCustomerOrders returns customer orders ordered from newest to oldest.
+func ListCustomerOrders(ctx context.Context, customerID uuid.UUID, filter domain.OrdersFilter) (domain.OrdersPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+
+	after := cursor{}
+	if filter.Cursor != `` {
+		var err error
+		after, err = parseCursor(filter.Cursor)
+		if err != nil {
+			return domain.OrdersPage{}, err
+		}
+	}
+
+	rows, err := pool.Query(ctx, listCustomerOrdersQuery,
+		customerID.String(),
+		filter.Kinds,
+		timestampToModel(filter.CreatedFrom),
+		timestampToModel(filter.CreatedTo),
+		timestampToModel(filter.UpdatedFrom),
+		timestampToModel(filter.UpdatedTo),
+		timestampToModel(after.createdAt),
+		after.orderID.String(),
+		// fetch one more order to know if there is the next page.
+		filter.Limit+1,
+	)
+	if err != nil {
+		return domain.OrdersPage{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't list orders`)
+	}
+	defer rows.Close()
+
+	page := domain.OrdersPage{Orders: []domain.OrderView{}}
+	for rows.Next() {
+		order := &Order{}
+		err = scanOrder(rows, order)
+		if err != nil {
+			return domain.OrdersPage{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan order`)
+		}
+		if len(page.Orders) == filter.Limit {
+			last := page.Orders[len(page.Orders)-1]
+			page.NextCursor = cursor{createdAt: last.CreatedAt, orderID: last.Order.GetID()}.String()
+			break
+		}
+		page.Orders = append(page.Orders, mapToView(order))
+	}
+	if rows.Err() != nil {
+		return domain.OrdersPage{}, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't list orders`)
+	}
+	return page, nil
+}
+
+func scanOrder(row pgx.Row, order *Order) error {
+	return row.Scan(
+		&order.OrderID,
+		&order.CustomerID,
+		&order.Items,
+		&order.Price,
+		&order.Currency,
+		&order.PaymentID,
+		&order.Deadline,
+		&order.Discounts,
+		&order.Returns,
+		&order.Kind,
+		&order.Version,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+}
+
 const (
-	findOrderQuery   = `SELECT customer_id, items, price, payment_id, kind FROM orders WHERE order_id = $1 FOR UPDATE`
+	lockOrderQuery   = `SELECT 1 FROM orders WHERE order_id = $1 FOR UPDATE`
 	insertOrderQuery = `
-	INSERT INTO orders(order_id, customer_id, items, price, payment_id, kind)
-	VALUES ($1, $2, $3, $4, $5, $6)
+	INSERT INTO orders(order_id, customer_id, items, price, payment_id, deadline, kind, version, discounts, currency, returns)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	ON CONFLICT (order_id) DO UPDATE
-	SET kind='empty'::order_kind, items=EXCLUDED.items, updated_at=CURRENT_TIMESTAMP`
-	updateOrderQuery = `UPDATE orders SET customer_id = $2, items = $3, price = $4, payment_id = $5, kind = $6, updated_at = CURRENT_TIMESTAMP WHERE order_id = $1`
+	SET kind='empty'::order_kind, items=EXCLUDED.items, version=EXCLUDED.version, updated_at=CURRENT_TIMESTAMP`
+	updateOrderQuery = `
+	UPDATE orders
+	SET customer_id = $2, items = $3, price = $4, payment_id = $5, deadline = $6, kind = $7, version = $8, discounts = $9, currency = $10, returns = $11, updated_at = CURRENT_TIMESTAMP
+	WHERE order_id = $1`
+	getOrderQuery = `
+	SELECT order_id, customer_id, items, price, currency, payment_id, deadline, discounts, returns, kind, version, created_at, updated_at
+	FROM orders
+	WHERE order_id = $1`
+	listCustomerOrdersQuery = `
+	SELECT order_id, customer_id, items, price, currency, payment_id, deadline, discounts, returns, kind, version, created_at, updated_at
+	FROM orders
+	WHERE customer_id = $1
+		AND ($2::text[] IS NULL OR kind::text = ANY($2))
+		AND ($3::timestamp IS NULL OR created_at >= $3)
+		AND ($4::timestamp IS NULL OR created_at < $4)
+		AND ($5::timestamp IS NULL OR COALESCE(updated_at, created_at) >= $5)
+		AND ($6::timestamp IS NULL OR COALESCE(updated_at, created_at) < $6)
+		AND ($7::timestamp IS NULL OR (created_at, order_id) < ($7, $8::uuid))
+	ORDER BY created_at DESC, order_id DESC
+	LIMIT $9`
+	findExpiredOrdersQuery = `
+	SELECT order_id
+	FROM orders
+	WHERE kind IN ('pending', 'paid', 'stocked') AND deadline < $1
+	ORDER BY deadline ASC LIMIT $2`
 )
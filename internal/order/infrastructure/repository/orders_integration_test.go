@@ -7,6 +7,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
@@ -17,6 +18,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/money"
 	"github.com/moeryomenko/saga/schema"
 )
 
@@ -38,6 +41,7 @@ func TestIntegration_Repository(t *testing.T) {
 		getEvents           func(orderID, customerID uuid.UUID) []domain.Event
 		expectedOrderState  func(order domain.Order)
 		expectedEvent       func(orderID, customerID uuid.UUID) []schema.OrderEvent
+		expectedErr         error
 	}{
 		`success order completion`: {
 			orderID:    genUUID(t),
@@ -45,7 +49,7 @@ func TestIntegration_Repository(t *testing.T) {
 			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
 				return []domain.Event{
 					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
-					domain.AddItem{Item: `test`},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
 					domain.Process{},
 					domain.ConfirmStock{},
 					domain.ConfirmPayment{},
@@ -62,15 +66,15 @@ func TestIntegration_Repository(t *testing.T) {
 						Event:      schema.Event{Type: schema.NewOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 					{
 						Event:      schema.Event{Type: schema.CompleteOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 				}
 			},
@@ -81,7 +85,7 @@ func TestIntegration_Repository(t *testing.T) {
 			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
 				return []domain.Event{
 					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
-					domain.AddItem{Item: `test`},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
 					domain.Process{},
 					domain.ConfirmPayment{},
 					domain.ConfirmStock{},
@@ -98,15 +102,15 @@ func TestIntegration_Repository(t *testing.T) {
 						Event:      schema.Event{Type: schema.NewOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 					{
 						Event:      schema.Event{Type: schema.CompleteOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 				}
 			},
@@ -117,8 +121,8 @@ func TestIntegration_Repository(t *testing.T) {
 			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
 				return []domain.Event{
 					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
-					domain.AddItem{Item: `test`},
-					domain.AddItem{Item: `test1`},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.AddItem{Item: domain.Item{SKU: `test1`, Quantity: 1}},
 					domain.Process{},
 					domain.ConfirmPayment{},
 					domain.ConfirmStock{},
@@ -135,15 +139,53 @@ func TestIntegration_Repository(t *testing.T) {
 						Event:      schema.Event{Type: schema.NewOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test,test1`,
-						Price:      decimal.NewFromFloat32(19.98),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}, {SKU: `test1`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(19.98), `USD`),
 					},
 					{
 						Event:      schema.Event{Type: schema.CompleteOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test,test1`,
-						Price:      decimal.NewFromFloat32(19.98),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}, {SKU: `test1`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(19.98), `USD`),
+					},
+				}
+			},
+		},
+		`success order completion (with same item twice)`: {
+			orderID:    genUUID(t),
+			customerID: genUUID(t),
+			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
+				return []domain.Event{
+					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 2}},
+					domain.RemoveItem{SKU: `test`, Quantity: 1},
+					domain.Process{},
+					domain.ConfirmPayment{},
+					domain.ConfirmStock{},
+				}
+			},
+			expectedOrderState: func(order domain.Order) {
+				if _, ok := order.(domain.CompletedOrder); !ok {
+					require.FailNow(t, `expected order completed`)
+				}
+			},
+			expectedEvent: func(orderID, customerID uuid.UUID) []schema.OrderEvent {
+				return []schema.OrderEvent{
+					{
+						Event:      schema.Event{Type: schema.NewOrder},
+						OrderID:    orderID,
+						CustomerID: customerID,
+						Items:      schema.Items{{SKU: `test`, Quantity: 2}},
+						Price:      money.New(decimal.NewFromFloat32(19.98), `USD`),
+					},
+					{
+						Event:      schema.Event{Type: schema.CompleteOrder},
+						OrderID:    orderID,
+						CustomerID: customerID,
+						Items:      schema.Items{{SKU: `test`, Quantity: 2}},
+						Price:      money.New(decimal.NewFromFloat32(19.98), `USD`),
 					},
 				}
 			},
@@ -154,9 +196,9 @@ func TestIntegration_Repository(t *testing.T) {
 			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
 				return []domain.Event{
 					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
-					domain.AddItem{Item: `test`},
-					domain.AddItem{Item: `test1`},
-					domain.RemoveItem{Item: `test1`},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.AddItem{Item: domain.Item{SKU: `test1`, Quantity: 1}},
+					domain.RemoveItem{SKU: `test1`, Quantity: 1},
 					domain.Process{},
 					domain.ConfirmPayment{},
 					domain.ConfirmStock{},
@@ -173,29 +215,90 @@ func TestIntegration_Repository(t *testing.T) {
 						Event:      schema.Event{Type: schema.NewOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 					{
 						Event:      schema.Event{Type: schema.CompleteOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
+					},
+				}
+			},
+		},
+		`cancel order by timeout`: {
+			orderID:    genUUID(t),
+			customerID: genUUID(t),
+			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
+				return []domain.Event{
+					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.Process{Deadline: time.Now().Add(-time.Minute)},
+					domain.ConfirmPayment{},
+					domain.Timeout{Now: time.Now()},
+				}
+			},
+			expectedOrderState: func(order domain.Order) {
+				if _, ok := order.(domain.CanceledOrder); !ok {
+					require.FailNow(t, `expected order canceled`)
+				}
+			},
+			expectedEvent: func(orderID, customerID uuid.UUID) []schema.OrderEvent {
+				return []schema.OrderEvent{
+					{
+						Event:      schema.Event{Type: schema.NewOrder},
+						OrderID:    orderID,
+						CustomerID: customerID,
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
+					},
+					{
+						Event:      schema.Event{Type: schema.CancelOrder},
+						OrderID:    orderID,
+						CustomerID: customerID,
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 				}
 			},
 		},
+		`timeout before deadline`: {
+			orderID:    genUUID(t),
+			customerID: genUUID(t),
+			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
+				return []domain.Event{
+					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.Process{Deadline: time.Now().Add(time.Minute)},
+					domain.Timeout{Now: time.Now()},
+				}
+			},
+			expectedErr: domain.ErrOrderNotExpired,
+		},
+		`process order with unknown product`: {
+			orderID:    genUUID(t),
+			customerID: genUUID(t),
+			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
+				return []domain.Event{
+					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
+					domain.AddItem{Item: domain.Item{SKU: `unknown`, Quantity: 1}},
+					domain.Process{},
+				}
+			},
+			expectedErr: domain.ErrUnknownProduct,
+		},
 		`remove all items from order`: {
 			orderID:    genUUID(t),
 			customerID: genUUID(t),
 			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
 				return []domain.Event{
 					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
-					domain.AddItem{Item: `test`},
-					domain.AddItem{Item: `test1`},
-					domain.RemoveItem{Item: `test1`},
-					domain.RemoveItem{Item: `test`},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.AddItem{Item: domain.Item{SKU: `test1`, Quantity: 1}},
+					domain.RemoveItem{SKU: `test1`, Quantity: 1},
+					domain.RemoveItem{SKU: `test`, Quantity: 1},
 				}
 			},
 			expectedOrderState: func(order domain.Order) {
@@ -210,7 +313,7 @@ func TestIntegration_Repository(t *testing.T) {
 			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
 				return []domain.Event{
 					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
-					domain.AddItem{Item: `test`},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
 					domain.Process{},
 					domain.ConfirmPayment{},
 					domain.RejectStock{},
@@ -227,15 +330,15 @@ func TestIntegration_Repository(t *testing.T) {
 						Event:      schema.Event{Type: schema.NewOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 					{
 						Event:      schema.Event{Type: schema.CancelOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 				}
 			},
@@ -246,7 +349,7 @@ func TestIntegration_Repository(t *testing.T) {
 			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
 				return []domain.Event{
 					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
-					domain.AddItem{Item: `test`},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
 					domain.Process{},
 					domain.RejectStock{},
 					domain.ConfirmPayment{},
@@ -263,15 +366,15 @@ func TestIntegration_Repository(t *testing.T) {
 						Event:      schema.Event{Type: schema.NewOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 					{
 						Event:      schema.Event{Type: schema.CancelOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 				}
 			},
@@ -282,7 +385,7 @@ func TestIntegration_Repository(t *testing.T) {
 			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
 				return []domain.Event{
 					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
-					domain.AddItem{Item: `test`},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
 					domain.Process{},
 					domain.ConfirmStock{},
 					domain.RejectPayment{},
@@ -299,15 +402,15 @@ func TestIntegration_Repository(t *testing.T) {
 						Event:      schema.Event{Type: schema.NewOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 					{
 						Event:      schema.Event{Type: schema.CancelOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 				}
 			},
@@ -318,7 +421,7 @@ func TestIntegration_Repository(t *testing.T) {
 			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
 				return []domain.Event{
 					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
-					domain.AddItem{Item: `test`},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
 					domain.Process{},
 					domain.RejectPayment{},
 					domain.ConfirmStock{},
@@ -335,19 +438,155 @@ func TestIntegration_Repository(t *testing.T) {
 						Event:      schema.Event{Type: schema.NewOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
+					},
+					{
+						Event:      schema.Event{Type: schema.CancelOrder},
+						OrderID:    orderID,
+						CustomerID: customerID,
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
+					},
+				}
+			},
+		},
+		`cancel active order by customer`: {
+			orderID:    genUUID(t),
+			customerID: genUUID(t),
+			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
+				return []domain.Event{
+					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.Cancel{},
+				}
+			},
+			expectedOrderState: func(order domain.Order) {
+				if _, ok := order.(domain.CanceledOrder); !ok {
+					require.FailNow(t, `expected order canceled`)
+				}
+			},
+			expectedEvent: func(_, _ uuid.UUID) []schema.OrderEvent {
+				return nil
+			},
+		},
+		`cancel pending order by customer`: {
+			orderID:    genUUID(t),
+			customerID: genUUID(t),
+			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
+				return []domain.Event{
+					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.Process{},
+					domain.Cancel{},
+					domain.ConfirmPayment{},
+				}
+			},
+			expectedOrderState: func(order domain.Order) {
+				if _, ok := order.(domain.CanceledOrder); !ok {
+					require.FailNow(t, `expected order canceled`)
+				}
+			},
+			expectedEvent: func(orderID, customerID uuid.UUID) []schema.OrderEvent {
+				return []schema.OrderEvent{
+					{
+						Event:      schema.Event{Type: schema.NewOrder},
+						OrderID:    orderID,
+						CustomerID: customerID,
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 					{
 						Event:      schema.Event{Type: schema.CancelOrder},
 						OrderID:    orderID,
 						CustomerID: customerID,
-						Items:      `test`,
-						Price:      decimal.NewFromFloat32(9.99),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
+					},
+				}
+			},
+		},
+		`partial return of completed order`: {
+			orderID:    genUUID(t),
+			customerID: genUUID(t),
+			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
+				returnID := uuid.NewSHA1(orderID, []byte(`return`))
+				return []domain.Event{
+					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.AddItem{Item: domain.Item{SKU: `test1`, Quantity: 1}},
+					domain.Process{},
+					domain.ConfirmStock{},
+					domain.ConfirmPayment{},
+					domain.RequestReturn{ReturnID: returnID, Items: []domain.Item{{SKU: `test`, Quantity: 1}}},
+					domain.ConfirmRefund{ReturnID: returnID},
+					domain.ConfirmRestock{ReturnID: returnID},
+				}
+			},
+			expectedOrderState: func(order domain.Order) {
+				returned, ok := order.(domain.PartiallyReturnedOrder)
+				if !ok {
+					require.FailNow(t, `expected order partially returned`)
+				}
+				require.Len(t, returned.Returns, 1)
+				require.True(t, returned.Returns[0].Refunded)
+				require.True(t, returned.Returns[0].Restocked)
+			},
+			expectedEvent: func(orderID, customerID uuid.UUID) []schema.OrderEvent {
+				return []schema.OrderEvent{
+					{
+						Event:      schema.Event{Type: schema.NewOrder},
+						OrderID:    orderID,
+						CustomerID: customerID,
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}, {SKU: `test1`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(19.98), `USD`),
+					},
+					{
+						Event:      schema.Event{Type: schema.CompleteOrder},
+						OrderID:    orderID,
+						CustomerID: customerID,
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}, {SKU: `test1`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(19.98), `USD`),
+					},
+					{
+						Event:      schema.Event{Type: schema.ReturnOrder},
+						OrderID:    orderID,
+						CustomerID: customerID,
+						ReturnID:   uuid.NewSHA1(orderID, []byte(`return`)),
+						Items:      schema.Items{{SKU: `test`, Quantity: 1}},
+						Price:      money.New(decimal.NewFromFloat32(9.99), `USD`),
 					},
 				}
 			},
 		},
+		`return not completed order`: {
+			orderID:    genUUID(t),
+			customerID: genUUID(t),
+			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
+				return []domain.Event{
+					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.Process{},
+					domain.RequestReturn{ReturnID: genUUID(t)},
+				}
+			},
+			expectedErr: domain.ErrReturnOrder,
+		},
+		`cancel completed order by customer`: {
+			orderID:    genUUID(t),
+			customerID: genUUID(t),
+			getEvents: func(orderID, customerID uuid.UUID) []domain.Event {
+				return []domain.Event{
+					domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
+					domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+					domain.Process{},
+					domain.ConfirmStock{},
+					domain.ConfirmPayment{},
+					domain.Cancel{},
+				}
+			},
+			expectedErr: domain.ErrCustomerCancel,
+		},
 	}
 
 	for name, tc := range testcase {
@@ -357,7 +596,11 @@ func TestIntegration_Repository(t *testing.T) {
 			err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) (err error) {
 				_, err = tx.Exec(ctx, `TRUNCATE event_log`)
 				require.NoError(t, err)
-				_, err = tx.Exec(ctx, `UPDATE event_offset SET offset_acked = 0`)
+				_, err = tx.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
+				require.NoError(t, err)
+				_, err = tx.Exec(ctx, `
+				INSERT INTO products(sku, name, price) VALUES ('test', 'test', 9.99), ('test1', 'test1', 9.99)
+				ON CONFLICT (sku) DO UPDATE SET price = EXCLUDED.price`)
 				require.NoError(t, err)
 				return nil
 			})
@@ -365,20 +608,145 @@ func TestIntegration_Repository(t *testing.T) {
 
 			var order domain.Order
 			for _, event := range tc.getEvents(tc.orderID, tc.customerID) {
-				order, err = PersistOrder(context.Background(), tc.orderID, event)
+				order, _, err = PersistOrder(context.Background(), tc.orderID, domain.AnyVersion, event)
+				if tc.expectedErr != nil && err != nil {
+					break
+				}
 				require.NoError(t, err)
 			}
+			if tc.expectedErr != nil {
+				require.True(t, errors.Is(err, tc.expectedErr))
+				return
+			}
 			tc.expectedOrderState(order)
 
 			if tc.expectedEvent != nil {
 				for _, expectedEvent := range tc.expectedEvent(tc.orderID, tc.customerID) {
-					id, event, err := GetEvent(ctx)
+					id, event, err := getEvent(ctx)
 					require.NoError(t, err)
 					require.Equal(t, expectedEvent, event)
-					err = Ack(ctx, id)
+					err = EventLog().Ack(ctx, id)
 					require.NoError(t, err)
 				}
+				_, _, err = getEvent(ctx)
+				require.ErrorIs(t, err, ErrNoEvents)
 			}
 		})
 	}
 }
+
+func TestIntegration_ListCustomerOrders(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders pool_max_conns=1`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	customerID := genUUID(t)
+	var orderIDs []uuid.UUID
+	for i := 0; i < 3; i++ {
+		orderID := genUUID(t)
+		_, _, err = PersistOrder(ctx, orderID, domain.AnyVersion, domain.CreateOrder{OrderID: orderID, CustomerID: customerID})
+		require.NoError(t, err)
+		orderIDs = append(orderIDs, orderID)
+	}
+	_, _, err = PersistOrder(ctx, orderIDs[0], domain.AnyVersion, domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}})
+	require.NoError(t, err)
+
+	view, err := FindOrder(ctx, orderIDs[0])
+	require.NoError(t, err)
+	require.IsType(t, domain.ActiveOrder{}, view.Order)
+	require.False(t, view.UpdatedAt.IsZero())
+
+	_, err = FindOrder(ctx, genUUID(t))
+	require.True(t, errors.Is(err, domain.ErrOrderNotFound))
+
+	// newest orders go first.
+	page, err := ListCustomerOrders(ctx, customerID, domain.OrdersFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Orders, 2)
+	require.Equal(t, orderIDs[2], page.Orders[0].Order.GetID())
+	require.Equal(t, orderIDs[1], page.Orders[1].Order.GetID())
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = ListCustomerOrders(ctx, customerID, domain.OrdersFilter{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Orders, 1)
+	require.Equal(t, orderIDs[0], page.Orders[0].Order.GetID())
+	require.Empty(t, page.NextCursor)
+
+	page, err = ListCustomerOrders(ctx, customerID, domain.OrdersFilter{Kinds: []string{active}})
+	require.NoError(t, err)
+	require.Len(t, page.Orders, 1)
+	require.Equal(t, orderIDs[0], page.Orders[0].Order.GetID())
+
+	page, err = ListCustomerOrders(ctx, customerID, domain.OrdersFilter{CreatedFrom: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	require.Empty(t, page.Orders)
+
+	_, err = ListCustomerOrders(ctx, customerID, domain.OrdersFilter{Cursor: `invalid`})
+	require.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
+
+func TestIntegration_OrderHistory(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders pool_max_conns=1`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	orderID, customerID := genUUID(t), genUUID(t)
+	_, _, err = PersistOrder(ctx, orderID, domain.AnyVersion, domain.CreateOrder{OrderID: orderID, CustomerID: customerID})
+	require.NoError(t, err)
+	for i := 0; i < snapshotInterval; i++ {
+		_, _, err = PersistOrder(ctx, orderID, domain.AnyVersion, domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}})
+		require.NoError(t, err)
+	}
+	order, version, err := PersistOrder(ctx, orderID, domain.AnyVersion, domain.RemoveItem{SKU: `test`, Quantity: 1})
+	require.NoError(t, err)
+	require.Equal(t, snapshotInterval+2, version)
+
+	view, err := FindOrder(ctx, orderID)
+	require.NoError(t, err)
+	require.Equal(t, version, view.Version)
+
+	// stale version doesn't change order.
+	_, _, err = PersistOrder(ctx, orderID, version-1, domain.Cancel{})
+	require.ErrorAs(t, err, &domain.VersionConflict{})
+	require.Equal(t, domain.VersionConflict{Expected: version - 1, Current: version}, err)
+
+	var snapshots int
+	err = pool.QueryRow(ctx, `SELECT count(*) FROM order_snapshots WHERE order_id = $1`, orderID).Scan(&snapshots)
+	require.NoError(t, err)
+	require.Equal(t, 1, snapshots)
+
+	// the latest state is rebuilt from snapshot and events after it.
+	latest, err := LoadOrder(ctx, orderID, 0)
+	require.NoError(t, err)
+	require.Equal(t, order, latest)
+
+	first, err := LoadOrder(ctx, orderID, 1)
+	require.NoError(t, err)
+	require.Equal(t, domain.EmptyOrder{ID: orderID, CustomerID: customerID}, first)
+
+	middle, err := LoadOrder(ctx, orderID, 5)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Item{{SKU: `test`, Quantity: 4}}, middle.(domain.ActiveOrder).Items)
+
+	_, err = LoadOrder(ctx, orderID, snapshotInterval+3)
+	require.True(t, errors.Is(err, domain.ErrOrderNotFound))
+
+	events, err := OrderEvents(ctx, orderID)
+	require.NoError(t, err)
+	require.Len(t, events, snapshotInterval+2)
+	require.Equal(t, orderCreated, events[0].Type)
+	require.Equal(t, itemRemoved, events[len(events)-1].Type)
+}
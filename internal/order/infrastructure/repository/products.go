@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/money"
+)
+
+// catalogPrices returns price list of order items read by querier of order transaction,
+// so pricing of order doesn't need another connection.
+func catalogPrices(ctx context.Context, q querier, order domain.Order) (domain.PriceList, error) {
+	prices := domain.PriceList{}
+	active, ok := order.(domain.ActiveOrder)
+	if !ok {
+		return prices, nil
+	}
+
+	skus := make([]string, 0, len(active.Items))
+	for _, item := range active.Items {
+		skus = append(skus, item.SKU)
+	}
+
+	rows, err := q.Query(ctx, findPricesQuery, skus)
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find prices`)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			sku      string
+			price    decimal.Decimal
+			currency string
+		)
+		err = rows.Scan(&sku, &price, &currency)
+		if err != nil {
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan price`)
+		}
+		prices[sku] = money.New(price, money.Currency(currency))
+	}
+	if rows.Err() != nil {
+		return nil, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't find prices`)
+	}
+	return prices, nil
+}
+
+func CreateProduct(ctx context.Context, product domain.Product) (domain.Product, error) {
+	_, err := pool.Exec(ctx, insertProductQuery, product.SKU, product.Name, product.Price.Amount, string(product.Price.Currency))
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return product, nil
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		return domain.Product{}, errors.MarkAndWrapError(domain.ErrProductExists, domain.ErrDomain, `couldn't create product`)
+	default:
+		return domain.Product{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't create product`)
+	}
+}
+
+func UpdateProduct(ctx context.Context, product domain.Product) (domain.Product, error) {
+	tag, err := pool.Exec(ctx, updateProductQuery, product.SKU, product.Name, product.Price.Amount, string(product.Price.Currency))
+	if err != nil {
+		return domain.Product{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't update product`)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.Product{}, errors.MarkAndWrapError(domain.ErrUnknownProduct, domain.ErrDomain, `couldn't update product`)
+	}
+	return product, nil
+}
+
+func DeleteProduct(ctx context.Context, sku string) error {
+	tag, err := pool.Exec(ctx, deleteProductQuery, sku)
+	if err != nil {
+		return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't delete product`)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.MarkAndWrapError(domain.ErrUnknownProduct, domain.ErrDomain, `couldn't delete product`)
+	}
+	return nil
+}
+
+func FindProduct(ctx context.Context, sku string) (domain.Product, error) {
+	product := domain.Product{SKU: sku}
+	var (
+		price    decimal.Decimal
+		currency string
+	)
+	err := pool.QueryRow(ctx, findProductQuery, sku).Scan(&product.Name, &price, &currency)
+	switch err {
+	case nil:
+		product.Price = money.New(price, money.Currency(currency))
+		return product, nil
+	case pgx.ErrNoRows:
+		return domain.Product{}, errors.MarkAndWrapError(domain.ErrUnknownProduct, domain.ErrDomain, sku)
+	default:
+		return domain.Product{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find product`)
+	}
+}
+
+func ListProducts(ctx context.Context) ([]domain.Product, error) {
+	rows, err := pool.Query(ctx, listProductsQuery)
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't list products`)
+	}
+	defer rows.Close()
+
+	products := []domain.Product{}
+	for rows.Next() {
+		var (
+			product  domain.Product
+			price    decimal.Decimal
+			currency string
+		)
+		err = rows.Scan(&product.SKU, &product.Name, &price, &currency)
+		if err != nil {
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan product`)
+		}
+		product.Price = money.New(price, money.Currency(currency))
+		products = append(products, product)
+	}
+	if rows.Err() != nil {
+		return nil, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't list products`)
+	}
+	return products, nil
+}
+
+// uniqueViolation is postgres error code of unique constraint violation.
+const uniqueViolation = `23505`
+
+const (
+	insertProductQuery = `INSERT INTO products(sku, name, price, currency) VALUES ($1, $2, $3, $4)`
+	updateProductQuery = `UPDATE products SET name = $2, price = $3, currency = $4, updated_at = CURRENT_TIMESTAMP WHERE sku = $1`
+	deleteProductQuery = `DELETE FROM products WHERE sku = $1`
+	findProductQuery   = `SELECT name, price, currency FROM products WHERE sku = $1`
+	listProductsQuery  = `SELECT sku, name, price, currency FROM products ORDER BY sku`
+	findPricesQuery    = `SELECT sku, price, currency FROM products WHERE sku = ANY($1)`
+)
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/money"
+)
+
+// customerPromotions is promotions port of discount codes with their usages by customer.
+type customerPromotions map[string]customerPromotion
+
+type customerPromotion struct {
+	promotion domain.Promotion
+	usages    int
+}
+
+func (p customerPromotions) Promotion(code string, _ uuid.UUID) (domain.Promotion, int, error) {
+	promotion, ok := p[code]
+	if !ok {
+		return domain.Promotion{}, 0, domain.ErrUnknownPromotion
+	}
+	return promotion.promotion, promotion.usages, nil
+}
+
+// lockPromotions reads promotions of codes and their usages by customer of order in order transaction.
+// Promotions are locked until transaction ends, so concurrent orders can't exceed usage limit.
+func lockPromotions(ctx context.Context, q querier, order domain.Order, codes []string) (customerPromotions, error) {
+	promotions := customerPromotions{}
+	if len(codes) == 0 || order == nil {
+		return promotions, nil
+	}
+
+	rows, err := q.Query(ctx, lockPromotionsQuery, codes)
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't lock promotions`)
+	}
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			rows.Close()
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan promotion`)
+		}
+		promotions[promotion.Code] = customerPromotion{promotion: promotion}
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't lock promotions`)
+	}
+
+	// usages are counted after lock, so they include usages of committed concurrent orders.
+	rows, err = q.Query(ctx, countUsagesQuery, codes, order.GetCustomerID().String())
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't count promotion usages`)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			code   string
+			usages int
+		)
+		err = rows.Scan(&code, &usages)
+		if err != nil {
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan promotion usages`)
+		}
+		if promotion, ok := promotions[code]; ok {
+			promotion.usages = usages
+			promotions[code] = promotion
+		}
+	}
+	if rows.Err() != nil {
+		return nil, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't count promotion usages`)
+	}
+	return promotions, nil
+}
+
+func CreatePromotion(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error) {
+	_, err := pool.Exec(ctx, insertPromotionQuery,
+		promotion.Code, string(promotion.Kind), promotion.Value, currencyToModel(promotion.Currency), timestampToModel(promotion.ExpiresAt), promotion.UsageLimit)
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return promotion, nil
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		return domain.Promotion{}, errors.MarkAndWrapError(domain.ErrPromotionExists, domain.ErrDomain, `couldn't create promotion`)
+	default:
+		return domain.Promotion{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't create promotion`)
+	}
+}
+
+func DeletePromotion(ctx context.Context, code string) error {
+	tag, err := pool.Exec(ctx, deletePromotionQuery, code)
+	if err != nil {
+		return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't delete promotion`)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.MarkAndWrapError(domain.ErrUnknownPromotion, domain.ErrDomain, `couldn't delete promotion`)
+	}
+	return nil
+}
+
+func FindPromotion(ctx context.Context, code string) (domain.Promotion, error) {
+	promotion, err := scanPromotion(pool.QueryRow(ctx, findPromotionQuery, code))
+	switch err {
+	case nil:
+		return promotion, nil
+	case pgx.ErrNoRows:
+		return domain.Promotion{}, errors.MarkAndWrapError(domain.ErrUnknownPromotion, domain.ErrDomain, code)
+	default:
+		return domain.Promotion{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find promotion`)
+	}
+}
+
+func ListPromotions(ctx context.Context) ([]domain.Promotion, error) {
+	rows, err := pool.Query(ctx, listPromotionsQuery)
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't list promotions`)
+	}
+	defer rows.Close()
+
+	promotions := []domain.Promotion{}
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan promotion`)
+		}
+		promotions = append(promotions, promotion)
+	}
+	if rows.Err() != nil {
+		return nil, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't list promotions`)
+	}
+	return promotions, nil
+}
+
+func scanPromotion(row pgx.Row) (domain.Promotion, error) {
+	var (
+		promotion domain.Promotion
+		kind      string
+		currency  *string
+		expiresAt pgtype.Timestamp
+	)
+	err := row.Scan(&promotion.Code, &kind, &promotion.Value, &currency, &expiresAt, &promotion.UsageLimit)
+	if err != nil {
+		return domain.Promotion{}, err
+	}
+	promotion.Kind = domain.DiscountKind(kind)
+	if currency != nil {
+		promotion.Currency = money.Currency(*currency)
+	}
+	if expiresAt.Status == pgtype.Present {
+		promotion.ExpiresAt = expiresAt.Time
+	}
+	return promotion, nil
+}
+
+// saveUsages records discount codes used by processed order and
+// releases them if order was canceled, so codes can be used again.
+func saveUsages(ctx context.Context, tx pgx.Tx, prev, order domain.Order) error {
+	switch order := order.(type) {
+	case domain.PendingOrder:
+		if _, ok := prev.(domain.ActiveOrder); !ok {
+			return nil
+		}
+		for _, discount := range order.Discounts {
+			_, err := tx.Exec(ctx, insertUsageQuery, discount.Code, order.CustomerID, order.ID)
+			if err != nil {
+				return err
+			}
+		}
+	case domain.CanceledOrder:
+		if len(order.Discounts) == 0 {
+			return nil
+		}
+		_, err := tx.Exec(ctx, deleteUsagesQuery, order.ID)
+		return err
+	}
+	return nil
+}
+
+const (
+	insertPromotionQuery = `INSERT INTO promotions(code, kind, value, currency, expires_at, usage_limit) VALUES ($1, $2, $3, $4, $5, $6)`
+	deletePromotionQuery = `DELETE FROM promotions WHERE code = $1`
+	findPromotionQuery   = `SELECT code, kind, value, currency, expires_at, usage_limit FROM promotions WHERE code = $1`
+	listPromotionsQuery  = `SELECT code, kind, value, currency, expires_at, usage_limit FROM promotions ORDER BY code`
+	lockPromotionsQuery  = `SELECT code, kind, value, currency, expires_at, usage_limit FROM promotions WHERE code = ANY($1) ORDER BY code FOR UPDATE`
+	countUsagesQuery     = `SELECT code, count(*) FROM promotion_usages WHERE code = ANY($1) AND customer_id = $2 GROUP BY code`
+	insertUsageQuery     = `INSERT INTO promotion_usages(code, customer_id, order_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	deleteUsagesQuery    = `DELETE FROM promotion_usages WHERE order_id = $1`
+)
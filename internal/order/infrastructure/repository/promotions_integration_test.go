@@ -0,0 +1,169 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+func TestIntegration_Promotions(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders pool_max_conns=1`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	_, err = pool.Exec(ctx, `
+	INSERT INTO products(sku, name, price) VALUES ('test', 'test', 10)
+	ON CONFLICT (sku) DO UPDATE SET price = EXCLUDED.price`)
+	require.NoError(t, err)
+
+	code := genUUID(t).String()
+	promotion, err := domain.NewPromotion(code, domain.PercentageDiscount, decimal.NewFromInt(10), ``, time.Time{}, 1)
+	require.NoError(t, err)
+	_, err = CreatePromotion(ctx, promotion)
+	require.NoError(t, err)
+	defer func() {
+		_ = DeletePromotion(ctx, code)
+	}()
+
+	_, err = CreatePromotion(ctx, promotion)
+	require.True(t, errors.Is(err, domain.ErrPromotionExists))
+
+	found, err := FindPromotion(ctx, code)
+	require.NoError(t, err)
+	require.Equal(t, code, found.Code)
+	require.True(t, promotion.Value.Equal(found.Value))
+
+	customerID := genUUID(t)
+	persist := func(events ...domain.Event) (domain.Order, error) {
+		orderID := genUUID(t)
+		events = append([]domain.Event{
+			domain.CreateOrder{OrderID: orderID, CustomerID: customerID},
+			domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 2}},
+		}, events...)
+		var order domain.Order
+		for _, event := range events {
+			order, _, err = PersistOrder(ctx, orderID, domain.AnyVersion, event)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return order, nil
+	}
+	processEvent := domain.Process{
+		Codes:    []string{code},
+		Now:      time.Now(),
+		Deadline: time.Now().Add(time.Minute),
+	}
+
+	order, err := persist(processEvent)
+	require.NoError(t, err)
+	pending := order.(domain.PendingOrder)
+	require.Equal(t, `18.00 USD`, pending.Price.String())
+	require.Len(t, pending.Discounts, 1)
+	require.Equal(t, `2.00 USD`, pending.Discounts[0].Amount.String())
+
+	// order is rebuilt with discounts applied on processing.
+	latest, err := LoadOrder(ctx, pending.ID, 0)
+	require.NoError(t, err)
+	require.Equal(t, `18.00 USD`, latest.(domain.PendingOrder).Price.String())
+
+	// usage limit is reached by the first order.
+	_, err = persist(processEvent)
+	require.True(t, errors.Is(err, domain.ErrPromotionExhausted))
+
+	// cancellation releases discount code.
+	_, _, err = PersistOrder(ctx, pending.ID, domain.AnyVersion, domain.Cancel{})
+	require.NoError(t, err)
+	_, err = persist(processEvent)
+	require.NoError(t, err)
+
+	// fixed discount is applicable only to orders in its currency.
+	fixed, err := domain.NewPromotion(genUUID(t).String(), domain.FixedDiscount, decimal.NewFromInt(5), `EUR`, time.Time{}, 0)
+	require.NoError(t, err)
+	_, err = CreatePromotion(ctx, fixed)
+	require.NoError(t, err)
+	defer func() {
+		_ = DeletePromotion(ctx, fixed.Code)
+	}()
+	processEvent.Codes = []string{fixed.Code}
+	_, err = persist(processEvent)
+	require.True(t, errors.Is(err, domain.ErrPromotionCurrency))
+}
+
+func TestIntegration_ConcurrentPromotionUsage(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders pool_max_conns=2`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	_, err = pool.Exec(ctx, `
+	INSERT INTO products(sku, name, price) VALUES ('test', 'test', 10)
+	ON CONFLICT (sku) DO UPDATE SET price = EXCLUDED.price`)
+	require.NoError(t, err)
+
+	promotion, err := domain.NewPromotion(genUUID(t).String(), domain.PercentageDiscount, decimal.NewFromInt(10), ``, time.Time{}, 1)
+	require.NoError(t, err)
+	_, err = CreatePromotion(ctx, promotion)
+	require.NoError(t, err)
+	defer func() {
+		_ = DeletePromotion(ctx, promotion.Code)
+	}()
+
+	customerID := genUUID(t)
+	orders := make([]uuid.UUID, 2)
+	for i := range orders {
+		orders[i] = genUUID(t)
+		for _, event := range []domain.Event{
+			domain.CreateOrder{OrderID: orders[i], CustomerID: customerID},
+			domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+		} {
+			_, _, err = PersistOrder(ctx, orders[i], domain.AnyVersion, event)
+			require.NoError(t, err)
+		}
+	}
+
+	// orders of customer are processed concurrently, only one of them gets discount.
+	errs := make(chan error, len(orders))
+	for _, orderID := range orders {
+		go func(orderID uuid.UUID) {
+			_, _, err := PersistOrder(ctx, orderID, domain.AnyVersion, domain.Process{
+				Codes:    []string{promotion.Code},
+				Now:      time.Now(),
+				Deadline: time.Now().Add(time.Minute),
+			})
+			errs <- err
+		}(orderID)
+	}
+
+	var exhausted int
+	for range orders {
+		err := <-errs
+		if err != nil {
+			require.True(t, errors.Is(err, domain.ErrPromotionExhausted))
+			exhausted++
+		}
+	}
+	require.Equal(t, 1, exhausted)
+}
@@ -0,0 +1,72 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/pkg/outbox"
+)
+
+func TestIntegration_EventRetention(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	_, err = pool.Exec(ctx, `TRUNCATE event_log, event_log_archive`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
+	require.NoError(t, err)
+
+	var ids []int
+	for i := 0; i < 5; i++ {
+		var id int
+		err = pool.QueryRow(ctx, `
+		INSERT INTO event_log(payload, event_kind, created_at)
+		VALUES ('{"type": "test"}', 'test', CURRENT_TIMESTAMP - INTERVAL '10 days') RETURNING id`).Scan(&id)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	// the last event isn't published, so it's kept regardless of age.
+	require.NoError(t, EventLog().Ack(ctx, ids[3]))
+
+	// events are younger than retention.
+	removed, err := EventLog().Purge(ctx, outbox.ArchiveRetention, time.Now().Add(-30*24*time.Hour), 3)
+	require.NoError(t, err)
+	require.Zero(t, removed)
+
+	// published events are archived in batches.
+	removed, err = EventLog().Purge(ctx, outbox.ArchiveRetention, time.Now().Add(-7*24*time.Hour), 3)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, removed)
+
+	removed, err = EventLog().Purge(ctx, outbox.DeleteRetention, time.Now(), 3)
+	require.NoError(t, err)
+	require.Zero(t, removed)
+
+	var archived, kept int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT count(*) FROM event_log_archive`).Scan(&archived))
+	require.NoError(t, pool.QueryRow(ctx, `SELECT count(*) FROM event_log`).Scan(&kept))
+	require.Equal(t, 4, archived)
+	require.Equal(t, 1, kept)
+
+	_, err = EventLog().Purge(ctx, `unknown`, time.Now(), 3)
+	require.Error(t, err)
+
+	_, err = pool.Exec(ctx, `TRUNCATE event_log, event_log_archive`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
+	require.NoError(t, err)
+}
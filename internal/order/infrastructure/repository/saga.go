@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/saga"
+	"github.com/moeryomenko/saga/schema"
+)
+
+const (
+	stockStep   = `stock`
+	paymentStep = `payment`
+	refundStep  = `refund`
+	restockStep = `restock`
+)
+
+// orderSaga reserves stock and payment of processed order concurrently,
+// both are committed when order is completed and released when it's canceled.
+var orderSaga = saga.Definition{
+	Name: `order`,
+	Steps: []saga.Step{
+		{
+			Name:         stockStep,
+			Action:       saga.MessageType(schema.NewOrder),
+			Success:      saga.MessageType(schema.StockConfirmed),
+			Failure:      saga.MessageType(schema.StockFailed),
+			Compensation: saga.MessageType(schema.CancelOrder),
+		},
+		{
+			Name:         paymentStep,
+			Action:       saga.MessageType(schema.NewOrder),
+			Success:      saga.MessageType(schema.PaymentsConfirmed),
+			Failure:      saga.MessageType(schema.PaymentsFailed),
+			Compensation: saga.MessageType(schema.CancelOrder),
+		},
+	},
+	Completion: saga.MessageType(schema.CompleteOrder),
+}
+
+// returnSaga refunds payment and restocks items returned by customer.
+var returnSaga = saga.Definition{
+	Name: `return`,
+	Steps: []saga.Step{
+		{
+			Name:    refundStep,
+			Action:  saga.MessageType(schema.ReturnOrder),
+			Success: saga.MessageType(schema.PaymentsRefunded),
+		},
+		{
+			Name:    restockStep,
+			Action:  saga.MessageType(schema.ReturnOrder),
+			Success: saga.MessageType(schema.StockReturned),
+		},
+	},
+}
+
+// orchestrate drives sagas of order by event applied to it, saga instances
+// are saved and commands are sent through outbox in transaction of order.
+func orchestrate(ctx context.Context, tx pgx.Tx, order domain.Order, event domain.Event) error {
+	store, transport := sagaStore{tx: tx}, commandTransport{tx: tx, order: order}
+	orders := saga.New(orderSaga, store, transport)
+	returns := saga.New(returnSaga, store, transport)
+	orderID := order.GetID()
+
+	var err error
+	switch event := event.(type) {
+	case domain.Process:
+		err = orders.Start(ctx, orderID, orderID.String())
+	case domain.ConfirmStock:
+		err = orders.Reply(ctx, orderID, saga.Reply{Type: saga.MessageType(schema.StockConfirmed)})
+	case domain.RejectStock:
+		err = orders.Reply(ctx, orderID, saga.Reply{Type: saga.MessageType(schema.StockFailed), Reason: event.Reason})
+	case domain.ConfirmPayment:
+		err = orders.Reply(ctx, orderID, saga.Reply{Type: saga.MessageType(schema.PaymentsConfirmed), CorrelationID: event.PaymentID.String()})
+	case domain.RejectPayment:
+		err = orders.Reply(ctx, orderID, saga.Reply{Type: saga.MessageType(schema.PaymentsFailed)})
+	case domain.Cancel:
+		err = orders.Abort(ctx, orderID, `canceled by customer`)
+	case domain.Timeout:
+		err = orders.Abort(ctx, orderID, `order expired`)
+	case domain.RequestReturn:
+		err = returns.Start(ctx, event.ReturnID, orderID.String())
+	case domain.ConfirmRefund:
+		err = returns.Reply(ctx, event.ReturnID, saga.Reply{Type: saga.MessageType(schema.PaymentsRefunded)})
+	case domain.ConfirmRestock:
+		err = returns.Reply(ctx, event.ReturnID, saga.Reply{Type: saga.MessageType(schema.StockReturned)})
+	}
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrInfrastructure):
+		return err
+	default:
+		return errors.MarkAndWrapError(err, domain.ErrDomain, `couldn't orchestrate saga`)
+	}
+}
+
+// OrderSagas returns sagas started for order, the oldest first.
+func OrderSagas(ctx context.Context, orderID uuid.UUID) ([]saga.Instance, error) {
+	rows, err := pool.Query(ctx, listSagasQuery, orderID.String())
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find order sagas`)
+	}
+	defer rows.Close()
+
+	instances := []saga.Instance{}
+	for rows.Next() {
+		var instance saga.Instance
+		err = scanSaga(rows, &instance)
+		if err != nil {
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan order saga`)
+		}
+		instances = append(instances, instance)
+	}
+	if rows.Err() != nil {
+		return nil, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't find order sagas`)
+	}
+
+	for i := range instances {
+		instances[i].Steps, err = sagaSteps(ctx, pool, instances[i].ID)
+		if err != nil {
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find saga steps`)
+		}
+	}
+	return instances, nil
+}
+
+// sagaStore persists saga instances with their steps in transaction of order.
+type sagaStore struct {
+	tx pgx.Tx
+}
+
+func (s sagaStore) Load(ctx context.Context, sagaID uuid.UUID) (saga.Instance, error) {
+	var instance saga.Instance
+	err := scanSaga(s.tx.QueryRow(ctx, findSagaQuery, sagaID), &instance)
+	switch err {
+	case nil:
+	case pgx.ErrNoRows:
+		return saga.Instance{}, nil
+	default:
+		return saga.Instance{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find saga`)
+	}
+
+	instance.Steps, err = sagaSteps(ctx, s.tx, sagaID)
+	if err != nil {
+		return saga.Instance{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find saga steps`)
+	}
+	return instance, nil
+}
+
+func (s sagaStore) Save(ctx context.Context, instance saga.Instance) error {
+	_, err := s.tx.Exec(ctx, upsertSagaQuery, instance.ID, instance.Saga, instance.CorrelationID, string(instance.State))
+	if err != nil {
+		return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't save saga`)
+	}
+
+	for position, step := range instance.Steps {
+		_, err = s.tx.Exec(ctx, upsertSagaStepQuery, instance.ID, position, step.Name, string(step.Status),
+			step.Attempts, textToModel(step.LastError), textToModel(step.CorrelationID))
+		if err != nil {
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't save saga step`)
+		}
+	}
+	return nil
+}
+
+func sagaSteps(ctx context.Context, q querier, sagaID uuid.UUID) ([]saga.StepState, error) {
+	rows, err := q.Query(ctx, findSagaStepsQuery, sagaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []saga.StepState
+	for rows.Next() {
+		var (
+			step                     saga.StepState
+			status                   string
+			lastError, correlationID *string
+			updatedAt                pgtype.Timestamp
+		)
+		err = rows.Scan(&step.Name, &status, &step.Attempts, &lastError, &correlationID, &updatedAt)
+		if err != nil {
+			return nil, err
+		}
+		step.Status = saga.StepStatus(status)
+		if lastError != nil {
+			step.LastError = *lastError
+		}
+		if correlationID != nil {
+			step.CorrelationID = *correlationID
+		}
+		step.UpdatedAt = updatedAt.Time
+		steps = append(steps, step)
+	}
+	return steps, rows.Err()
+}
+
+func scanSaga(row pgx.Row, instance *saga.Instance) error {
+	var (
+		state                string
+		createdAt, updatedAt pgtype.Timestamp
+	)
+	err := row.Scan(&instance.ID, &instance.Saga, &instance.CorrelationID, &state, &createdAt, &updatedAt)
+	if err != nil {
+		return err
+	}
+	instance.State = saga.State(state)
+	instance.CreatedAt, instance.UpdatedAt = createdAt.Time, updatedAt.Time
+	return nil
+}
+
+func textToModel(text string) *string {
+	if text == `` {
+		return nil
+	}
+	return &text
+}
+
+// commandTransport sends saga commands through event log in transaction of order.
+type commandTransport struct {
+	tx    pgx.Tx
+	order domain.Order
+}
+
+func (o commandTransport) Send(ctx context.Context, sagaID uuid.UUID, command saga.MessageType) error {
+	event, err := mapToEvent(o.order, sagaID, command)
+	if err != nil {
+		return err
+	}
+	return insertEvent(ctx, o.tx, event)
+}
+
+const (
+	findSagaQuery = `
+	SELECT saga_id, saga_name, correlation_id, state, created_at, updated_at
+	FROM saga_instances WHERE saga_id = $1 FOR UPDATE`
+	listSagasQuery = `
+	SELECT saga_id, saga_name, correlation_id, state, created_at, updated_at
+	FROM saga_instances WHERE correlation_id = $1 ORDER BY created_at, saga_id`
+	findSagaStepsQuery = `
+	SELECT name, status, attempts, last_error, correlation_id, updated_at
+	FROM saga_steps WHERE saga_id = $1 ORDER BY position`
+	upsertSagaQuery = `
+	INSERT INTO saga_instances(saga_id, saga_name, correlation_id, state) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (saga_id) DO UPDATE SET state = EXCLUDED.state, updated_at = CURRENT_TIMESTAMP
+	WHERE saga_instances.state IS DISTINCT FROM EXCLUDED.state`
+	upsertSagaStepQuery = `
+	INSERT INTO saga_steps(saga_id, position, name, status, attempts, last_error, correlation_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (saga_id, position) DO UPDATE SET
+		status = EXCLUDED.status, attempts = EXCLUDED.attempts, last_error = EXCLUDED.last_error,
+		correlation_id = EXCLUDED.correlation_id, updated_at = CURRENT_TIMESTAMP
+	WHERE (saga_steps.status, saga_steps.attempts, saga_steps.last_error, saga_steps.correlation_id)
+		IS DISTINCT FROM (EXCLUDED.status, EXCLUDED.attempts, EXCLUDED.last_error, EXCLUDED.correlation_id)`
+)
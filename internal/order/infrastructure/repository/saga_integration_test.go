@@ -0,0 +1,115 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/pkg/saga"
+)
+
+func TestIntegration_OrderSagas(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=orders`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	_, err = pool.Exec(ctx, `
+	INSERT INTO products(sku, name, price) VALUES ('test', 'test', 9.99)
+	ON CONFLICT (sku) DO UPDATE SET price = EXCLUDED.price`)
+	require.NoError(t, err)
+
+	steps := func(instance saga.Instance) []saga.StepStatus {
+		statuses := make([]saga.StepStatus, 0, len(instance.Steps))
+		for _, step := range instance.Steps {
+			statuses = append(statuses, step.Status)
+		}
+		return statuses
+	}
+
+	testcases := map[string]struct {
+		getEvents     func() []domain.Event
+		expectedState saga.State
+		expectedSteps []saga.StepStatus
+		expectedError string
+	}{
+		`not processed order`: {
+			getEvents: func() []domain.Event { return nil },
+		},
+		`running order saga`: {
+			getEvents: func() []domain.Event {
+				return []domain.Event{domain.Process{}, domain.ConfirmStock{}}
+			},
+			expectedState: saga.Running,
+			expectedSteps: []saga.StepStatus{saga.StepSucceeded, saga.StepPending},
+		},
+		`completed order saga`: {
+			getEvents: func() []domain.Event {
+				return []domain.Event{domain.Process{}, domain.ConfirmPayment{PaymentID: genUUID(t)}, domain.ConfirmStock{}}
+			},
+			expectedState: saga.Completed,
+			expectedSteps: []saga.StepStatus{saga.StepSucceeded, saga.StepSucceeded},
+		},
+		`failed order saga`: {
+			getEvents: func() []domain.Event {
+				return []domain.Event{domain.Process{}, domain.ConfirmStock{}, domain.RejectPayment{}}
+			},
+			expectedState: saga.Aborted,
+			expectedSteps: []saga.StepStatus{saga.StepCompensated, saga.StepFailed},
+			expectedError: `payments_failed`,
+		},
+		`canceled order saga`: {
+			getEvents: func() []domain.Event {
+				return []domain.Event{domain.Process{}, domain.Cancel{}}
+			},
+			expectedState: saga.Aborted,
+			expectedSteps: []saga.StepStatus{saga.StepCompensated, saga.StepCompensated},
+			expectedError: `canceled by customer`,
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			orderID := genUUID(t)
+			events := append([]domain.Event{
+				domain.CreateOrder{OrderID: orderID, CustomerID: genUUID(t)},
+				domain.AddItem{Item: domain.Item{SKU: `test`, Quantity: 1}},
+			}, tc.getEvents()...)
+			for _, event := range events {
+				_, _, err := PersistOrder(ctx, orderID, domain.AnyVersion, event)
+				require.NoError(t, err)
+			}
+
+			sagas, err := OrderSagas(ctx, orderID)
+			require.NoError(t, err)
+			if tc.expectedState == saga.NotStarted {
+				require.Empty(t, sagas)
+				return
+			}
+
+			require.Len(t, sagas, 1)
+			instance := sagas[0]
+			require.Equal(t, orderID, instance.ID)
+			require.Equal(t, orderID.String(), instance.CorrelationID)
+			require.Equal(t, tc.expectedState, instance.State)
+			require.Equal(t, tc.expectedSteps, steps(instance))
+			require.Equal(t, tc.expectedError, instance.Steps[len(instance.Steps)-1].LastError)
+			for _, step := range instance.Steps {
+				require.Equal(t, 1, step.Attempts)
+				require.False(t, step.UpdatedAt.IsZero())
+			}
+		})
+	}
+}
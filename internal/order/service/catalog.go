@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/internal/order/infrastructure/repository"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/money"
+)
+
+func CreateProduct(ctx context.Context, sku, name string, price money.Money) (domain.Product, error) {
+	product, err := domain.NewProduct(sku, name, price)
+	if err != nil {
+		return domain.Product{}, errors.MarkAndWrapError(err, domain.ErrDomain, `invalid product`)
+	}
+	return repository.CreateProduct(ctx, product)
+}
+
+func UpdateProduct(ctx context.Context, sku, name string, price money.Money) (domain.Product, error) {
+	product, err := domain.NewProduct(sku, name, price)
+	if err != nil {
+		return domain.Product{}, errors.MarkAndWrapError(err, domain.ErrDomain, `invalid product`)
+	}
+	return repository.UpdateProduct(ctx, product)
+}
+
+func DeleteProduct(ctx context.Context, sku string) error {
+	return repository.DeleteProduct(ctx, sku)
+}
+
+func GetProduct(ctx context.Context, sku string) (domain.Product, error) {
+	return repository.FindProduct(ctx, sku)
+}
+
+func ListProducts(ctx context.Context) ([]domain.Product, error) {
+	return repository.ListProducts(ctx)
+}
@@ -2,52 +2,55 @@ package service
 
 import (
 	"context"
-	"log"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
 	"github.com/google/uuid"
 	"github.com/moeryomenko/saga/internal/order/domain"
 	"github.com/moeryomenko/saga/internal/order/infrastructure/eventhandler"
 	"github.com/moeryomenko/saga/internal/order/infrastructure/repository"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/inbox"
+	"github.com/moeryomenko/saga/pkg/outbox"
 )
 
 func HandleEvent(ctx context.Context, orderID uuid.UUID, event domain.Event) (domain.Order, error) {
-	return repository.PersistOrder(ctx, orderID, event)
+	order, _, err := repository.PersistOrder(ctx, orderID, domain.AnyVersion, event)
+	return order, err
 }
 
-func Procuder(period time.Duration) func(ctx context.Context) error {
-	return func(ctx context.Context) error {
-		eventPollTicker := time.NewTicker(period)
-		defer eventPollTicker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return nil
-			case <-eventPollTicker.C:
-				id, order, err := repository.GetEvent(ctx)
-				switch err {
-				case nil:
-				case repository.ErrNoEvents:
-					continue
-				default:
-					log.Println(err)
-					return err
-				}
-
-				err = backoff.Retry(func() error {
-					return eventhandler.Produce(ctx, order)
-				}, backoff.NewExponentialBackOff())
-				if err != nil {
-					return err
-				}
-
-				err = repository.Ack(ctx, id)
-				if err != nil {
-					return err
-				}
-			}
-		}
+// HandleReply applies saga reply to order, redelivered reply is skipped.
+func HandleReply(ctx context.Context, msg inbox.Message, orderID uuid.UUID, event domain.Event) error {
+	_, err := repository.PersistReply(ctx, msg, orderID, event)
+	if errors.Is(err, repository.ErrDuplicateMessage) {
+		return nil
 	}
+	return err
+}
+
+// HandleCommand applies customer command to order, if order wasn't changed
+// since expected version, and returns order with its new version.
+func HandleCommand(ctx context.Context, orderID uuid.UUID, expected int, event domain.Event) (domain.OrderView, error) {
+	order, version, err := repository.PersistOrder(ctx, orderID, expected, event)
+	return domain.OrderView{Order: order, Version: version}, err
+}
+
+// ProcessOrder closes order to changes, prices it by catalog and applies discount codes,
+// saga participants must confirm order until given timeout expires.
+func ProcessOrder(ctx context.Context, orderID uuid.UUID, expected int, codes []string, timeout time.Duration) (domain.OrderView, error) {
+	now := time.Now()
+	return HandleCommand(ctx, orderID, expected, domain.Process{
+		Codes:    codes,
+		Now:      now,
+		Deadline: now.Add(timeout),
+	})
+}
+
+// Procuder relays commands from event log to saga participants.
+func Procuder(cfg outbox.RelayConfig) func(ctx context.Context) error {
+	return repository.EventLog().Relay(cfg, eventhandler.Publisher())
+}
+
+// PurgeEvents periodically removes published events from event log.
+func PurgeEvents(cfg outbox.RetentionConfig) func(ctx context.Context) error {
+	return repository.EventLog().Retain(cfg)
 }
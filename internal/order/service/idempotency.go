@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/moeryomenko/saga/internal/order/infrastructure/repository"
+)
+
+var (
+	ErrIdempotencyKeyReused = repository.ErrIdempotencyKeyReused
+	ErrRequestInProgress    = repository.ErrRequestInProgress
+	ErrLeaseLost            = repository.ErrLeaseLost
+)
+
+// IdempotentResponse is stored response of request with idempotency key.
+type IdempotentResponse struct {
+	Status  int
+	Headers map[string]string
+	Body    []byte
+}
+
+// ReserveIdempotencyKey reserves key for request and returns token of its lease
+// or returns response of the first request with this key.
+func ReserveIdempotencyKey(ctx context.Context, key, fingerprint string, ttl, lease time.Duration) (*IdempotentResponse, uuid.UUID, error) {
+	stored, token, err := repository.ReserveIdempotencyKey(ctx, key, fingerprint, time.Now(), ttl, lease)
+	if err != nil || stored == nil {
+		return nil, token, err
+	}
+	return &IdempotentResponse{Status: stored.Status, Headers: stored.Headers, Body: stored.Body}, uuid.Nil, nil
+}
+
+func SaveIdempotentResponse(ctx context.Context, key string, token uuid.UUID, resp IdempotentResponse) error {
+	return repository.SaveIdempotentResponse(ctx, key, token, repository.IdempotentResponse{
+		Status:  resp.Status,
+		Headers: resp.Headers,
+		Body:    resp.Body,
+	})
+}
+
+func ReleaseIdempotencyKey(ctx context.Context, key string, token uuid.UUID) error {
+	return repository.ReleaseIdempotencyKey(ctx, key, token)
+}
+
+// PurgeIdempotencyKeys periodically removes expired idempotency keys.
+func PurgeIdempotencyKeys(period time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		purgeTicker := time.NewTicker(period)
+		defer purgeTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-purgeTicker.C:
+				_, err := repository.PurgeIdempotencyKeys(ctx, time.Now())
+				if err != nil {
+					log.Println(err)
+				}
+			}
+		}
+	}
+}
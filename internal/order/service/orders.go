@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/internal/order/infrastructure/repository"
+	"github.com/moeryomenko/saga/pkg/saga"
+)
+
+func GetOrder(ctx context.Context, orderID uuid.UUID) (domain.OrderView, error) {
+	return repository.FindOrder(ctx, orderID)
+}
+
+// GetOrderVersion returns order state at given version of its history.
+func GetOrderVersion(ctx context.Context, orderID uuid.UUID, version int) (domain.OrderView, error) {
+	order, err := repository.LoadOrder(ctx, orderID, version)
+	return domain.OrderView{Order: order, Version: version}, err
+}
+
+func GetOrderEvents(ctx context.Context, orderID uuid.UUID) ([]domain.StoredEvent, error) {
+	return repository.OrderEvents(ctx, orderID)
+}
+
+// GetOrderSagas returns sagas of order with status of their steps.
+func GetOrderSagas(ctx context.Context, orderID uuid.UUID) ([]saga.Instance, error) {
+	return repository.OrderSagas(ctx, orderID)
+}
+
+func ListCustomerOrders(ctx context.Context, customerID uuid.UUID, filter domain.OrdersFilter) (domain.OrdersPage, error) {
+	return repository.ListCustomerOrders(ctx, customerID, filter)
+}
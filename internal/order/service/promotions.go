@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/internal/order/infrastructure/repository"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/money"
+)
+
+func CreatePromotion(ctx context.Context, code string, kind domain.DiscountKind, value decimal.Decimal, currency money.Currency, expiresAt time.Time, usageLimit int) (domain.Promotion, error) {
+	promotion, err := domain.NewPromotion(code, kind, value, currency, expiresAt, usageLimit)
+	if err != nil {
+		return domain.Promotion{}, errors.MarkAndWrapError(err, domain.ErrDomain, `invalid promotion`)
+	}
+	return repository.CreatePromotion(ctx, promotion)
+}
+
+func DeletePromotion(ctx context.Context, code string) error {
+	return repository.DeletePromotion(ctx, code)
+}
+
+func GetPromotion(ctx context.Context, code string) (domain.Promotion, error) {
+	return repository.FindPromotion(ctx, code)
+}
+
+func ListPromotions(ctx context.Context) ([]domain.Promotion, error) {
+	return repository.ListPromotions(ctx)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/moeryomenko/saga/internal/order/domain"
+	"github.com/moeryomenko/saga/internal/order/infrastructure/repository"
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+// ExpireOrders periodically cancels orders, which weren't confirmed by saga
+// participants until deadline, cancellation emits CancelOrder event for compensation.
+func ExpireOrders(period time.Duration, batchSize int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		expirationTicker := time.NewTicker(period)
+		defer expirationTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-expirationTicker.C:
+				orderIDs, err := repository.FindExpiredOrders(ctx, time.Now(), batchSize)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+
+				for _, orderID := range orderIDs {
+					_, err = HandleEvent(ctx, orderID, domain.Timeout{Now: time.Now()})
+					switch {
+					case err == nil:
+					case errors.Is(err, domain.ErrDomain):
+						// order was confirmed or canceled concurrently.
+					default:
+						log.Println(err)
+					}
+				}
+			}
+		}
+	}
+}
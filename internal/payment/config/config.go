@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -19,7 +20,18 @@ func LoadConfig() (*Config, error) {
 
 // Config represents service configurations.
 type Config struct {
+	// EventRelayMode is how outbox relay learns about new events: poll, notify or replication,
+	// in notify mode polling period is fallback for missed notifications.
+	EventRelayMode string `envconfig:"EVENT_RELAY_MODE" default:"poll"`
+	// EventPollingPeriod is max latency of outbox relay, when event log is drained.
 	EventPollingPeriod time.Duration `envconfig:"EVENT_POLLING_PERIOD" default:"200ms"`
+	// EventBatchSize is max number of events published by outbox relay at once.
+	EventBatchSize int `envconfig:"EVENT_BATCH_SIZE" default:"100"`
+	// EventRetention removes published events from event log.
+	EventRetention RetentionConfig `envconfig:"EVENT_RETENTION"`
+	// FXRates is exchange rates of currency pairs, e.g. "EUR/USD:1.08,USD/EUR:0.92",
+	// payments in currency without rate to balance currency are rejected.
+	FXRates map[string]string `envconfig:"FX_RATES"`
 
 	Health   HealthConfig `envconfig:"HEALTH"`
 	Stream   StreamConfig `envconfig:"STREAM"`
@@ -39,12 +51,27 @@ type HealthConfig struct {
 type StreamConfig struct {
 	Host string `envconfig:"HOST"`
 	Port int    `envconfig:"PORT" default:"6379"`
+	// Consumer is name of replica in consumer groups, it must be stable
+	// across restarts, hostname of replica is used by default.
+	Consumer string `envconfig:"CONSUMER"`
 }
 
 func (c StreamConfig) Addr() string {
 	return fmt.Sprintf(`%s:%d`, c.Host, c.Port)
 }
 
+// ConsumerName returns name of replica in consumer groups.
+func (c StreamConfig) ConsumerName() string {
+	if c.Consumer != `` {
+		return c.Consumer
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return `default`
+	}
+	return hostname
+}
+
 // DBConfig represents database connection configuration.
 type DBConfig struct {
 	Host     string `envconfig:"HOST"`
@@ -61,3 +88,18 @@ type PoolConfig struct {
 	MaxOpenConns int `envconfig:"MAX_OPEN_CONNS" default:"20"`
 	MaxIdleConns int `envconfig:"MAX_IDLE_CONNS" default:"20"`
 }
+
+// RetentionConfig represents retention of published events of outbox.
+type RetentionConfig struct {
+	// Policy is what happens to published events: delete or archive.
+	Policy string `envconfig:"POLICY" default:"delete"`
+	// Days is age of published events to be removed.
+	Days      int           `envconfig:"DAYS" default:"7"`
+	Period    time.Duration `envconfig:"PERIOD" default:"1h"`
+	BatchSize int           `envconfig:"BATCH_SIZE" default:"1000"`
+}
+
+// Age returns age of published events to be removed.
+func (cfg RetentionConfig) Age() time.Duration {
+	return time.Duration(cfg.Days) * 24 * time.Hour
+}
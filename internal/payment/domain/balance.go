@@ -2,57 +2,84 @@ package domain
 
 import (
 	"github.com/google/uuid"
-	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/pkg/money"
 )
 
+// Balance is customer funds, available and reserved amounts are in the same currency.
 type Balance struct {
 	CustomerID uuid.UUID
-	Amount     decimal.Decimal
-	Reserved   decimal.Decimal
+	Amount     money.Money
+	Reserved   money.Money
 }
 
-func (b Balance) Apply(payment Payment) (balance Balance, err error) {
-	switch payment := payment.(type) {
+// Apply changes balance by payment, new payment is returned
+// with amount in balance currency.
+func (b Balance) Apply(payment Payment, rates money.Rates) (Balance, Payment, error) {
+	var balance Balance
+	switch p := payment.(type) {
 	case NewPayment:
-		balance, err = b.ReserveAmount(payment)
+		reserved, converted, err := b.ReserveAmount(p, rates)
 		if err != nil {
-			return b, err
+			return b, converted, err
 		}
+		balance, payment = reserved, converted
 	case CompletedPayment:
-		balance = b.CompletePayment(payment)
-	case CanceledPayment:
-		balance = b.Refund(payment)
+		balance = b.CompletePayment(p)
+	case CanceledPayment, RefundedPayment:
+		balance = b.Refund(p)
 	default:
 		panic(`bug: invalid payment`)
 	}
 	balance.CustomerID = b.CustomerID
-	return balance, nil
+	return balance, payment, nil
 }
 
-func (b Balance) ReserveAmount(payment Payment) (Balance, error) {
-	available, reserved := b.Amount.Sub(payment.GetAmount()), b.Reserved.Add(payment.GetAmount())
+// ReserveAmount reserves payment amount, amount in other currency is converted
+// by exchange rates, payment is rejected if rate of currencies is unknown.
+func (b Balance) ReserveAmount(payment NewPayment, rates money.Rates) (Balance, NewPayment, error) {
+	amount, err := rates.Convert(payment.Amount, b.Amount.Currency)
+	if err != nil {
+		return b, payment, ErrCurrencyMismatch
+	}
+	payment.Amount = amount
+
+	available, _ := b.Amount.Sub(amount)
+	reserved, _ := b.Reserved.Add(amount)
 
-	if available.LessThan(decimal.Zero) {
-		return b, ErrInsufficientFunds
+	if available.IsNegative() {
+		return b, payment, ErrInsufficientFunds
 	}
 
 	return Balance{
 		Amount:   available,
 		Reserved: reserved,
-	}, nil
+	}, payment, nil
 }
 
 func (b Balance) CompletePayment(payment Payment) Balance {
+	reserved, _ := b.Reserved.Sub(payment.GetAmount())
 	return Balance{
 		Amount:   b.Amount,
-		Reserved: b.Reserved.Sub(payment.GetAmount()),
+		Reserved: reserved,
 	}
 }
 
+// Refund returns payment amount to available funds, refund of completed
+// payment returns only refunded amount, because it isn't reserved anymore.
 func (b Balance) Refund(payment Payment) Balance {
-	amount := payment.GetAmount()
+	if payment, ok := payment.(RefundedPayment); ok {
+		available, _ := b.Amount.Add(payment.Refund)
+		return Balance{
+			Amount:   available,
+			Reserved: b.Reserved,
+		}
+	}
+
+	available, _ := b.Amount.Add(payment.GetAmount())
+	reserved, _ := b.Reserved.Sub(payment.GetAmount())
 	return Balance{
-		Amount:   b.Amount.Add(amount),
-		Reserved: b.Reserved.Sub(amount),
+		Amount:   available,
+		Reserved: reserved,
 	}
 }
@@ -6,6 +6,9 @@ var (
 	ErrDomain = errors.New(`domain`)
 
 	ErrInsufficientFunds = errors.New(`insufficient funds to pay`)
+	ErrCurrencyMismatch  = errors.New(`payment currency differs from balance currency`)
 	ErrCanceledPayment   = errors.New(`compelete canceled payment`)
 	ErrFailedPayment     = errors.New(`cancel failed payment`)
+	ErrRefundPayment     = errors.New(`refund not completed payment`)
+	ErrRefundApplied     = errors.New(`refund already applied`)
 )
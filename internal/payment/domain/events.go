@@ -2,36 +2,48 @@ package domain
 
 import (
 	"github.com/google/uuid"
-	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/pkg/money"
 )
 
 type Event interface {
-	GetID() uuid.UUID
+	GetOrderID() uuid.UUID
 }
 
 type Reserve struct {
 	OrderID uuid.UUID
-	Amount  decimal.Decimal
+	Amount  money.Money
 }
 
-func (e Reserve) GetID() uuid.UUID {
-	return uuid.UUID{}
+func (e Reserve) GetOrderID() uuid.UUID {
+	return e.OrderID
 }
 
 type Complete struct {
-	PaymentID uuid.UUID
+	OrderID uuid.UUID
 }
 
-func (e Complete) GetID() uuid.UUID {
-	return e.PaymentID
+func (e Complete) GetOrderID() uuid.UUID {
+	return e.OrderID
 }
 
 type Cancel struct {
-	PaymentID uuid.UUID
+	OrderID uuid.UUID
+}
+
+func (e Cancel) GetOrderID() uuid.UUID {
+	return e.OrderID
+}
+
+// Refund returns part of completed payment for returned order items.
+type Refund struct {
+	OrderID  uuid.UUID
+	ReturnID uuid.UUID
+	Amount   money.Money
 }
 
-func (e Cancel) GetID() uuid.UUID {
-	return e.PaymentID
+func (e Refund) GetOrderID() uuid.UUID {
+	return e.OrderID
 }
 
 func Apply(payment Payment, event Event) (Payment, error) {
@@ -46,6 +58,8 @@ func Apply(payment Payment, event Event) (Payment, error) {
 		return CompletePayment(payment)
 	case Cancel:
 		return CancelPayment(payment)
+	case Refund:
+		return RefundPayment(payment, event)
 	default:
 		panic(`bug: invalid payment event`)
 	}
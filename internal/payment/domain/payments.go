@@ -2,12 +2,13 @@ package domain
 
 import (
 	"github.com/google/uuid"
-	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/pkg/money"
 )
 
 type Payment interface {
 	GetID() uuid.UUID
-	GetAmount() decimal.Decimal
+	GetAmount() money.Money
 }
 
 type ResultPayment interface {
@@ -18,14 +19,14 @@ type ResultPayment interface {
 type NewPayment struct {
 	ID      uuid.UUID
 	OrderID uuid.UUID
-	Amount  decimal.Decimal
+	Amount  money.Money
 }
 
 func (p NewPayment) GetID() uuid.UUID {
 	return p.ID
 }
 
-func (p NewPayment) GetAmount() decimal.Decimal {
+func (p NewPayment) GetAmount() money.Money {
 	return p.Amount
 }
 
@@ -36,14 +37,14 @@ func (p NewPayment) GetOrderID() uuid.UUID {
 type FailedPayment struct {
 	ID      uuid.UUID
 	OrderID uuid.UUID
-	Amount  decimal.Decimal
+	Amount  money.Money
 }
 
 func (p FailedPayment) GetID() uuid.UUID {
 	return p.ID
 }
 
-func (p FailedPayment) GetAmount() decimal.Decimal {
+func (p FailedPayment) GetAmount() money.Money {
 	return p.Amount
 }
 
@@ -53,30 +54,54 @@ func (p FailedPayment) GetOrderID() uuid.UUID {
 
 type CompletedPayment struct {
 	ID     uuid.UUID
-	Amount decimal.Decimal
+	Amount money.Money
 }
 
 func (p CompletedPayment) GetID() uuid.UUID {
 	return p.ID
 }
 
-func (p CompletedPayment) GetAmount() decimal.Decimal {
+func (p CompletedPayment) GetAmount() money.Money {
 	return p.Amount
 }
 
 type CanceledPayment struct {
 	ID     uuid.UUID
-	Amount decimal.Decimal
+	Amount money.Money
 }
 
 func (p CanceledPayment) GetID() uuid.UUID {
 	return p.ID
 }
 
-func (p CanceledPayment) GetAmount() decimal.Decimal {
+func (p CanceledPayment) GetAmount() money.Money {
+	return p.Amount
+}
+
+// RefundedPayment is completed payment, which was partially or fully refunded.
+type RefundedPayment struct {
+	ID      uuid.UUID
+	OrderID uuid.UUID
+	Amount  money.Money
+	// Refunded is total refunded amount.
+	Refunded money.Money
+	// ReturnID and Refund describe the latest refund.
+	ReturnID uuid.UUID
+	Refund   money.Money
+}
+
+func (p RefundedPayment) GetID() uuid.UUID {
+	return p.ID
+}
+
+func (p RefundedPayment) GetAmount() money.Money {
 	return p.Amount
 }
 
+func (p RefundedPayment) GetOrderID() uuid.UUID {
+	return p.OrderID
+}
+
 func CompletePayment(payment Payment) (Payment, error) {
 	switch payment := payment.(type) {
 	case NewPayment:
@@ -89,6 +114,42 @@ func CompletePayment(payment Payment) (Payment, error) {
 	}
 }
 
+// RefundPayment refunds amount of completed payment, refund can't exceed
+// not refunded amount, amount must be in payment currency.
+func RefundPayment(payment Payment, refund Refund) (Payment, error) {
+	refunded := money.Zero(payment.GetAmount().Currency)
+	switch payment := payment.(type) {
+	case CompletedPayment:
+	case RefundedPayment:
+		refunded = payment.Refunded
+	default:
+		return nil, ErrRefundPayment
+	}
+
+	remaining, err := payment.GetAmount().Sub(refunded)
+	if err != nil {
+		return nil, err
+	}
+	amount := refund.Amount
+	cmp, err := amount.Cmp(remaining)
+	if err != nil {
+		return nil, ErrCurrencyMismatch
+	}
+	if cmp > 0 {
+		amount = remaining
+	}
+	refunded, _ = refunded.Add(amount)
+
+	return RefundedPayment{
+		ID:       payment.GetID(),
+		OrderID:  refund.OrderID,
+		Amount:   payment.GetAmount(),
+		Refunded: refunded,
+		ReturnID: refund.ReturnID,
+		Refund:   amount,
+	}, nil
+}
+
 func FailPayment(payment Payment) FailedPayment {
 	switch payment := payment.(type) {
 	case NewPayment:
@@ -1,21 +1,35 @@
 package domain
 
+import "github.com/moeryomenko/saga/pkg/money"
+
 type Tx struct {
 	Payment Payment
 	Event   Event
+	// Rates converts payments in other currency than balance one.
+	Rates money.Rates
 }
 
 func (b Balance) Transaction(tx Tx) (Balance, Payment, error) {
+	if refund, ok := tx.Event.(Refund); ok {
+		// refund is in order currency, but payment was converted to balance one.
+		amount, err := tx.Rates.Convert(refund.Amount, b.Amount.Currency)
+		if err != nil {
+			return b, nil, ErrCurrencyMismatch
+		}
+		refund.Amount = amount
+		tx.Event = refund
+	}
+
 	payment, err := Apply(tx.Payment, tx.Event)
 	if err != nil {
 		return b, nil, err
 	}
 
-	balance, err := b.Apply(payment)
+	balance, payment, err := b.Apply(payment, tx.Rates)
 	switch err {
 	case nil:
 		return balance, payment, nil
-	case ErrInsufficientFunds:
+	case ErrInsufficientFunds, ErrCurrencyMismatch:
 		return b, FailPayment(payment), nil
 	default:
 		return b, nil, err
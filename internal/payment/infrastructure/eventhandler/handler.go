@@ -2,62 +2,32 @@ package eventhandler
 
 import (
 	"context"
-	"log"
-	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 
 	"github.com/moeryomenko/saga/internal/payment/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/inbox"
+	"github.com/moeryomenko/saga/pkg/saga"
 	"github.com/moeryomenko/saga/schema"
 )
 
-type EventHandler func(context.Context, uuid.UUID, domain.Event) error
+// EventHandler handles event of customer, message of event is received by inbox.
+type EventHandler func(context.Context, inbox.Message, uuid.UUID, domain.Event) error
 
+// HandleEvents handles saga commands of order service.
 func HandleEvents(handler EventHandler) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
-		for initConsumerGroup(ctx) != nil {
-		}
-
-		consumerID := uuid.New().String()
-		for {
-			select {
-			case <-ctx.Done():
-				return nil
-			default:
-				events, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
-					Group:    PaymentGroup,
-					Consumer: consumerID,
-					Streams:  []string{OrderStream, `>`},
-					Block:    0,
-					Count:    1,
-					NoAck:    false,
-				}).Result()
-				if err != nil {
-					<-time.After(time.Second)
-					continue
-				}
-
-				for _, msg := range events[0].Messages {
-					err := handleMessage(ctx, msg, handler)
-					if err != nil {
-						log.Panicln(err)
-					}
-
-					_, err = client.XAck(ctx, OrderStream, PaymentGroup, msg.ID).Result()
-					if err != nil {
-						log.Println(err)
-					}
-				}
-			}
-		}
+		return streams.Subscribe(PaymentGroup, saga.CommandStream, func(ctx context.Context, id string, values map[string]any) error {
+			return handleMessage(ctx, inbox.MessageOf(saga.CommandStream, id, values), values, handler)
+		})(ctx)
 	}
 }
 
-func handleMessage(ctx context.Context, msg redis.XMessage, handler EventHandler) error {
-	event, err := schema.ToOrderEvent(msg.Values)
+func handleMessage(ctx context.Context, msg inbox.Message, values map[string]any, handler EventHandler) error {
+	event, err := schema.ToOrderEvent(values)
 	if err != nil {
-		return err
+		return errors.MarkAndWrapError(err, saga.ErrRejected, `invalid command`)
 	}
 
 	var domainEvent domain.Event
@@ -65,10 +35,19 @@ func handleMessage(ctx context.Context, msg redis.XMessage, handler EventHandler
 	case schema.NewOrder:
 		domainEvent = domain.Reserve{OrderID: event.OrderID, Amount: event.Price}
 	case schema.CompleteOrder:
-		domainEvent = domain.Complete{PaymentID: event.PaymentID}
+		domainEvent = domain.Complete{OrderID: event.OrderID}
 	case schema.CancelOrder:
-		domainEvent = domain.Cancel{PaymentID: event.PaymentID}
+		domainEvent = domain.Cancel{OrderID: event.OrderID}
+	case schema.ReturnOrder:
+		domainEvent = domain.Refund{OrderID: event.OrderID, ReturnID: event.ReturnID, Amount: event.Price}
+	default:
+		// command isn't addressed to payments.
+		return nil
 	}
 
-	return handler(ctx, event.CustomerID, domainEvent)
+	err = handler(ctx, msg, event.CustomerID, domainEvent)
+	if errors.Is(err, domain.ErrDomain) {
+		return errors.MarkAndWrapError(err, saga.ErrRejected, `command is rejected`)
+	}
+	return err
 }
@@ -1,9 +1,15 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/moeryomenko/saga/pkg/inbox"
+	"github.com/moeryomenko/saga/pkg/outbox"
+)
 
 var (
 	ErrInfrastructure = errors.New(`infrastructure`)
 
-	ErrNoEvents = errors.New(`no new event into log`)
+	ErrNoEvents         = outbox.ErrNoEvents
+	ErrDuplicateMessage = inbox.ErrDuplicate
 )
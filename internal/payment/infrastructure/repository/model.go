@@ -4,6 +4,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgtype"
 	"github.com/moeryomenko/saga/internal/payment/domain"
+	"github.com/moeryomenko/saga/pkg/money"
 	"github.com/shopspring/decimal"
 )
 
@@ -12,12 +13,14 @@ const (
 	statusFailed    = `failed`
 	statusCompleted = `completed`
 	statusCanceled  = `canceled`
+	statusRefunded  = `refunded`
 )
 
 type Balance struct {
 	CustomerID pgtype.UUID
 	Available  decimal.Decimal
 	Reserved   decimal.Decimal
+	Currency   string
 }
 
 type Payment struct {
@@ -25,32 +28,42 @@ type Payment struct {
 	CustomerID pgtype.UUID
 	OrderID    pgtype.UUID
 	Amount     decimal.Decimal
+	Currency   string
+	Refunded   decimal.Decimal
 	Status     string
 }
 
 func mapPaymentToDomain(p *Payment) domain.Payment {
+	amount := money.New(p.Amount, money.Currency(p.Currency))
 	switch p.Status {
 	case statusNew:
 		return domain.NewPayment{
 			ID:      p.PaymentID.Bytes,
 			OrderID: p.OrderID.Bytes,
-			Amount:  p.Amount,
+			Amount:  amount,
 		}
 	case statusFailed:
 		return domain.FailedPayment{
 			ID:      p.PaymentID.Bytes,
 			OrderID: p.OrderID.Bytes,
-			Amount:  p.Amount,
+			Amount:  amount,
 		}
 	case statusCompleted:
 		return domain.CompletedPayment{
 			ID:     p.PaymentID.Bytes,
-			Amount: p.Amount,
+			Amount: amount,
 		}
 	case statusCanceled:
 		return domain.CanceledPayment{
 			ID:     p.PaymentID.Bytes,
-			Amount: p.Amount,
+			Amount: amount,
+		}
+	case statusRefunded:
+		return domain.RefundedPayment{
+			ID:       p.PaymentID.Bytes,
+			OrderID:  p.OrderID.Bytes,
+			Amount:   amount,
+			Refunded: money.New(p.Refunded, amount.Currency),
 		}
 	}
 	return nil
@@ -59,8 +72,8 @@ func mapPaymentToDomain(p *Payment) domain.Payment {
 func mapBalanceToDomain(b *Balance) domain.Balance {
 	return domain.Balance{
 		CustomerID: b.CustomerID.Bytes,
-		Amount:     b.Available,
-		Reserved:   b.Reserved,
+		Amount:     money.New(b.Available, money.Currency(b.Currency)),
+		Reserved:   money.New(b.Reserved, money.Currency(b.Currency)),
 	}
 }
 
@@ -72,7 +85,8 @@ func mapPaymentToModel(customerID uuid.UUID, p domain.Payment) Payment {
 			PaymentID:  pgtype.UUID{Bytes: p.ID, Status: pgtype.Present},
 			OrderID:    pgtype.UUID{Bytes: p.OrderID, Status: pgtype.Present},
 			CustomerID: pgtype.UUID{Bytes: customerID, Status: pgtype.Present},
-			Amount:     p.Amount,
+			Amount:     p.Amount.Amount,
+			Currency:   string(p.Amount.Currency),
 			Status:     status,
 		}
 	case domain.FailedPayment:
@@ -80,9 +94,20 @@ func mapPaymentToModel(customerID uuid.UUID, p domain.Payment) Payment {
 			PaymentID:  pgtype.UUID{Bytes: p.ID, Status: pgtype.Present},
 			OrderID:    pgtype.UUID{Bytes: p.OrderID, Status: pgtype.Present},
 			CustomerID: pgtype.UUID{Bytes: customerID, Status: pgtype.Present},
-			Amount:     p.Amount,
+			Amount:     p.Amount.Amount,
+			Currency:   string(p.Amount.Currency),
 			Status:     statusFailed,
 		}
+	case domain.RefundedPayment:
+		return Payment{
+			PaymentID:  pgtype.UUID{Bytes: p.ID, Status: pgtype.Present},
+			OrderID:    pgtype.UUID{Bytes: p.OrderID, Status: pgtype.Present},
+			CustomerID: pgtype.UUID{Bytes: customerID, Status: pgtype.Present},
+			Amount:     p.Amount.Amount,
+			Currency:   string(p.Amount.Currency),
+			Refunded:   p.Refunded.Amount,
+			Status:     statusRefunded,
+		}
 	case domain.CompletedPayment:
 		status = statusCompleted
 	case domain.CanceledPayment:
@@ -97,7 +122,8 @@ func mapPaymentToModel(customerID uuid.UUID, p domain.Payment) Payment {
 func mapBalanceToModel(b domain.Balance) Balance {
 	return Balance{
 		CustomerID: pgtype.UUID{Bytes: b.CustomerID, Status: pgtype.Present},
-		Available:  b.Amount,
-		Reserved:   b.Reserved,
+		Available:  b.Amount.Amount,
+		Reserved:   b.Reserved.Amount,
+		Currency:   string(b.Amount.Currency),
 	}
 }
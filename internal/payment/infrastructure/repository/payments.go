@@ -8,17 +8,31 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/moeryomenko/saga/internal/payment/domain"
 	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/inbox"
+	"github.com/moeryomenko/saga/pkg/money"
 )
 
-func PersistTransaction(ctx context.Context, customerID uuid.UUID, event domain.Event) (domain.Payment, error) {
+// PersistTransaction applies event to customer balance and payment, message of event
+// is received by inbox in the same transaction, so redelivered message returns
+// ErrDuplicateMessage. Rates convert payments in other currency than balance one.
+func PersistTransaction(ctx context.Context, msg inbox.Message, customerID uuid.UUID, rates money.Rates, event domain.Event) (domain.Payment, error) {
 	var payment domain.Payment
 	err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		err := inbox.Receive(ctx, tx, msg)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrDuplicateMessage):
+			return err
+		default:
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't receive message`)
+		}
+
 		balance, err := findBalanceByCustomer(ctx, tx, customerID)
 		if err != nil {
 			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find balance`)
 		}
 
-		payment, err = findPaymentByID(ctx, tx, event.GetID())
+		payment, err = findPaymentByOrderID(ctx, tx, event.GetOrderID())
 		switch err {
 		case nil, pgx.ErrNoRows:
 			// it's ok for NewPayment event.
@@ -29,6 +43,7 @@ func PersistTransaction(ctx context.Context, customerID uuid.UUID, event domain.
 		balance, payment, err = balance.Transaction(domain.Tx{
 			Payment: payment,
 			Event:   event,
+			Rates:   rates,
 		})
 		if err != nil {
 			return errors.MarkAndWrapError(err, domain.ErrDomain, `couldn't apply event`)
@@ -36,12 +51,12 @@ func PersistTransaction(ctx context.Context, customerID uuid.UUID, event domain.
 
 		err = saveBalance(ctx, tx, balance)
 		if err != nil {
-			return errors.MarkAndWrapError(err, domain.ErrDomain, `couldn't update balance`)
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't update balance`)
 		}
 
 		err = savePayment(ctx, tx, balance.CustomerID, payment)
 		if err != nil {
-			return errors.MarkAndWrapError(err, domain.ErrDomain, `couldn't update payment`)
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't update payment`)
 		}
 
 		return insertEvent(ctx, tx, payment)
@@ -54,6 +69,7 @@ func findBalanceByCustomer(ctx context.Context, tx pgx.Tx, customerID uuid.UUID)
 	err := tx.QueryRow(ctx, findBalanceQuery, customerID.String()).Scan(
 		&balance.Available,
 		&balance.Reserved,
+		&balance.Currency,
 	)
 	if err != nil {
 		return domain.Balance{}, err
@@ -67,12 +83,14 @@ func saveBalance(ctx context.Context, tx pgx.Tx, balance domain.Balance) error {
 	return err
 }
 
-func findPaymentByID(ctx context.Context, tx pgx.Tx, paymentID uuid.UUID) (domain.Payment, error) {
-	payment := &Payment{PaymentID: pgtype.UUID{Bytes: paymentID, Status: pgtype.Present}}
-	err := tx.QueryRow(ctx, findPaymentQuery, paymentID.String()).Scan(
+func findPaymentByOrderID(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) (domain.Payment, error) {
+	payment := &Payment{OrderID: pgtype.UUID{Bytes: orderID, Status: pgtype.Present}}
+	err := tx.QueryRow(ctx, findPaymentQuery, orderID.String()).Scan(
+		&payment.PaymentID,
 		&payment.CustomerID,
-		&payment.OrderID,
 		&payment.Amount,
+		&payment.Currency,
+		&payment.Refunded,
 		&payment.Status,
 	)
 	if err != nil {
@@ -85,22 +103,39 @@ func savePayment(ctx context.Context, tx pgx.Tx, customerID uuid.UUID, payment d
 	model := mapPaymentToModel(customerID, payment)
 	switch model.Status {
 	case statusNew:
-		_, err := tx.Exec(ctx, insertPaymentQuery, model.PaymentID, model.Status, model.CustomerID, model.OrderID, model.Amount)
+		_, err := tx.Exec(ctx, insertPaymentQuery, model.PaymentID, model.Status, model.CustomerID, model.OrderID, model.Amount, model.Currency)
 		return err
 	case statusFailed:
 		_, err := tx.Exec(ctx, cancelPaymentByOrderQuery, model.OrderID, model.Status)
 		return err
+	case statusRefunded:
+		return saveRefund(ctx, tx, payment.(domain.RefundedPayment), model)
 	default:
 		_, err := tx.Exec(ctx, updatePaymentQuery, model.PaymentID, model.Status)
 		return err
 	}
 }
 
+// saveRefund records refund by return, so redelivered refund isn't applied twice.
+func saveRefund(ctx context.Context, tx pgx.Tx, payment domain.RefundedPayment, model Payment) error {
+	tag, err := tx.Exec(ctx, insertRefundQuery, payment.ReturnID, model.PaymentID, payment.Refund.Amount, model.Currency)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRefundApplied
+	}
+	_, err = tx.Exec(ctx, refundPaymentQuery, model.PaymentID, model.Status, model.Refunded)
+	return err
+}
+
 const (
-	findPaymentQuery          = `SELECT customer_id, order_id, amount, status FROM payments WHERE payment_id = $1`
-	findBalanceQuery          = `SELECT available_amount, reserved_amount FROM balances WHERE customer_id = $1`
+	findPaymentQuery          = `SELECT payment_id, customer_id, amount, currency, refunded_amount, status FROM payments WHERE order_id = $1 FOR UPDATE`
+	findBalanceQuery          = `SELECT available_amount, reserved_amount, currency FROM balances WHERE customer_id = $1`
 	updateBalanceQuery        = `UPDATE balances SET available_amount = $2, reserved_amount = $3 WHERE customer_id = $1`
-	insertPaymentQuery        = `INSERT INTO payments(payment_id, status, customer_id, order_id, amount) VALUES ($1, $2, $3, $4, $5)`
+	insertPaymentQuery        = `INSERT INTO payments(payment_id, status, customer_id, order_id, amount, currency) VALUES ($1, $2, $3, $4, $5, $6)`
 	updatePaymentQuery        = `UPDATE payments SET status = $2 WHERE payment_id = $1`
 	cancelPaymentByOrderQuery = `UPDATE payments SET status = $2 WHERE order_id = $1`
+	refundPaymentQuery        = `UPDATE payments SET status = $2, refunded_amount = $3 WHERE payment_id = $1`
+	insertRefundQuery         = `INSERT INTO refunds(return_id, payment_id, amount, currency) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`
 )
@@ -17,9 +17,15 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/moeryomenko/saga/internal/payment/domain"
+	"github.com/moeryomenko/saga/pkg/inbox"
+	"github.com/moeryomenko/saga/pkg/money"
+	"github.com/moeryomenko/saga/pkg/outbox"
+	"github.com/moeryomenko/saga/pkg/saga"
 	"github.com/moeryomenko/saga/schema"
 )
 
+var rates = money.Rates{{Base: `USD`, Quote: `EUR`}: decimal.RequireFromString(`0.5`)}
+
 func TestIntegration_Payments(t *testing.T) {
 	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=payments pool_max_conns=1`)
 	require.NoError(t, err)
@@ -35,13 +41,15 @@ func TestIntegration_Payments(t *testing.T) {
 
 	positivePayments(context.Background(), t)
 	negativePayments(context.Background(), t)
+	refundPayments(context.Background(), t)
+	redeliveredPayments(context.Background(), t)
 }
 
 func positivePayments(ctx context.Context, t *testing.T) {
 	testcases := map[string]struct {
 		orderID                uuid.UUID
 		customer               func() (uuid.UUID, error)
-		amount                 decimal.Decimal
+		amount                 money.Money
 		finalEvent             func(uuid.UUID) domain.Event
 		expectedCreatedBalance domain.Balance
 		expectedFinalBalance   domain.Balance
@@ -58,22 +66,46 @@ func positivePayments(ctx context.Context, t *testing.T) {
 				})
 				return customerID, err
 			},
-			amount: decimal.NewFromInt32(20),
+			amount: money.New(decimal.NewFromInt32(20), `USD`),
 			finalEvent: func(u uuid.UUID) domain.Event {
-				return domain.Complete{PaymentID: u}
+				return domain.Complete{OrderID: u}
 			},
 			expectedCreatedBalance: domain.Balance{
-				Amount:   decimal.NewFromInt32(80),
-				Reserved: decimal.NewFromInt32(20),
+				Amount:   money.New(decimal.NewFromInt32(80), `USD`),
+				Reserved: money.New(decimal.NewFromInt32(20), `USD`),
 			},
 			expectedFinalBalance: domain.Balance{
-				Amount:   decimal.NewFromInt32(80),
-				Reserved: decimal.Zero,
+				Amount:   money.New(decimal.NewFromInt32(80), `USD`),
+				Reserved: money.Zero(`USD`),
 			},
 			expectedEvent: func(orderID, paymentID uuid.UUID) schema.PaymentsEvent {
 				return schema.PaymentsEvent{Event: schema.Event{Type: schema.PaymentsConfirmed}, OrderID: orderID, PaymentsID: paymentID}
 			},
 		},
+		`converted payments`: {
+			orderID: uuid.New(),
+			customer: func() (uuid.UUID, error) {
+				customerID := uuid.New()
+				available := decimal.NewFromInt32(100)
+				err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+					_, err := tx.Exec(ctx, `INSERT INTO balances(customer_id, available_amount, currency) VALUES ($1, $2, 'EUR')`, customerID, available)
+					return err
+				})
+				return customerID, err
+			},
+			amount: money.New(decimal.NewFromInt32(20), `USD`),
+			finalEvent: func(u uuid.UUID) domain.Event {
+				return domain.Cancel{OrderID: u}
+			},
+			expectedCreatedBalance: domain.Balance{
+				Amount:   money.New(decimal.NewFromInt32(90), `EUR`),
+				Reserved: money.New(decimal.NewFromInt32(10), `EUR`),
+			},
+			expectedFinalBalance: domain.Balance{
+				Amount:   money.New(decimal.NewFromInt32(100), `EUR`),
+				Reserved: money.Zero(`EUR`),
+			},
+		},
 		`canceled payments`: {
 			orderID: uuid.New(),
 			customer: func() (uuid.UUID, error) {
@@ -85,17 +117,17 @@ func positivePayments(ctx context.Context, t *testing.T) {
 				})
 				return customerID, err
 			},
-			amount: decimal.NewFromInt32(20),
+			amount: money.New(decimal.NewFromInt32(20), `USD`),
 			finalEvent: func(u uuid.UUID) domain.Event {
-				return domain.Cancel{PaymentID: u}
+				return domain.Cancel{OrderID: u}
 			},
 			expectedCreatedBalance: domain.Balance{
-				Amount:   decimal.NewFromInt32(80),
-				Reserved: decimal.NewFromInt32(20),
+				Amount:   money.New(decimal.NewFromInt32(80), `USD`),
+				Reserved: money.New(decimal.NewFromInt32(20), `USD`),
 			},
 			expectedFinalBalance: domain.Balance{
-				Amount:   decimal.NewFromInt32(100),
-				Reserved: decimal.Zero,
+				Amount:   money.New(decimal.NewFromInt32(100), `USD`),
+				Reserved: money.Zero(`USD`),
 			},
 		},
 	}
@@ -107,7 +139,7 @@ func positivePayments(ctx context.Context, t *testing.T) {
 			err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) (err error) {
 				_, err = tx.Exec(ctx, `TRUNCATE event_log`)
 				require.NoError(t, err)
-				_, err = tx.Exec(ctx, `UPDATE event_offset SET offset_acked = 0`)
+				_, err = tx.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
 				require.NoError(t, err)
 				return nil
 			})
@@ -119,7 +151,7 @@ func positivePayments(ctx context.Context, t *testing.T) {
 			tc.expectedFinalBalance.CustomerID = customerID
 
 			// create payments.
-			payment, err := PersistTransaction(ctx, customerID, domain.Reserve{OrderID: tc.orderID, Amount: tc.amount})
+			payment, err := PersistTransaction(ctx, newMessage(), customerID, rates, domain.Reserve{OrderID: tc.orderID, Amount: tc.amount})
 			require.NoError(t, err)
 			checkBalance(ctx, t, customerID, tc.expectedCreatedBalance)
 			if _, ok := payment.(domain.NewPayment); !ok {
@@ -127,16 +159,16 @@ func positivePayments(ctx context.Context, t *testing.T) {
 			}
 
 			// complete payments.
-			event := tc.finalEvent(payment.GetID())
-			payment, err = PersistTransaction(ctx, customerID, event)
+			event := tc.finalEvent(tc.orderID)
+			payment, err = PersistTransaction(ctx, newMessage(), customerID, rates, event)
 			require.NoError(t, err)
 			checkBalance(ctx, t, customerID, tc.expectedFinalBalance)
 
 			if tc.expectedEvent != nil {
-				id, event, err := GetEvent(ctx)
+				id, event, err := getEvent(ctx)
 				require.NoError(t, err)
 				require.Equal(t, tc.expectedEvent(tc.orderID, payment.GetID()), event)
-				err = Ack(ctx, id)
+				err = EventLog().Ack(ctx, id)
 				require.NoError(t, err)
 			}
 		})
@@ -165,11 +197,33 @@ func negativePayments(ctx context.Context, t *testing.T) {
 				return customerID, err
 			},
 			event: func(orderID, _ uuid.UUID) domain.Event {
-				return domain.Reserve{OrderID: orderID, Amount: decimal.NewFromInt32(50)}
+				return domain.Reserve{OrderID: orderID, Amount: money.New(decimal.NewFromInt32(50), `USD`)}
+			},
+			expectedBalance: domain.Balance{
+				Amount:   money.New(decimal.NewFromInt32(40), `USD`),
+				Reserved: money.Zero(`USD`),
+			},
+			expectedEvent: func(orderID uuid.UUID) schema.PaymentsEvent {
+				return schema.PaymentsEvent{Event: schema.Event{Type: schema.PaymentsFailed}, OrderID: orderID}
+			},
+		},
+		`unknown exchange rate`: {
+			orderID: uuid.New(),
+			customer: func() (uuid.UUID, error) {
+				customerID := uuid.New()
+				available := decimal.NewFromInt32(40)
+				err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+					_, err := tx.Exec(ctx, `INSERT INTO balances(customer_id, available_amount) VALUES ($1, $2)`, customerID, available)
+					return err
+				})
+				return customerID, err
+			},
+			event: func(orderID, _ uuid.UUID) domain.Event {
+				return domain.Reserve{OrderID: orderID, Amount: money.New(decimal.NewFromInt32(10), `GBP`)}
 			},
 			expectedBalance: domain.Balance{
-				Amount:   decimal.NewFromInt32(40),
-				Reserved: decimal.Zero,
+				Amount:   money.New(decimal.NewFromInt32(40), `USD`),
+				Reserved: money.Zero(`USD`),
 			},
 			expectedEvent: func(orderID uuid.UUID) schema.PaymentsEvent {
 				return schema.PaymentsEvent{Event: schema.Event{Type: schema.PaymentsFailed}, OrderID: orderID}
@@ -190,17 +244,17 @@ func negativePayments(ctx context.Context, t *testing.T) {
 			preparePayment: func(customerID, orderID uuid.UUID) (uuid.UUID, error) {
 				paymentID := uuid.New()
 				err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
-					_, err := tx.Exec(ctx, insertPaymentQuery, paymentID, statusCompleted, customerID, orderID, decimal.NewFromInt32(20))
+					_, err := tx.Exec(ctx, insertPaymentQuery, paymentID, statusCompleted, customerID, orderID, decimal.NewFromInt32(20), `USD`)
 					return err
 				})
 				return paymentID, err
 			},
-			event: func(_, paymentID uuid.UUID) domain.Event {
-				return domain.Cancel{PaymentID: paymentID}
+			event: func(orderID, _ uuid.UUID) domain.Event {
+				return domain.Cancel{OrderID: orderID}
 			},
 			expectedBalance: domain.Balance{
-				Amount:   decimal.NewFromInt32(60),
-				Reserved: decimal.Zero,
+				Amount:   money.New(decimal.NewFromInt32(60), `USD`),
+				Reserved: money.Zero(`USD`),
 			},
 		},
 		`complete canceled payments`: {
@@ -217,17 +271,17 @@ func negativePayments(ctx context.Context, t *testing.T) {
 			preparePayment: func(customerID, orderID uuid.UUID) (uuid.UUID, error) {
 				paymentID := uuid.New()
 				err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
-					_, err := tx.Exec(ctx, insertPaymentQuery, paymentID, statusCanceled, customerID, orderID, decimal.NewFromInt32(20))
+					_, err := tx.Exec(ctx, insertPaymentQuery, paymentID, statusCanceled, customerID, orderID, decimal.NewFromInt32(20), `USD`)
 					return err
 				})
 				return paymentID, err
 			},
-			event: func(_, paymentID uuid.UUID) domain.Event {
-				return domain.Complete{PaymentID: paymentID}
+			event: func(orderID, _ uuid.UUID) domain.Event {
+				return domain.Complete{OrderID: orderID}
 			},
 			expectedBalance: domain.Balance{
-				Amount:   decimal.NewFromInt32(40),
-				Reserved: decimal.Zero,
+				Amount:   money.New(decimal.NewFromInt32(40), `USD`),
+				Reserved: money.Zero(`USD`),
 			},
 			expectedError: domain.ErrCanceledPayment,
 		},
@@ -245,17 +299,17 @@ func negativePayments(ctx context.Context, t *testing.T) {
 			preparePayment: func(customerID, orderID uuid.UUID) (uuid.UUID, error) {
 				paymentID := uuid.New()
 				err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
-					_, err := tx.Exec(ctx, insertPaymentQuery, paymentID, statusFailed, customerID, orderID, decimal.NewFromInt32(60))
+					_, err := tx.Exec(ctx, insertPaymentQuery, paymentID, statusFailed, customerID, orderID, decimal.NewFromInt32(60), `USD`)
 					return err
 				})
 				return paymentID, err
 			},
-			event: func(_, paymentID uuid.UUID) domain.Event {
-				return domain.Complete{PaymentID: paymentID}
+			event: func(orderID, _ uuid.UUID) domain.Event {
+				return domain.Complete{OrderID: orderID}
 			},
 			expectedBalance: domain.Balance{
-				Amount:   decimal.NewFromInt32(40),
-				Reserved: decimal.Zero,
+				Amount:   money.New(decimal.NewFromInt32(40), `USD`),
+				Reserved: money.Zero(`USD`),
 			},
 			expectedError: domain.ErrCanceledPayment,
 		},
@@ -268,7 +322,7 @@ func negativePayments(ctx context.Context, t *testing.T) {
 			err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) (err error) {
 				_, err = tx.Exec(ctx, `TRUNCATE event_log`)
 				require.NoError(t, err)
-				_, err = tx.Exec(ctx, `UPDATE event_offset SET offset_acked = 0`)
+				_, err = tx.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
 				require.NoError(t, err)
 				return nil
 			})
@@ -285,23 +339,156 @@ func negativePayments(ctx context.Context, t *testing.T) {
 			}()
 			require.NoError(t, err)
 
-			_, err = PersistTransaction(ctx, customerID, tc.event(tc.orderID, paymentID))
+			_, err = PersistTransaction(ctx, newMessage(), customerID, rates, tc.event(tc.orderID, paymentID))
 			if tc.expectedError != nil {
 				require.ErrorIs(t, err, tc.expectedError)
 			}
 			checkBalance(ctx, t, customerID, tc.expectedBalance)
 
 			if tc.expectedEvent != nil {
-				id, event, err := GetEvent(ctx)
+				id, event, err := getEvent(ctx)
 				require.NoError(t, err)
 				require.Equal(t, tc.expectedEvent(tc.orderID), event)
-				err = Ack(ctx, id)
+				err = EventLog().Ack(ctx, id)
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func refundPayments(ctx context.Context, t *testing.T) {
+	customerID, orderID := uuid.New(), uuid.New()
+	err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `TRUNCATE event_log`)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO balances(customer_id, available_amount) VALUES ($1, $2)`, customerID, decimal.NewFromInt32(100))
+		return err
+	})
+	require.NoError(t, err)
+
+	payment, err := PersistTransaction(ctx, newMessage(), customerID, rates, domain.Reserve{OrderID: orderID, Amount: money.New(decimal.NewFromInt32(20), `USD`)})
+	require.NoError(t, err)
+	_, err = PersistTransaction(ctx, newMessage(), customerID, rates, domain.Complete{OrderID: orderID})
+	require.NoError(t, err)
+	id, _, err := getEvent(ctx)
+	require.NoError(t, err)
+	require.NoError(t, EventLog().Ack(ctx, id))
+
+	testcases := []struct {
+		name            string
+		returnID        uuid.UUID
+		amount          money.Money
+		expectedError   error
+		expectedBalance domain.Balance
+		expectedEvent   bool
+	}{
+		{
+			name:     `partial refund`,
+			returnID: uuid.MustParse(`6f0a3a5e-2b8e-4c47-9a4e-7d1f0c2b5a11`),
+			amount:   money.New(decimal.NewFromInt32(5), `USD`),
+			expectedBalance: domain.Balance{
+				Amount:   money.New(decimal.NewFromInt32(85), `USD`),
+				Reserved: money.Zero(`USD`),
+			},
+			expectedEvent: true,
+		},
+		{
+			name:          `redelivered refund`,
+			returnID:      uuid.MustParse(`6f0a3a5e-2b8e-4c47-9a4e-7d1f0c2b5a11`),
+			amount:        money.New(decimal.NewFromInt32(5), `USD`),
+			expectedError: domain.ErrRefundApplied,
+			expectedBalance: domain.Balance{
+				Amount:   money.New(decimal.NewFromInt32(85), `USD`),
+				Reserved: money.Zero(`USD`),
+			},
+		},
+		{
+			name:     `refund exceeds payment`,
+			returnID: uuid.MustParse(`b3c1e9d2-7a64-4f0e-8b25-1e9d4c6a7f32`),
+			amount:   money.New(decimal.NewFromInt32(50), `USD`),
+			expectedBalance: domain.Balance{
+				Amount:   money.New(decimal.NewFromInt32(100), `USD`),
+				Reserved: money.Zero(`USD`),
+			},
+			expectedEvent: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := PersistTransaction(ctx, newMessage(), customerID, rates, domain.Refund{OrderID: orderID, ReturnID: tc.returnID, Amount: tc.amount})
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			checkBalance(ctx, t, customerID, tc.expectedBalance)
+
+			if tc.expectedEvent {
+				id, event, err := getEvent(ctx)
 				require.NoError(t, err)
+				require.Equal(t, schema.PaymentsEvent{
+					Event:      schema.Event{Type: schema.PaymentsRefunded},
+					OrderID:    orderID,
+					PaymentsID: payment.GetID(),
+					ReturnID:   tc.returnID,
+				}, event)
+				require.NoError(t, EventLog().Ack(ctx, id))
 			}
 		})
 	}
 }
 
+func redeliveredPayments(ctx context.Context, t *testing.T) {
+	customerID, orderID := uuid.New(), uuid.New()
+	_, err := pool.Exec(ctx, `INSERT INTO balances(customer_id, available_amount) VALUES ($1, $2)`, customerID, decimal.NewFromInt32(100))
+	require.NoError(t, err)
+
+	command := map[string]any{outbox.EventIDKey: `orders/` + uuid.NewString()}
+	for key, value := range (schema.OrderEvent{
+		Event:      schema.Event{Type: schema.NewOrder},
+		OrderID:    orderID,
+		CustomerID: customerID,
+		Price:      money.New(decimal.NewFromInt32(20), `USD`),
+	}).Map() {
+		command[key] = value
+	}
+	reserve := domain.Reserve{OrderID: orderID, Amount: money.New(decimal.NewFromInt32(20), `USD`)}
+
+	msg := inbox.MessageOf(saga.CommandStream, uuid.NewString(), command)
+	_, err = PersistTransaction(ctx, msg, customerID, rates, reserve)
+	require.NoError(t, err)
+
+	// consumer crashed after payment was persisted, but before message was acknowledged.
+	_, err = PersistTransaction(ctx, msg, customerID, rates, reserve)
+	require.ErrorIs(t, err, ErrDuplicateMessage)
+
+	// relay crashed after command was published, but before offset was submitted,
+	// so command is published again as new message of stream.
+	msg = inbox.MessageOf(saga.CommandStream, uuid.NewString(), command)
+	_, err = PersistTransaction(ctx, msg, customerID, rates, reserve)
+	require.ErrorIs(t, err, ErrDuplicateMessage)
+
+	checkBalance(ctx, t, customerID, domain.Balance{
+		Amount:   money.New(decimal.NewFromInt32(80), `USD`),
+		Reserved: money.New(decimal.NewFromInt32(20), `USD`),
+	})
+	var payments int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT count(*) FROM payments WHERE order_id = $1`, orderID).Scan(&payments))
+	require.Equal(t, 1, payments)
+}
+
+func newMessage() inbox.Message {
+	return inbox.Message{Stream: saga.CommandStream, ID: uuid.NewString()}
+}
+
 func checkBalance(ctx context.Context, t *testing.T, customerID uuid.UUID, expectedBalance domain.Balance) {
 	var balance domain.Balance
 	pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) (err error) {
@@ -311,3 +498,12 @@ func checkBalance(ctx context.Context, t *testing.T, customerID uuid.UUID, expec
 	require.Equal(t, expectedBalance.Amount.String(), balance.Amount.String())
 	require.Equal(t, expectedBalance.Reserved.String(), balance.Reserved.String())
 }
+
+// getEvent returns the oldest not acknowledged event of log.
+func getEvent(ctx context.Context) (int, schema.PaymentsEvent, error) {
+	entries, err := EventLog().Read(ctx, 1)
+	if err != nil {
+		return 0, schema.PaymentsEvent{}, err
+	}
+	return entries[0].Offset, entries[0].Event, nil
+}
@@ -2,61 +2,42 @@ package service
 
 import (
 	"context"
-	"errors"
-	"log"
-	"time"
 
-	"github.com/cenkalti/backoff/v4"
 	"github.com/google/uuid"
 	"github.com/moeryomenko/saga/internal/payment/domain"
 	"github.com/moeryomenko/saga/internal/payment/infrastructure/eventhandler"
 	"github.com/moeryomenko/saga/internal/payment/infrastructure/repository"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/inbox"
+	"github.com/moeryomenko/saga/pkg/money"
+	"github.com/moeryomenko/saga/pkg/outbox"
 )
 
-func HandlePayments(ctx context.Context, customerID uuid.UUID, event domain.Event) error {
-	_, err := repository.PersistTransaction(ctx, customerID, event)
-	switch {
-	case err == nil:
-		return nil
-	case errors.Is(err, domain.ErrDomain):
-		return nil
-	default:
-		return err
+// HandlePayments returns handler of payment events, rates convert
+// payments in other currency than customer balance one.
+func HandlePayments(rates money.Rates) eventhandler.EventHandler {
+	return func(ctx context.Context, msg inbox.Message, customerID uuid.UUID, event domain.Event) error {
+		_, err := repository.PersistTransaction(ctx, msg, customerID, rates, event)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, domain.ErrDomain):
+			return nil
+		case errors.Is(err, repository.ErrDuplicateMessage):
+			// message was redelivered.
+			return nil
+		default:
+			return err
+		}
 	}
 }
 
-func Producer(pollPeriod time.Duration) func(ctx context.Context) error {
-	return func(ctx context.Context) error {
-		eventPollTicker := time.NewTicker(pollPeriod)
-		defer eventPollTicker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return nil
-			case <-eventPollTicker.C:
-				id, order, err := repository.GetEvent(ctx)
-				switch err {
-				case nil:
-				case repository.ErrNoEvents:
-					continue
-				default:
-					log.Println(err)
-					return err
-				}
-
-				err = backoff.Retry(func() error {
-					return eventhandler.Produce(ctx, order)
-				}, backoff.NewExponentialBackOff())
-				if err != nil {
-					return err
-				}
+// Producer relays replies from event log to saga orchestrator.
+func Producer(cfg outbox.RelayConfig) func(ctx context.Context) error {
+	return repository.EventLog().Relay(cfg, eventhandler.Publisher())
+}
 
-				err = repository.Ack(ctx, id)
-				if err != nil {
-					return err
-				}
-			}
-		}
-	}
+// PurgeEvents periodically removes published events from event log.
+func PurgeEvents(cfg outbox.RetentionConfig) func(ctx context.Context) error {
+	return repository.EventLog().Retain(cfg)
 }
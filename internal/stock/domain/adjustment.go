@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// AdjustmentReason is reason code of manual change of on-hand quantity.
+type AdjustmentReason string
+
+const (
+	Restock    AdjustmentReason = `restock`
+	Damaged    AdjustmentReason = `damaged`
+	Lost       AdjustmentReason = `lost`
+	Found      AdjustmentReason = `found`
+	Correction AdjustmentReason = `correction`
+)
+
+func (r AdjustmentReason) Valid() bool {
+	switch r {
+	case Restock, Damaged, Lost, Found, Correction:
+		return true
+	default:
+		return false
+	}
+}
+
+// Adjustment is audit record of manual change of product stock.
+type Adjustment struct {
+	ID        int64
+	SKU       string
+	Warehouse string
+	Delta     int
+	Reason    AdjustmentReason
+	Note      string
+	// OnHand is on-hand quantity in warehouse after adjustment.
+	OnHand    int
+	CreatedAt time.Time
+}
@@ -0,0 +1,96 @@
+package domain
+
+import "sort"
+
+// AllocationStrategy chooses warehouses which fulfil ordered items.
+type AllocationStrategy string
+
+const (
+	// SingleLocation prefers the nearest warehouse, which has all ordered items,
+	// otherwise items are split between warehouses as by Nearest.
+	SingleLocation AllocationStrategy = `single_location`
+	// Nearest takes items from the nearest warehouses.
+	Nearest AllocationStrategy = `nearest`
+	// LowestStock takes items from warehouses with the lowest available stock first,
+	// so small leftovers are cleared out.
+	LowestStock AllocationStrategy = `lowest_stock`
+)
+
+func (s AllocationStrategy) Valid() bool {
+	switch s {
+	case SingleLocation, Nearest, LowestStock:
+		return true
+	default:
+		return false
+	}
+}
+
+// allocate splits available items by warehouses, result has an item per product and warehouse.
+func (s AllocationStrategy) allocate(inventory Inventory, items []Item) []Item {
+	if s == SingleLocation {
+		if warehouse, ok := singleLocation(inventory, items); ok {
+			allocation := make([]Item, 0, len(items))
+			for _, item := range items {
+				allocation = append(allocation, Item{SKU: item.SKU, Quantity: item.Quantity, Warehouse: warehouse})
+			}
+			return allocation
+		}
+	}
+
+	var allocation []Item
+	for _, item := range items {
+		remaining := item.Quantity
+		for _, location := range s.sort(inventory[item.SKU].Locations) {
+			quantity := location.Available()
+			if quantity <= 0 {
+				continue
+			}
+			if quantity > remaining {
+				quantity = remaining
+			}
+			allocation = append(allocation, Item{SKU: item.SKU, Quantity: quantity, Warehouse: location.Warehouse})
+			remaining -= quantity
+			if remaining == 0 {
+				break
+			}
+		}
+	}
+	return allocation
+}
+
+// sort orders locations by preference of strategy, the nearest ones go first on tie.
+func (s AllocationStrategy) sort(locations []Location) []Location {
+	sorted := append([]Location{}, locations...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if s == LowestStock && sorted[i].Available() != sorted[j].Available() {
+			return sorted[i].Available() < sorted[j].Available()
+		}
+		if sorted[i].Distance != sorted[j].Distance {
+			return sorted[i].Distance < sorted[j].Distance
+		}
+		return sorted[i].Warehouse < sorted[j].Warehouse
+	})
+	return sorted
+}
+
+// singleLocation returns the nearest warehouse, which has all items.
+func singleLocation(inventory Inventory, items []Item) (string, bool) {
+	if len(items) == 0 {
+		return ``, false
+	}
+
+	for _, candidate := range Nearest.sort(inventory[items[0].SKU].Locations) {
+		fulfils := true
+		for _, item := range items {
+			location, _ := inventory[item.SKU].location(candidate.Warehouse)
+			if location.Available() < item.Quantity {
+				fulfils = false
+				break
+			}
+		}
+		if fulfils {
+			return candidate.Warehouse, true
+		}
+	}
+	return ``, false
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "errors"
+
+var (
+	ErrDomain = errors.New(`domain`)
+
+	ErrCantStock      = errors.New(`cant stock order`)
+	ErrCancelStock    = errors.New(`cancel committed stock`)
+	ErrCommitStock    = errors.New(`commit not reserved stock`)
+	ErrRestockApplied = errors.New(`restock already applied`)
+
+	ErrUnknownProduct      = errors.New(`unknown product`)
+	ErrProductExists       = errors.New(`product already exists`)
+	ErrInvalidAdjustment   = errors.New(`invalid stock adjustment`)
+	ErrInsufficientStock   = errors.New(`on-hand quantity can't be less than reserved`)
+	ErrReservationNotFound = errors.New(`reservation not found`)
+	ErrUnknownWarehouse    = errors.New(`unknown warehouse`)
+	ErrWarehouseExists     = errors.New(`warehouse already exists`)
+)
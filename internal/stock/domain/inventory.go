@@ -0,0 +1,229 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// DefaultWarehouse receives returned items, which origin is unknown.
+const DefaultWarehouse = `default`
+
+// Warehouse is location of stock.
+type Warehouse struct {
+	ID   string
+	Name string
+	// Distance is distance of warehouse to customers, nearer warehouses are preferred by allocation.
+	Distance int
+}
+
+// Location is stock of product in warehouse.
+type Location struct {
+	Warehouse string
+	Distance  int
+	OnHand    int
+	Reserved  int
+}
+
+// Available returns number of units which can be reserved.
+func (l Location) Available() int {
+	return l.OnHand - l.Reserved
+}
+
+// Product is stock of product in warehouses, reserved units are held by not
+// completed orders. On-hand and reserved quantities are totals of its locations.
+type Product struct {
+	SKU       string
+	Name      string
+	OnHand    int
+	Reserved  int
+	Locations []Location
+}
+
+// NewProduct returns product stocked in locations.
+func NewProduct(sku, name string, locations []Location) Product {
+	return Product{SKU: sku, Name: name, Locations: locations}.total()
+}
+
+// Available returns number of units which can be reserved.
+func (p Product) Available() int {
+	return p.OnHand - p.Reserved
+}
+
+// Adjust changes on-hand quantity of product in warehouse by reason, reserved units can't be taken.
+func (p Product) Adjust(warehouse string, delta int, reason AdjustmentReason) (Product, error) {
+	location, _ := p.location(warehouse)
+	switch {
+	case !reason.Valid():
+		return p, ErrInvalidAdjustment
+	case delta == 0:
+		return p, ErrInvalidAdjustment
+	case reason == Restock && delta < 0:
+		return p, ErrInvalidAdjustment
+	case location.OnHand+delta < location.Reserved:
+		return p, ErrInsufficientStock
+	}
+	return p.update(warehouse, func(location *Location) {
+		location.OnHand += delta
+	}), nil
+}
+
+func (p Product) location(warehouse string) (Location, int) {
+	for i, location := range p.Locations {
+		if location.Warehouse == warehouse {
+			return location, i
+		}
+	}
+	return Location{Warehouse: warehouse}, -1
+}
+
+// update applies change to location of product in warehouse and recounts totals.
+func (p Product) update(warehouse string, apply func(*Location)) Product {
+	location, i := p.location(warehouse)
+	apply(&location)
+
+	p.Locations = append([]Location{}, p.Locations...)
+	if i < 0 {
+		p.Locations = append(p.Locations, location)
+	} else {
+		p.Locations[i] = location
+	}
+	return p.total()
+}
+
+func (p Product) total() Product {
+	p.OnHand, p.Reserved = 0, 0
+	for _, location := range p.Locations {
+		p.OnHand += location.OnHand
+		p.Reserved += location.Reserved
+	}
+	return p
+}
+
+// Inventory is stock of products by SKU.
+type Inventory map[string]Product
+
+// Reserve reserves all items of order or none of them, order is rejected
+// with reason if any item is unknown or not available in ordered quantity.
+// Items are allocated to warehouses by strategy.
+func (i Inventory) Reserve(orderID uuid.UUID, items []Item, strategy AllocationStrategy) (Inventory, Stock) {
+	items = mergeItems(items)
+	for _, item := range items {
+		product, ok := i[item.SKU]
+		switch {
+		case !ok:
+			return i, RejectedStock{OrderID: orderID, Reason: fmt.Sprintf(`unknown product %s`, item.SKU)}
+		case item.Quantity <= 0:
+			return i, RejectedStock{OrderID: orderID, Reason: fmt.Sprintf(`invalid quantity of %s`, item.SKU)}
+		case product.Available() < item.Quantity:
+			return i, RejectedStock{OrderID: orderID, Reason: fmt.Sprintf(`insufficient stock of %s`, item.SKU)}
+		}
+	}
+
+	allocation := strategy.allocate(i, items)
+	return i.update(allocation, func(location *Location, quantity int) {
+		location.Reserved += quantity
+	}), ActiveStock{
+		ID:      uuid.New(),
+		OrderID: orderID,
+		Items:   allocation,
+	}
+}
+
+func (i Inventory) release(items []Item) Inventory {
+	return i.update(items, func(location *Location, quantity int) {
+		location.Reserved -= quantity
+	})
+}
+
+func (i Inventory) commit(items []Item) Inventory {
+	return i.update(items, func(location *Location, quantity int) {
+		location.Reserved -= quantity
+		location.OnHand -= quantity
+	})
+}
+
+func (i Inventory) restock(items []Item) Inventory {
+	return i.update(items, func(location *Location, quantity int) {
+		location.OnHand += quantity
+	})
+}
+
+func (i Inventory) update(items []Item, apply func(*Location, int)) Inventory {
+	updated := i.copy()
+	for _, item := range items {
+		product, ok := updated[item.SKU]
+		if !ok {
+			product = Product{SKU: item.SKU}
+		}
+		updated[item.SKU] = product.update(item.Warehouse, func(location *Location) {
+			apply(location, item.Quantity)
+		})
+	}
+	return updated
+}
+
+func (i Inventory) copy() Inventory {
+	inventory := make(Inventory, len(i))
+	for sku, product := range i {
+		inventory[sku] = product
+	}
+	return inventory
+}
+
+// mergeItems sums quantities of the same products.
+func mergeItems(items []Item) []Item {
+	merged := make([]Item, 0, len(items))
+	positions := make(map[string]int, len(items))
+	for _, item := range items {
+		if i, ok := positions[item.SKU]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		positions[item.SKU] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged
+}
+
+// returnedItems sends returned items to warehouses, which fulfilled them. Quantity is split
+// across allocations of product, units above allocated ones are sent to default warehouse.
+func returnedItems(stock Stock, items []Item) []Item {
+	var fulfilled []Item
+	switch stock := stock.(type) {
+	case ActiveStock:
+		fulfilled = stock.Items
+	case CommittedStock:
+		fulfilled = stock.Items
+	}
+
+	// remaining is quantity of allocations, which isn't returned yet.
+	remaining := make([]int, len(fulfilled))
+	for i, origin := range fulfilled {
+		remaining[i] = origin.Quantity
+	}
+
+	returned := make([]Item, 0, len(items))
+	for _, item := range items {
+		quantity := item.Quantity
+		for i, origin := range fulfilled {
+			if quantity == 0 {
+				break
+			}
+			if origin.SKU != item.SKU || remaining[i] == 0 {
+				continue
+			}
+			part := quantity
+			if part > remaining[i] {
+				part = remaining[i]
+			}
+			remaining[i] -= part
+			quantity -= part
+			returned = append(returned, Item{SKU: item.SKU, Quantity: part, Warehouse: origin.Warehouse})
+		}
+		if quantity > 0 {
+			returned = append(returned, Item{SKU: item.SKU, Quantity: quantity, Warehouse: DefaultWarehouse})
+		}
+	}
+	return returned
+}
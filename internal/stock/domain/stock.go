@@ -1,34 +1,108 @@
 package domain
 
 import (
-	"errors"
-
 	"github.com/google/uuid"
 )
 
 type Event any
 
+// Item represents ordered product, warehouse is set for allocated items.
+type Item struct {
+	SKU       string
+	Quantity  int
+	Warehouse string
+}
+
 type StockOrder struct {
 	OrderID uuid.UUID
-	Items   []string
+	Items   []Item
 }
 
+// CancelStock releases reservation of canceled order.
 type CancelStock struct {
 	OrderID uuid.UUID
 }
 
-func Apply(event Event) (Stock, error) {
-	switch event := event.(type) {
+// CommitStock commits reservation of completed order.
+type CommitStock struct {
+	OrderID uuid.UUID
+}
+
+// ReturnStock restocks items returned by customer after order completion.
+type ReturnStock struct {
+	OrderID  uuid.UUID
+	ReturnID uuid.UUID
+	Items    []Item
+}
+
+// Tx is event applied to stock of order, stock is nil if order wasn't stocked yet.
+// Strategy allocates items of stocked order to warehouses.
+type Tx struct {
+	Stock    Stock
+	Event    Event
+	Strategy AllocationStrategy
+}
+
+// Transaction applies event to stock of order and inventory of its items.
+// Events may be redelivered, so repeated event leaves stock as is.
+func (i Inventory) Transaction(tx Tx) (Inventory, Stock, error) {
+	switch event := tx.Event.(type) {
 	case StockOrder:
-		return CreateStock(event.OrderID, event.Items), nil
+		if tx.Stock != nil {
+			return i, tx.Stock, nil
+		}
+		inventory, stock := i.Reserve(event.OrderID, event.Items, tx.Strategy)
+		return inventory, stock, nil
 	case CancelStock:
-		return CanceledStock(event), nil
+		stock, err := ReleaseStock(tx.Stock, event.OrderID)
+		if err != nil {
+			return i, tx.Stock, err
+		}
+		if active, ok := tx.Stock.(ActiveStock); ok {
+			return i.release(active.Items), stock, nil
+		}
+		return i, stock, nil
+	case CommitStock:
+		stock, err := CommitReservation(tx.Stock)
+		if err != nil {
+			return i, tx.Stock, err
+		}
+		if active, ok := tx.Stock.(ActiveStock); ok {
+			return i.commit(active.Items), stock, nil
+		}
+		return i, stock, nil
+	case ReturnStock:
+		event.Items = returnedItems(tx.Stock, event.Items)
+		return i.restock(event.Items), ReturnedStock(event), nil
 	default:
 		panic(`bug: invalid domain event`)
 	}
 }
 
-var ErrCantStock = errors.New(`cant stock order`)
+// ReleaseStock releases reserved stock, order canceled before it was
+// stocked is remembered as canceled, so late stock command reserves nothing.
+func ReleaseStock(stock Stock, orderID uuid.UUID) (Stock, error) {
+	switch stock := stock.(type) {
+	case nil, ActiveStock:
+		return CanceledStock{OrderID: orderID}, nil
+	case CanceledStock, RejectedStock:
+		return stock, nil
+	default:
+		return stock, ErrCancelStock
+	}
+}
+
+// CommitReservation commits reserved stock of completed order.
+func CommitReservation(stock Stock) (Stock, error) {
+	switch stock := stock.(type) {
+	case ActiveStock:
+		return CommittedStock{ActiveStock: stock}, nil
+	case CommittedStock:
+		return stock, nil
+	default:
+		return stock, ErrCommitStock
+	}
+}
 
 type Stock interface {
 	GetOrderID() uuid.UUID
@@ -42,8 +116,26 @@ func (s CanceledStock) GetOrderID() uuid.UUID {
 	return s.OrderID
 }
 
+// ReturnedStock is stock of returned order items.
+type ReturnedStock struct {
+	OrderID  uuid.UUID
+	ReturnID uuid.UUID
+	Items    []Item
+}
+
+func (s ReturnedStock) GetOrderID() uuid.UUID {
+	return s.OrderID
+}
+
+// CommittedStock is reserved stock of completed order.
+type CommittedStock struct {
+	ActiveStock
+}
+
+// RejectedStock is order which items couldn't be reserved by reason.
 type RejectedStock struct {
 	OrderID uuid.UUID
+	Reason  string
 }
 
 func (s RejectedStock) GetOrderID() uuid.UUID {
@@ -53,25 +145,9 @@ func (s RejectedStock) GetOrderID() uuid.UUID {
 type ActiveStock struct {
 	ID      uuid.UUID
 	OrderID uuid.UUID
-	Items   []string
+	Items   []Item
 }
 
 func (s ActiveStock) GetOrderID() uuid.UUID {
 	return s.OrderID
 }
-
-func CreateStock(orderID uuid.UUID, items []string) Stock {
-	// dummy stock domain logic.
-	if len(items) > 10 {
-		return RejectedStock{OrderID: orderID}
-	}
-
-	stock := ActiveStock{
-		ID:      uuid.New(),
-		OrderID: orderID,
-	}
-
-	copy(stock.Items, items)
-
-	return stock
-}
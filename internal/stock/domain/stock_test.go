@@ -0,0 +1,302 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction(t *testing.T) {
+	orderID, returnID := uuid.New(), uuid.New()
+	active := ActiveStock{ID: uuid.New(), OrderID: orderID, Items: []Item{{SKU: `test`, Quantity: 1, Warehouse: `near`}}}
+	inventory := Inventory{`test`: product(`test`, Location{Warehouse: `near`, OnHand: 3, Reserved: 1})}
+
+	testcases := map[string]struct {
+		stock             Stock
+		event             Event
+		expectedStock     Stock
+		expectedInventory Inventory
+		expectedErr       error
+	}{
+		`stock order`: {
+			event:             StockOrder{OrderID: orderID, Items: []Item{{SKU: `test`, Quantity: 1}, {SKU: `test`, Quantity: 1}}},
+			expectedStock:     ActiveStock{OrderID: orderID, Items: []Item{{SKU: `test`, Quantity: 2, Warehouse: `near`}}},
+			expectedInventory: Inventory{`test`: product(`test`, Location{Warehouse: `near`, OnHand: 3, Reserved: 3})},
+		},
+		`insufficient stock`: {
+			event:             StockOrder{OrderID: orderID, Items: []Item{{SKU: `test`, Quantity: 3}}},
+			expectedStock:     RejectedStock{OrderID: orderID, Reason: `insufficient stock of test`},
+			expectedInventory: inventory,
+		},
+		`unknown product`: {
+			event:             StockOrder{OrderID: orderID, Items: []Item{{SKU: `test`, Quantity: 1}, {SKU: `unknown`, Quantity: 1}}},
+			expectedStock:     RejectedStock{OrderID: orderID, Reason: `unknown product unknown`},
+			expectedInventory: inventory,
+		},
+		`redelivered stock order`: {
+			stock:         active,
+			event:         StockOrder{OrderID: orderID, Items: []Item{{SKU: `test`, Quantity: 1}}},
+			expectedStock: active,
+		},
+		`stock canceled order`: {
+			stock:         CanceledStock{OrderID: orderID},
+			event:         StockOrder{OrderID: orderID, Items: []Item{{SKU: `test`, Quantity: 1}}},
+			expectedStock: CanceledStock{OrderID: orderID},
+		},
+		`release reserved stock`: {
+			stock:             active,
+			event:             CancelStock{OrderID: orderID},
+			expectedStock:     CanceledStock{OrderID: orderID},
+			expectedInventory: Inventory{`test`: product(`test`, Location{Warehouse: `near`, OnHand: 3, Reserved: 0})},
+		},
+		`release not stocked order`: {
+			event:         CancelStock{OrderID: orderID},
+			expectedStock: CanceledStock{OrderID: orderID},
+		},
+		`redelivered release`: {
+			stock:         CanceledStock{OrderID: orderID},
+			event:         CancelStock{OrderID: orderID},
+			expectedStock: CanceledStock{OrderID: orderID},
+		},
+		`release rejected stock`: {
+			stock:         RejectedStock{OrderID: orderID},
+			event:         CancelStock{OrderID: orderID},
+			expectedStock: RejectedStock{OrderID: orderID},
+		},
+		`release committed stock`: {
+			stock:       CommittedStock{ActiveStock: active},
+			event:       CancelStock{OrderID: orderID},
+			expectedErr: ErrCancelStock,
+		},
+		`commit reserved stock`: {
+			stock:             active,
+			event:             CommitStock{OrderID: orderID},
+			expectedStock:     CommittedStock{ActiveStock: active},
+			expectedInventory: Inventory{`test`: product(`test`, Location{Warehouse: `near`, OnHand: 2, Reserved: 0})},
+		},
+		`redelivered commit`: {
+			stock:         CommittedStock{ActiveStock: active},
+			event:         CommitStock{OrderID: orderID},
+			expectedStock: CommittedStock{ActiveStock: active},
+		},
+		`commit released stock`: {
+			stock:       CanceledStock{OrderID: orderID},
+			event:       CommitStock{OrderID: orderID},
+			expectedErr: ErrCommitStock,
+		},
+		`restock returned items`: {
+			stock: CommittedStock{ActiveStock: active},
+			event: ReturnStock{OrderID: orderID, ReturnID: returnID, Items: []Item{{SKU: `test`, Quantity: 1}, {SKU: `new`, Quantity: 1}}},
+			expectedStock: ReturnedStock{OrderID: orderID, ReturnID: returnID, Items: []Item{
+				{SKU: `test`, Quantity: 1, Warehouse: `near`},
+				{SKU: `new`, Quantity: 1, Warehouse: DefaultWarehouse},
+			}},
+			expectedInventory: Inventory{
+				`test`: product(`test`, Location{Warehouse: `near`, OnHand: 4, Reserved: 1}),
+				`new`:  product(`new`, Location{Warehouse: DefaultWarehouse, OnHand: 1}),
+			},
+		},
+		`restock items to warehouses of allocations`: {
+			stock: CommittedStock{ActiveStock: ActiveStock{ID: active.ID, OrderID: orderID, Items: []Item{
+				{SKU: `test`, Quantity: 1, Warehouse: `near`},
+				{SKU: `test`, Quantity: 2, Warehouse: `far`},
+			}}},
+			event: ReturnStock{OrderID: orderID, ReturnID: returnID, Items: []Item{{SKU: `test`, Quantity: 4}}},
+			expectedStock: ReturnedStock{OrderID: orderID, ReturnID: returnID, Items: []Item{
+				{SKU: `test`, Quantity: 1, Warehouse: `near`},
+				{SKU: `test`, Quantity: 2, Warehouse: `far`},
+				{SKU: `test`, Quantity: 1, Warehouse: DefaultWarehouse},
+			}},
+			expectedInventory: Inventory{
+				`test`: product(`test`,
+					Location{Warehouse: `near`, OnHand: 4, Reserved: 1},
+					Location{Warehouse: `far`, OnHand: 2},
+					Location{Warehouse: DefaultWarehouse, OnHand: 1},
+				),
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			updated, stock, err := inventory.Transaction(Tx{Stock: tc.stock, Event: tc.event})
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				require.Equal(t, inventory, updated)
+				return
+			}
+			require.NoError(t, err)
+			if active, ok := stock.(ActiveStock); ok && tc.stock == nil {
+				// reservation ID is generated.
+				require.NotEqual(t, uuid.UUID{}, active.ID)
+				active.ID = uuid.UUID{}
+				stock = active
+			}
+			require.Equal(t, tc.expectedStock, stock)
+			if tc.expectedInventory == nil {
+				tc.expectedInventory = inventory
+			}
+			require.Equal(t, tc.expectedInventory, updated)
+			require.Equal(t, Inventory{`test`: product(`test`, Location{Warehouse: `near`, OnHand: 3, Reserved: 1})}, inventory)
+		})
+	}
+}
+
+func TestProductAdjust(t *testing.T) {
+	stocked := product(`test`, Location{Warehouse: `near`, OnHand: 5, Reserved: 2})
+
+	testcases := map[string]struct {
+		warehouse       string
+		delta           int
+		reason          AdjustmentReason
+		expectedProduct Product
+		expectedErr     error
+	}{
+		`restock`: {
+			warehouse:       `near`,
+			delta:           3,
+			reason:          Restock,
+			expectedProduct: product(`test`, Location{Warehouse: `near`, OnHand: 8, Reserved: 2}),
+		},
+		`restock new warehouse`: {
+			warehouse: `far`,
+			delta:     3,
+			reason:    Restock,
+			expectedProduct: product(`test`,
+				Location{Warehouse: `near`, OnHand: 5, Reserved: 2},
+				Location{Warehouse: `far`, OnHand: 3},
+			),
+		},
+		`damaged units`: {
+			warehouse:       `near`,
+			delta:           -3,
+			reason:          Damaged,
+			expectedProduct: product(`test`, Location{Warehouse: `near`, OnHand: 2, Reserved: 2}),
+		},
+		`take reserved units`: {
+			warehouse:   `near`,
+			delta:       -4,
+			reason:      Lost,
+			expectedErr: ErrInsufficientStock,
+		},
+		`take units of empty warehouse`: {
+			warehouse:   `far`,
+			delta:       -1,
+			reason:      Lost,
+			expectedErr: ErrInsufficientStock,
+		},
+		`negative restock`: {
+			warehouse:   `near`,
+			delta:       -1,
+			reason:      Restock,
+			expectedErr: ErrInvalidAdjustment,
+		},
+		`zero delta`: {
+			reason:      Correction,
+			expectedErr: ErrInvalidAdjustment,
+		},
+		`unknown reason`: {
+			delta:       1,
+			reason:      `unknown`,
+			expectedErr: ErrInvalidAdjustment,
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			adjusted, err := stocked.Adjust(tc.warehouse, tc.delta, tc.reason)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedProduct, adjusted)
+		})
+	}
+}
+
+func TestAllocationStrategy(t *testing.T) {
+	orderID := uuid.New()
+	inventory := Inventory{
+		`a`: product(`a`,
+			Location{Warehouse: `near`, Distance: 1, OnHand: 5, Reserved: 1},
+			Location{Warehouse: `far`, Distance: 10, OnHand: 2},
+		),
+		`b`: product(`b`,
+			Location{Warehouse: `near`, Distance: 1, OnHand: 1},
+			Location{Warehouse: `far`, Distance: 10, OnHand: 4},
+		),
+	}
+
+	testcases := map[string]struct {
+		strategy      AllocationStrategy
+		items         []Item
+		expectedItems []Item
+	}{
+		`single location`: {
+			strategy:      SingleLocation,
+			items:         []Item{{SKU: `a`, Quantity: 2}, {SKU: `b`, Quantity: 2}},
+			expectedItems: []Item{{SKU: `a`, Quantity: 2, Warehouse: `far`}, {SKU: `b`, Quantity: 2, Warehouse: `far`}},
+		},
+		`single location prefers nearest`: {
+			strategy:      SingleLocation,
+			items:         []Item{{SKU: `a`, Quantity: 1}, {SKU: `b`, Quantity: 1}},
+			expectedItems: []Item{{SKU: `a`, Quantity: 1, Warehouse: `near`}, {SKU: `b`, Quantity: 1, Warehouse: `near`}},
+		},
+		`split without single location`: {
+			strategy: SingleLocation,
+			items:    []Item{{SKU: `a`, Quantity: 5}, {SKU: `b`, Quantity: 1}},
+			expectedItems: []Item{
+				{SKU: `a`, Quantity: 4, Warehouse: `near`},
+				{SKU: `a`, Quantity: 1, Warehouse: `far`},
+				{SKU: `b`, Quantity: 1, Warehouse: `near`},
+			},
+		},
+		`nearest`: {
+			strategy: Nearest,
+			items:    []Item{{SKU: `a`, Quantity: 2}, {SKU: `b`, Quantity: 2}},
+			expectedItems: []Item{
+				{SKU: `a`, Quantity: 2, Warehouse: `near`},
+				{SKU: `b`, Quantity: 1, Warehouse: `near`},
+				{SKU: `b`, Quantity: 1, Warehouse: `far`},
+			},
+		},
+		`lowest stock`: {
+			strategy: LowestStock,
+			items:    []Item{{SKU: `a`, Quantity: 3}, {SKU: `b`, Quantity: 1}},
+			expectedItems: []Item{
+				{SKU: `a`, Quantity: 2, Warehouse: `far`},
+				{SKU: `a`, Quantity: 1, Warehouse: `near`},
+				{SKU: `b`, Quantity: 1, Warehouse: `near`},
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			reserved, stock := inventory.Reserve(orderID, tc.items, tc.strategy)
+			require.IsType(t, ActiveStock{}, stock)
+			require.Equal(t, tc.expectedItems, stock.(ActiveStock).Items)
+
+			for _, item := range tc.items {
+				require.Equal(t, inventory[item.SKU].Reserved+item.Quantity, reserved[item.SKU].Reserved)
+			}
+			require.Equal(t, released(t, reserved, stock.(ActiveStock)), inventory)
+		})
+	}
+}
+
+// released releases reservation of active stock.
+func released(t *testing.T, inventory Inventory, stock ActiveStock) Inventory {
+	released, _, err := inventory.Transaction(Tx{Stock: stock, Event: CancelStock{OrderID: stock.OrderID}})
+	require.NoError(t, err)
+	return released
+}
+
+func product(sku string, locations ...Location) Product {
+	return NewProduct(sku, ``, locations)
+}
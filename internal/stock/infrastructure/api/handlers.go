@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
+
+	"github.com/moeryomenko/saga/internal/stock/config"
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/internal/stock/service"
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+func New(cfg *config.Config) *http.Server {
+	return &http.Server{
+		ReadHeaderTimeout: 1 * time.Minute,
+		Handler:           Handler(RestController{}),
+		Addr:              cfg.Addr(),
+	}
+}
+
+type RestController struct{}
+
+func (RestController) GetProducts(w http.ResponseWriter, r *http.Request) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.ListProducts(ctx)
+	}), WithResponseMapper(mapProducts))
+}
+
+func (RestController) PostProducts(w http.ResponseWriter, r *http.Request) {
+	var product CreateProduct
+	handlerDecorator(w, r, WithRequestBody(&product), WithOperation(func(ctx context.Context) (any, error) {
+		return service.CreateProduct(ctx, *product.Sku, stringOrEmpty(product.Name))
+	}), WithResponseMapper(mapProduct), WithErrorMapper(mapInventoryError), WithDefaultStatus(http.StatusCreated))
+}
+
+func (RestController) GetProductsSku(w http.ResponseWriter, r *http.Request, sku SKU) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.GetProduct(ctx, sku)
+	}), WithResponseMapper(mapProduct), WithErrorMapper(mapInventoryError))
+}
+
+func (RestController) PostProductsSkuRestock(w http.ResponseWriter, r *http.Request, sku SKU) {
+	var restock Restock
+	handlerDecorator(w, r, WithRequestBody(&restock), WithOperation(func(ctx context.Context) (any, error) {
+		return service.Restock(ctx, sku, warehouseOrDefault(restock.Warehouse), *restock.Quantity, stringOrEmpty(restock.Note))
+	}), WithResponseMapper(mapProduct), WithErrorMapper(mapInventoryError))
+}
+
+func (RestController) GetProductsSkuAdjustments(w http.ResponseWriter, r *http.Request, sku SKU) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.ListAdjustments(ctx, sku)
+	}), WithResponseMapper(mapAdjustments), WithErrorMapper(mapInventoryError))
+}
+
+func (RestController) PostProductsSkuAdjustments(w http.ResponseWriter, r *http.Request, sku SKU) {
+	var adjustment AdjustStock
+	handlerDecorator(w, r, WithRequestBody(&adjustment), WithOperation(func(ctx context.Context) (any, error) {
+		return service.AdjustStock(ctx, sku, warehouseOrDefault(adjustment.Warehouse), *adjustment.Delta,
+			domain.AdjustmentReason(*adjustment.Reason), stringOrEmpty(adjustment.Note))
+	}), WithResponseMapper(mapProduct), WithErrorMapper(mapInventoryError))
+}
+
+func (RestController) GetReservationsOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.GetReservation(ctx, orderID)
+	}), WithResponseMapper(mapReservation), WithErrorMapper(mapInventoryError))
+}
+
+func (RestController) GetWarehouses(w http.ResponseWriter, r *http.Request) {
+	handlerDecorator(w, r, WithOperation(func(ctx context.Context) (any, error) {
+		return service.ListWarehouses(ctx)
+	}), WithResponseMapper(mapWarehouses))
+}
+
+func (RestController) PostWarehouses(w http.ResponseWriter, r *http.Request) {
+	var warehouse Warehouse
+	handlerDecorator(w, r, WithRequestBody(&warehouse), WithOperation(func(ctx context.Context) (any, error) {
+		return service.CreateWarehouse(ctx, domain.Warehouse{
+			ID:       *warehouse.Id,
+			Name:     stringOrEmpty(warehouse.Name),
+			Distance: intOrZero(warehouse.Distance),
+		})
+	}), WithResponseMapper(mapWarehouse), WithErrorMapper(mapInventoryError), WithDefaultStatus(http.StatusCreated))
+}
+
+func mapInventoryError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUnknownProduct), errors.Is(err, domain.ErrUnknownWarehouse),
+		errors.Is(err, domain.ErrReservationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrProductExists), errors.Is(err, domain.ErrWarehouseExists):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInsufficientStock):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrDomain):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
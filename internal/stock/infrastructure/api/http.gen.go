@@ -0,0 +1,646 @@
+// Package api provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	openapi_types "github.com/deepmap/oapi-codegen/pkg/types"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+)
+
+// Defines values for AdjustmentReason.
+const (
+	AdjustmentReasonCorrection AdjustmentReason = "correction"
+	AdjustmentReasonDamaged    AdjustmentReason = "damaged"
+	AdjustmentReasonFound      AdjustmentReason = "found"
+	AdjustmentReasonLost       AdjustmentReason = "lost"
+	AdjustmentReasonRestock    AdjustmentReason = "restock"
+)
+
+// Defines values for ReservationStatus.
+const (
+	Committed ReservationStatus = "committed"
+	Rejected  ReservationStatus = "rejected"
+	Released  ReservationStatus = "released"
+	Reserved  ReservationStatus = "reserved"
+)
+
+// AdjustStock defines model for AdjustStock.
+type AdjustStock struct {
+	// change of on-hand quantity, must not be zero
+	Delta *int    `json:"delta,omitempty"`
+	Note  *string `json:"note,omitempty"`
+
+	// reason code of adjustment, restock can only add units
+	Reason *AdjustmentReason `json:"reason,omitempty"`
+
+	// warehouse of adjusted units, default warehouse if omitted
+	Warehouse *string `json:"warehouse,omitempty"`
+}
+
+// Adjustment defines model for Adjustment.
+type Adjustment struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Delta     *int       `json:"delta,omitempty"`
+	Id        *int64     `json:"id,omitempty"`
+	Note      *string    `json:"note,omitempty"`
+
+	// on-hand quantity in warehouse after adjustment
+	OnHand *int `json:"on_hand,omitempty"`
+
+	// reason code of adjustment, restock can only add units
+	Reason    *AdjustmentReason `json:"reason,omitempty"`
+	Warehouse *string           `json:"warehouse,omitempty"`
+}
+
+// reason code of adjustment, restock can only add units
+type AdjustmentReason string
+
+// CreateProduct defines model for CreateProduct.
+type CreateProduct struct {
+	Name *string `json:"name,omitempty"`
+	Sku  *string `json:"sku,omitempty"`
+}
+
+// Error defines model for Error.
+type Error struct {
+	Errors *[]string `json:"errors,omitempty"`
+}
+
+// Item defines model for Item.
+type Item struct {
+	Quantity *int    `json:"quantity,omitempty"`
+	Sku      *string `json:"sku,omitempty"`
+
+	// warehouse which fulfils item
+	Warehouse *string `json:"warehouse,omitempty"`
+}
+
+// Location defines model for Location.
+type Location struct {
+	Available *int    `json:"available,omitempty"`
+	OnHand    *int    `json:"on_hand,omitempty"`
+	Reserved  *int    `json:"reserved,omitempty"`
+	Warehouse *string `json:"warehouse,omitempty"`
+}
+
+// Product defines model for Product.
+type Product struct {
+	Available *int        `json:"available,omitempty"`
+	Locations *[]Location `json:"locations,omitempty"`
+	Name      *string     `json:"name,omitempty"`
+	OnHand    *int        `json:"on_hand,omitempty"`
+	Reserved  *int        `json:"reserved,omitempty"`
+	Sku       *string     `json:"sku,omitempty"`
+}
+
+// Reservation defines model for Reservation.
+type Reservation struct {
+	CreatedAt *time.Time          `json:"created_at,omitempty"`
+	Id        *openapi_types.UUID `json:"id,omitempty"`
+	Items     *[]Item             `json:"items,omitempty"`
+	OrderId   *openapi_types.UUID `json:"order_id,omitempty"`
+
+	// reason of rejection
+	Reason    *string            `json:"reason,omitempty"`
+	Status    *ReservationStatus `json:"status,omitempty"`
+	UpdatedAt *time.Time         `json:"updated_at,omitempty"`
+}
+
+// ReservationStatus defines model for Reservation.Status.
+type ReservationStatus string
+
+// Restock defines model for Restock.
+type Restock struct {
+	Note *string `json:"note,omitempty"`
+
+	// number of received units, must be positive
+	Quantity *int `json:"quantity,omitempty"`
+
+	// warehouse which received units, default warehouse if omitted
+	Warehouse *string `json:"warehouse,omitempty"`
+}
+
+// Warehouse defines model for Warehouse.
+type Warehouse struct {
+	// distance to customers, nearer warehouses are preferred by allocation
+	Distance *int    `json:"distance,omitempty"`
+	Id       *string `json:"id,omitempty"`
+	Name     *string `json:"name,omitempty"`
+}
+
+// SKU defines model for SKU.
+type SKU = string
+
+// PostProductsJSONBody defines parameters for PostProducts.
+type PostProductsJSONBody = CreateProduct
+
+// PostProductsSkuAdjustmentsJSONBody defines parameters for PostProductsSkuAdjustments.
+type PostProductsSkuAdjustmentsJSONBody = AdjustStock
+
+// PostProductsSkuRestockJSONBody defines parameters for PostProductsSkuRestock.
+type PostProductsSkuRestockJSONBody = Restock
+
+// PostWarehousesJSONBody defines parameters for PostWarehouses.
+type PostWarehousesJSONBody = Warehouse
+
+// PostProductsJSONRequestBody defines body for PostProducts for application/json ContentType.
+type PostProductsJSONRequestBody = PostProductsJSONBody
+
+// PostProductsSkuAdjustmentsJSONRequestBody defines body for PostProductsSkuAdjustments for application/json ContentType.
+type PostProductsSkuAdjustmentsJSONRequestBody = PostProductsSkuAdjustmentsJSONBody
+
+// PostProductsSkuRestockJSONRequestBody defines body for PostProductsSkuRestock for application/json ContentType.
+type PostProductsSkuRestockJSONRequestBody = PostProductsSkuRestockJSONBody
+
+// PostWarehousesJSONRequestBody defines body for PostWarehouses for application/json ContentType.
+type PostWarehousesJSONRequestBody = PostWarehousesJSONBody
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// List products with their stock levels
+	// (GET /products)
+	GetProducts(w http.ResponseWriter, r *http.Request)
+	// Add product to inventory without stock
+	// (POST /products)
+	PostProducts(w http.ResponseWriter, r *http.Request)
+	// Stock levels of product
+	// (GET /products/{sku})
+	GetProductsSku(w http.ResponseWriter, r *http.Request, sku SKU)
+	// Audit trail of product stock changes, the latest first
+	// (GET /products/{sku}/adjustments)
+	GetProductsSkuAdjustments(w http.ResponseWriter, r *http.Request, sku SKU)
+	// Manually change on-hand quantity
+	// (POST /products/{sku}/adjustments)
+	PostProductsSkuAdjustments(w http.ResponseWriter, r *http.Request, sku SKU)
+	// Add received units to on-hand quantity
+	// (POST /products/{sku}/restock)
+	PostProductsSkuRestock(w http.ResponseWriter, r *http.Request, sku SKU)
+	// Stock reservation of order
+	// (GET /reservations/{orderID})
+	GetReservationsOrderID(w http.ResponseWriter, r *http.Request, orderID openapi_types.UUID)
+	// List warehouses, the nearest first
+	// (GET /warehouses)
+	GetWarehouses(w http.ResponseWriter, r *http.Request)
+	// Add warehouse without stock
+	// (POST /warehouses)
+	PostWarehouses(w http.ResponseWriter, r *http.Request)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.HandlerFunc) http.HandlerFunc
+
+// GetProducts operation middleware
+func (siw *ServerInterfaceWrapper) GetProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetProducts(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostProducts operation middleware
+func (siw *ServerInterfaceWrapper) PostProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostProducts(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetProductsSku operation middleware
+func (siw *ServerInterfaceWrapper) GetProductsSku(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sku" -------------
+	var sku SKU
+
+	err = runtime.BindStyledParameter("simple", false, "sku", chi.URLParam(r, "sku"), &sku)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sku", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetProductsSku(w, r, sku)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetProductsSkuAdjustments operation middleware
+func (siw *ServerInterfaceWrapper) GetProductsSkuAdjustments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sku" -------------
+	var sku SKU
+
+	err = runtime.BindStyledParameter("simple", false, "sku", chi.URLParam(r, "sku"), &sku)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sku", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetProductsSkuAdjustments(w, r, sku)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostProductsSkuAdjustments operation middleware
+func (siw *ServerInterfaceWrapper) PostProductsSkuAdjustments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sku" -------------
+	var sku SKU
+
+	err = runtime.BindStyledParameter("simple", false, "sku", chi.URLParam(r, "sku"), &sku)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sku", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostProductsSkuAdjustments(w, r, sku)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostProductsSkuRestock operation middleware
+func (siw *ServerInterfaceWrapper) PostProductsSkuRestock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sku" -------------
+	var sku SKU
+
+	err = runtime.BindStyledParameter("simple", false, "sku", chi.URLParam(r, "sku"), &sku)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sku", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostProductsSkuRestock(w, r, sku)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetReservationsOrderID operation middleware
+func (siw *ServerInterfaceWrapper) GetReservationsOrderID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "orderID" -------------
+	var orderID openapi_types.UUID
+
+	err = runtime.BindStyledParameter("simple", false, "orderID", chi.URLParam(r, "orderID"), &orderID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "orderID", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetReservationsOrderID(w, r, orderID)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetWarehouses operation middleware
+func (siw *ServerInterfaceWrapper) GetWarehouses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetWarehouses(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PostWarehouses operation middleware
+func (siw *ServerInterfaceWrapper) PostWarehouses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostWarehouses(w, r)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+type UnescapedCookieParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnescapedCookieParamError) Error() string {
+	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
+}
+
+func (e *UnescapedCookieParamError) Unwrap() error {
+	return e.Err
+}
+
+type UnmarshalingParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnmarshalingParamError) Error() string {
+	return fmt.Sprintf("Error unmarshaling parameter %s as JSON: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *UnmarshalingParamError) Unwrap() error {
+	return e.Err
+}
+
+type RequiredParamError struct {
+	ParamName string
+}
+
+func (e *RequiredParamError) Error() string {
+	return fmt.Sprintf("Query argument %s is required, but not found", e.ParamName)
+}
+
+type RequiredHeaderError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *RequiredHeaderError) Error() string {
+	return fmt.Sprintf("Header parameter %s is required, but not found", e.ParamName)
+}
+
+func (e *RequiredHeaderError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidParamFormatError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *InvalidParamFormatError) Error() string {
+	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *InvalidParamFormatError) Unwrap() error {
+	return e.Err
+}
+
+type TooManyValuesForParamError struct {
+	ParamName string
+	Count     int
+}
+
+func (e *TooManyValuesForParamError) Error() string {
+	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
+}
+
+// Handler creates http.Handler with routing matching OpenAPI spec.
+func Handler(si ServerInterface) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{})
+}
+
+type ChiServerOptions struct {
+	BaseURL          string
+	BaseRouter       chi.Router
+	Middlewares      []MiddlewareFunc
+	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
+func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseRouter: r,
+	})
+}
+
+func HandlerFromMuxWithBaseURL(si ServerInterface, r chi.Router, baseURL string) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseURL:    baseURL,
+		BaseRouter: r,
+	})
+}
+
+// HandlerWithOptions creates http.Handler with additional options
+func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handler {
+	r := options.BaseRouter
+
+	if r == nil {
+		r = chi.NewRouter()
+	}
+	if options.ErrorHandlerFunc == nil {
+		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandlerFunc:   options.ErrorHandlerFunc,
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/products", wrapper.GetProducts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/products", wrapper.PostProducts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/products/{sku}", wrapper.GetProductsSku)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/products/{sku}/adjustments", wrapper.GetProductsSkuAdjustments)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/products/{sku}/adjustments", wrapper.PostProductsSkuAdjustments)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/products/{sku}/restock", wrapper.PostProductsSkuRestock)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/reservations/{orderID}", wrapper.GetReservationsOrderID)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/warehouses", wrapper.GetWarehouses)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/warehouses", wrapper.PostWarehouses)
+	})
+
+	return r
+}
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+xZ3W/bNhD/Vw7cHtU47bIB81v2gSFYhwYJhj40RXGRTjETiVTJozMv0P8+kPq25MRu",
+	"E9cPe7JFkbwf7373wdODiHVeaEWKrZg/iAIN5sRkwtPln3/7n4RsbGTBUisx94OgUyiMTlzMIhLSjxbI",
+	"CxEJhTmJubB3TkTC0GcnDSVizsZRJGy8oBz9jrwqwjQ2Ut2Isiybl0HsaXLrLF+yju8CJqMLMizJVmAy",
+	"xjGqeIHqhjwwrV4tUCXw2aFiyasIcmcZlGa4JviXjBZRA0AqphsyooyE0kwT0Pwp0HoRD+J7Q6mYi+9m",
+	"nc5mNexZhTknxRfV/DIS92hooZ2lMdz2lUeMYS0l4JRkG0FCKbqMoZskU9C5ZKZEROsIy3ZEX99SzF5y",
+	"h2aswNgQMiWfMLxLtcn9P5Eg0yuWOY0lRJ3Sx3qTyWAbqfink90UrNUnb7CxktYtCVL1dIIpkwHsTjol",
+	"9JmMt5PKL1qZw+NUWCDWSc/qfkEEhqxnO8SoQKtsBZjUbBCRIOVyMf8g6kkiEgnmeBO4kGnrD55qp/xj",
+	"rI2hOMj7OGHGX4Ppz2vPHTGjct4JC3l33k4LvxujzXhn8sPhn2TK7aSQegCNwdX05mdM+Xjvhh3T9JyG",
+	"vqVv3i9kvIDUZanMLHjo27nfWx1jtdk6WFyizPA6o2m0PVeY4rIls6QNb3fl60YWPAExq882tOZj3tVq",
+	"Y2TkaDPlvkIT27P1IuyzwVRfEifXgqFzMpmc1qhtK/0F2k/oTpuEzKctRZrHg5JOwdBtHTomlltGdpUr",
+	"d9GoskEkqpX134zQUhWL8jplTYUiVyQ7aneDAaerhI3Jph8thppQLr8mU2kiJrns8nGoH64JCm0lyyWJ",
+	"6Cn/ezygrO//9fn+fV/4Wr0kLaOKJ2A1b4A1xM6yzsnYCBShIdOBsYCGoDCUkjGUwPUKMGuiwKQqZDKp",
+	"+g2+Pj6PH5Iq1cELtWIMYaqM1vCfqSUp1mYFOSq8IZ9KIdUG/PGrGAWWMU2P4N2okrBQVYxJlW+vV1eq",
+	"zq8W/MwuO1u4l7yA2lF89o6AMF7UG/itDMXeGROvSXSJZGCDMju6Uhe1lwwkLygLalSawft7Rr74C+5s",
+	"I2jj72BNXQtdqXbQo9KOoXFE0IqOIFTNfsEdFeyFdHaMmg0l2V4Ff6W8fVkzZmFYsoU2yB9dBRNL9ulA",
+	"VJt7cTImOD0/E5FYkrGVPV4fHR8dh8BUkMJCirn4IQxF4XYQ2DirhYaHGwrO35rrLBFz8QfxeTMnRPpC",
+	"K1tR+c3xcUOJurLFoshkBXV2W8e37paxVXSthU1UICPCvZWWe4qzfs2PO2J6DEpVPE0IPlNMRmHWqp7q",
+	"mZGwLs/RrBp0DbSKs7wgaaAqLTNaUhYgF9pO6P1c26HiPzuy/ItOVs92vmH5OXHOGr134nx0fyxHbHj9",
+	"bMgewXTprCVrU5fVZXnREeZkP8ZfYibX5P788nJrnQBmhjBZAf0j7aFx/rSzh4+9ss0ITXCsygO/qI08",
+	"swd758pt4s9l3cX4ihD0haQ779v6ZH+29hmpukselJkvexGs33sqo0HD6sM0hm7KzDe0yo9jNsx66X5L",
+	"Zpz2VuwjT3XydklV/XP9T6YqZnQFWo9LdZasajob+dQJGTJZhlQa+8Vc2ybdTrDp+ZNvv6kaVPdUdt1L",
+	"oDttWp/fKqviwK326h+6d8kaOsvJmzcvD2N0J7rXzt9LCDKyFniBanC3OCwv/guVQ1+SNY3/tdNM5fyZ",
+	"6bULXs6XL9o+7Uv4cbP74fhwjegbOnFj18Px4MMqkYcNJ18pT7uL6fqhdvYQGhJnvz1aKvc6qPZdNV+M",
+	"EuXEB0Ldzt38kfCJnmb58QXp3jvXBsp3r/dEuqBeWKAFpcEMARxaqd6DFz7JeugVxbp21GO0et/N2kdt",
+	"3YrbpbTuneTw+kD9rp8vZUNTd1DLbsxka7p//gzW03ZZ7rPLsyb4iT7PfZ8Ue01na5L30Ot5331VP/Bu",
+	"T+9bylqLx0/1xWqddJzJxFzM/HX/vwEAglJHPOUiAAA=",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %s", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	var res = make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	var resolvePath = PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		var pathToFile = url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/internal/stock/infrastructure/repository"
+)
+
+func mapProduct(product any) any {
+	switch product := product.(type) {
+	case domain.Product:
+		available := product.Available()
+		locations := make([]Location, 0, len(product.Locations))
+		for _, location := range product.Locations {
+			location := location
+			available := location.Available()
+			locations = append(locations, Location{
+				Warehouse: &location.Warehouse,
+				OnHand:    &location.OnHand,
+				Reserved:  &location.Reserved,
+				Available: &available,
+			})
+		}
+		return Product{
+			Sku:       &product.SKU,
+			Name:      &product.Name,
+			OnHand:    &product.OnHand,
+			Reserved:  &product.Reserved,
+			Available: &available,
+			Locations: &locations,
+		}
+	}
+	return Product{}
+}
+
+func mapProducts(products any) any {
+	switch products := products.(type) {
+	case []domain.Product:
+		result := make([]Product, 0, len(products))
+		for _, product := range products {
+			result = append(result, mapProduct(product).(Product))
+		}
+		return result
+	}
+	return []Product{}
+}
+
+func mapAdjustments(adjustments any) any {
+	switch adjustments := adjustments.(type) {
+	case []domain.Adjustment:
+		result := make([]Adjustment, 0, len(adjustments))
+		for _, adjustment := range adjustments {
+			adjustment := adjustment
+			reason := AdjustmentReason(adjustment.Reason)
+			result = append(result, Adjustment{
+				Id:        &adjustment.ID,
+				Warehouse: &adjustment.Warehouse,
+				Delta:     &adjustment.Delta,
+				Reason:    &reason,
+				Note:      &adjustment.Note,
+				OnHand:    &adjustment.OnHand,
+				CreatedAt: &adjustment.CreatedAt,
+			})
+		}
+		return result
+	}
+	return []Adjustment{}
+}
+
+func mapWarehouse(warehouse any) any {
+	switch warehouse := warehouse.(type) {
+	case domain.Warehouse:
+		return Warehouse{Id: &warehouse.ID, Name: &warehouse.Name, Distance: &warehouse.Distance}
+	}
+	return Warehouse{}
+}
+
+func mapWarehouses(warehouses any) any {
+	switch warehouses := warehouses.(type) {
+	case []domain.Warehouse:
+		result := make([]Warehouse, 0, len(warehouses))
+		for _, warehouse := range warehouses {
+			result = append(result, mapWarehouse(warehouse).(Warehouse))
+		}
+		return result
+	}
+	return []Warehouse{}
+}
+
+func mapReservation(reservation any) any {
+	switch reservation := reservation.(type) {
+	case repository.Reservation:
+		status := ReservationStatus(reservation.Status)
+		items := make([]Item, 0, len(reservation.Items))
+		for _, item := range reservation.Items {
+			item := item
+			items = append(items, Item{Sku: &item.SKU, Quantity: &item.Quantity, Warehouse: &item.Warehouse})
+		}
+		return Reservation{
+			Id:        &reservation.ID,
+			OrderId:   &reservation.OrderID,
+			Status:    &status,
+			Reason:    reservation.Reason,
+			Items:     &items,
+			CreatedAt: &reservation.CreatedAt,
+			UpdatedAt: &reservation.UpdatedAt,
+		}
+	}
+	return Reservation{}
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ``
+	}
+	return *s
+}
+
+func intOrZero(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+func warehouseOrDefault(warehouse *string) string {
+	if warehouse == nil || *warehouse == `` {
+		return domain.DefaultWarehouse
+	}
+	return *warehouse
+}
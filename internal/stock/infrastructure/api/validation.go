@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+
+	"github.com/moeryomenko/saga/internal/stock/domain"
+)
+
+type Validated interface {
+	Validate() error
+}
+
+func (r *CreateProduct) Validate() error {
+	var err *multierror.Error
+
+	if r.Sku == nil || *r.Sku == `` {
+		err = multierror.Append(err, fmt.Errorf(`product must have sku`))
+	}
+
+	return err.ErrorOrNil()
+}
+
+func (r *Restock) Validate() error {
+	var err *multierror.Error
+
+	if r.Quantity == nil || *r.Quantity <= 0 {
+		err = multierror.Append(err, fmt.Errorf(`quantity must be positive`))
+	}
+
+	return err.ErrorOrNil()
+}
+
+func (r *AdjustStock) Validate() error {
+	var err *multierror.Error
+
+	if r.Delta == nil || *r.Delta == 0 {
+		err = multierror.Append(err, fmt.Errorf(`delta must not be zero`))
+	}
+	switch {
+	case r.Reason == nil:
+		err = multierror.Append(err, fmt.Errorf(`reason is required`))
+	case !domain.AdjustmentReason(*r.Reason).Valid():
+		err = multierror.Append(err, fmt.Errorf(`unknown reason %q`, *r.Reason))
+	case *r.Reason == AdjustmentReasonRestock && r.Delta != nil && *r.Delta < 0:
+		err = multierror.Append(err, fmt.Errorf(`restock can only add units`))
+	}
+
+	return err.ErrorOrNil()
+}
+
+func (r *Warehouse) Validate() error {
+	var err *multierror.Error
+
+	if r.Id == nil || *r.Id == `` {
+		err = multierror.Append(err, fmt.Errorf(`warehouse must have id`))
+	}
+	if r.Distance != nil && *r.Distance < 0 {
+		err = multierror.Append(err, fmt.Errorf(`distance must not be negative`))
+	}
+
+	return err.ErrorOrNil()
+}
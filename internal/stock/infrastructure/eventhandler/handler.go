@@ -2,85 +2,66 @@ package eventhandler
 
 import (
 	"context"
-	"log"
-	"strings"
-	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+
 	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/saga"
 	"github.com/moeryomenko/saga/schema"
 )
 
-type EventHandler func(domain.Event) (domain.Stock, error)
+// EventHandler handles event of order.
+type EventHandler func(context.Context, uuid.UUID, domain.Event) error
 
+// HandleEvents handles saga commands of order service.
 func HandleEvents(eventHandler EventHandler) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
-		for initConsumerGroup(ctx) != nil {
-		}
-
-		consumerID := uuid.New().String()
-		for {
-			select {
-			case <-ctx.Done():
-				return nil
-			default:
-				events, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
-					Group:    StockGroup,
-					Consumer: consumerID,
-					Streams:  []string{OrderStream, `>`},
-					Block:    0,
-					Count:    1,
-					NoAck:    false,
-				}).Result()
-				if err != nil {
-					<-time.After(time.Second)
-					continue
-				}
-
-				for _, msg := range events[0].Messages {
-					err = handleMessage(ctx, msg, eventHandler)
-					if err != nil {
-						log.Println(err)
-					}
-					_, _ = client.XAck(ctx, OrderStream, StockGroup, msg.ID).Result()
-				}
-			}
-		}
+		return streams.Subscribe(StockGroup, saga.CommandStream, func(ctx context.Context, _ string, values map[string]any) error {
+			return handleMessage(ctx, values, eventHandler)
+		})(ctx)
 	}
 }
 
-func handleMessage(ctx context.Context, msg redis.XMessage, eventHandler EventHandler) error {
-	event, err := schema.ToOrderEvent(msg.Values)
+func handleMessage(ctx context.Context, values map[string]any, eventHandler EventHandler) error {
+	event, err := schema.ToOrderEvent(values)
 	if err != nil {
-		return err
+		return errors.MarkAndWrapError(err, saga.ErrRejected, `invalid command`)
 	}
 
-	// skip completed and canceled orders.
-	if event.Type == schema.CompleteOrder || event.Type == schema.CancelOrder {
+	var stockEvent domain.Event
+	switch event.Type {
+	case schema.NewOrder:
+		stockEvent = domain.StockOrder{
+			OrderID: event.OrderID,
+			Items:   mapItemsFromEvent(event.Items),
+		}
+	case schema.CompleteOrder:
+		stockEvent = domain.CommitStock{OrderID: event.OrderID}
+	case schema.CancelOrder:
+		stockEvent = domain.CancelStock{OrderID: event.OrderID}
+	case schema.ReturnOrder:
+		stockEvent = domain.ReturnStock{
+			OrderID:  event.OrderID,
+			ReturnID: event.ReturnID,
+			Items:    mapItemsFromEvent(event.Items),
+		}
+	default:
+		// command isn't addressed to stock.
 		return nil
 	}
 
-	stock, err := eventHandler(domain.StockOrder{
-		OrderID: event.OrderID,
-		Items:   mapItemsFromEvent(event.Items),
-	})
-	if err != nil {
-		return err
+	err = eventHandler(ctx, event.OrderID, stockEvent)
+	if errors.Is(err, domain.ErrDomain) {
+		return errors.MarkAndWrapError(err, saga.ErrRejected, `command is rejected`)
 	}
-
-	err = ProcudeConfimation(ctx, stock)
-	if err != nil {
-		return err
-	}
-
-	_, err = client.XAck(ctx, OrderStream, StockGroup, msg.ID).Result()
-	if err != nil {
-		return err
-	}
-	return nil
+	return err
 }
 
-func mapItemsFromEvent(items string) []string {
-	return strings.Split(items, `, `)
+func mapItemsFromEvent(items schema.Items) []domain.Item {
+	domainItems := make([]domain.Item, 0, len(items))
+	for _, item := range items {
+		domainItems = append(domainItems, domain.Item{SKU: item.SKU, Quantity: item.Quantity})
+	}
+	return domainItems
 }
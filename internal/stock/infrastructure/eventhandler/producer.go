@@ -3,26 +3,13 @@ package eventhandler
 import (
 	"context"
 
-	"github.com/go-redis/redis/v8"
-	"github.com/moeryomenko/saga/internal/stock/domain"
-	"github.com/moeryomenko/saga/schema"
+	"github.com/moeryomenko/saga/pkg/outbox"
+	"github.com/moeryomenko/saga/pkg/saga"
 )
 
-func ProcudeConfimation(ctx context.Context, stock domain.Stock) error {
-	event := schema.StockEvent{OrderID: stock.GetOrderID()}
-
-	switch stock.(type) {
-	case domain.ActiveStock:
-		event.SetType(schema.StockConfirmed)
-	case domain.RejectedStock:
-		event.SetType(schema.StockFailed)
-	default:
-		panic(`bug: invalied state for stock`)
-	}
-
-	_, err := client.XAdd(ctx, &redis.XAddArgs{
-		Stream: ConfirmStream,
-		Values: event.Map(),
-	}).Result()
-	return err
+// Publisher publishes relayed events to saga.ReplyStream.
+func Publisher() outbox.Publisher {
+	return outbox.PublisherFunc(func(ctx context.Context, messages []map[string]string) (int, error) {
+		return streams.PublishBatch(ctx, saga.ReplyStream, messages)
+	})
 }
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/moeryomenko/saga/pkg/outbox"
+)
+
+var (
+	ErrInfrastructure = errors.New(`infrastructure`)
+
+	ErrNoEvents = outbox.ErrNoEvents
+)
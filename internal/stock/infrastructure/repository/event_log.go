@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/outbox"
+	"github.com/moeryomenko/saga/schema"
+)
+
+// EventLog is outbox of stock replies.
+func EventLog() outbox.Outbox[schema.StockEvent] {
+	return outbox.New[schema.StockEvent](pool)
+}
+
+func insertEvent(ctx context.Context, tx pgx.Tx, stock domain.Stock) error {
+	event, ok := mapToEvent(stock)
+	if !ok {
+		return nil
+	}
+	err := EventLog().Write(ctx, tx, event)
+	if err != nil {
+		return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't insert stock event to log`)
+	}
+	return nil
+}
+
+// mapToEvent maps stock to reply of saga, compensation and completion have no replies.
+func mapToEvent(stock domain.Stock) (schema.StockEvent, bool) {
+	event := schema.StockEvent{OrderID: stock.GetOrderID()}
+	switch stock := stock.(type) {
+	case domain.ActiveStock:
+		event.Allocation = make(schema.Allocation, 0, len(stock.Items))
+		for _, item := range stock.Items {
+			event.Allocation = append(event.Allocation, schema.AllocatedItem{SKU: item.SKU, Warehouse: item.Warehouse, Quantity: item.Quantity})
+		}
+		event.SetType(schema.StockConfirmed)
+	case domain.RejectedStock:
+		event.Reason = stock.Reason
+		event.SetType(schema.StockFailed)
+	case domain.ReturnedStock:
+		event.ReturnID = stock.ReturnID
+		event.SetType(schema.StockReturned)
+	default:
+		return schema.StockEvent{}, false
+	}
+	return event, true
+}
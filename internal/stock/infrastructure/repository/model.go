@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/moeryomenko/saga/internal/stock/domain"
+)
+
+const (
+	reserved  = `reserved`
+	rejected  = `rejected`
+	released  = `released`
+	committed = `committed`
+)
+
+// Reservation is stored stock of order.
+type Reservation struct {
+	ID      uuid.UUID
+	OrderID uuid.UUID
+	Status  string
+	Reason  *string
+	Items   []domain.Item
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// stockToModel maps stock to reservation, reservation without items gets new ID,
+// which is kept only if order has no reservation yet.
+func stockToModel(stock domain.Stock) Reservation {
+	switch stock := stock.(type) {
+	case domain.ActiveStock:
+		return Reservation{ID: stock.ID, OrderID: stock.OrderID, Status: reserved, Items: stock.Items}
+	case domain.CommittedStock:
+		return Reservation{ID: stock.ID, OrderID: stock.OrderID, Status: committed, Items: stock.Items}
+	case domain.RejectedStock:
+		return Reservation{ID: uuid.New(), OrderID: stock.OrderID, Status: rejected, Reason: &stock.Reason}
+	case domain.CanceledStock:
+		return Reservation{ID: uuid.New(), OrderID: stock.OrderID, Status: released}
+	default:
+		panic(`bug: stock isn't reservation`)
+	}
+}
+
+func modelToStock(model Reservation) domain.Stock {
+	active := domain.ActiveStock{ID: model.ID, OrderID: model.OrderID, Items: model.Items}
+	switch model.Status {
+	case reserved:
+		return active
+	case committed:
+		return domain.CommittedStock{ActiveStock: active}
+	case rejected:
+		stock := domain.RejectedStock{OrderID: model.OrderID}
+		if model.Reason != nil {
+			stock.Reason = *model.Reason
+		}
+		return stock
+	case released:
+		return domain.CanceledStock{OrderID: model.OrderID}
+	default:
+		panic(`bug: invalid reservation status`)
+	}
+}
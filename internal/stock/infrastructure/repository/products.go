@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+func CreateProduct(ctx context.Context, sku, name string) (domain.Product, error) {
+	_, err := pool.Exec(ctx, insertProductQuery, sku, name)
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return domain.Product{SKU: sku, Name: name}, nil
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		return domain.Product{}, errors.MarkAndWrapError(domain.ErrProductExists, domain.ErrDomain, `couldn't create product`)
+	default:
+		return domain.Product{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't create product`)
+	}
+}
+
+func FindProduct(ctx context.Context, sku string) (domain.Product, error) {
+	product, err := findProduct(ctx, pool, sku, findProductsQuery)
+	switch err {
+	case nil:
+		return product, nil
+	case pgx.ErrNoRows:
+		return domain.Product{}, errors.MarkAndWrapError(domain.ErrUnknownProduct, domain.ErrDomain, sku)
+	default:
+		return domain.Product{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find product`)
+	}
+}
+
+func ListProducts(ctx context.Context) ([]domain.Product, error) {
+	rows, err := pool.Query(ctx, listProductsQuery)
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't list products`)
+	}
+
+	products, err := scanProducts(rows)
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't list products`)
+	}
+	return products, nil
+}
+
+// AdjustStock changes on-hand quantity of product in warehouse and records adjustment to audit trail.
+func AdjustStock(ctx context.Context, sku, warehouse string, delta int, reason domain.AdjustmentReason, note string) (domain.Product, error) {
+	var product domain.Product
+	err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		var err error
+		product, err = findProduct(ctx, tx, sku, lockProductQuery)
+		switch err {
+		case nil:
+		case pgx.ErrNoRows:
+			return errors.MarkAndWrapError(domain.ErrUnknownProduct, domain.ErrDomain, sku)
+		default:
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find product`)
+		}
+
+		product, err = product.Adjust(warehouse, delta, reason)
+		if err != nil {
+			return errors.MarkAndWrapError(err, domain.ErrDomain, `couldn't adjust stock`)
+		}
+
+		location := findLocation(product, warehouse)
+		_, err = tx.Exec(ctx, upsertLocationQuery, warehouse, sku, location.OnHand, location.Reserved)
+		var pgErr *pgconn.PgError
+		switch {
+		case err == nil:
+		case errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation:
+			return errors.MarkAndWrapError(domain.ErrUnknownWarehouse, domain.ErrDomain, warehouse)
+		default:
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't update product`)
+		}
+
+		_, err = tx.Exec(ctx, insertAdjustmentQuery, sku, warehouse, delta, string(reason), note, location.OnHand)
+		if err != nil {
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't save adjustment`)
+		}
+		return nil
+	})
+	return product, err
+}
+
+// ListAdjustments returns audit trail of product, the latest adjustments first.
+func ListAdjustments(ctx context.Context, sku string) ([]domain.Adjustment, error) {
+	_, err := FindProduct(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pool.Query(ctx, listAdjustmentsQuery, sku)
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't list adjustments`)
+	}
+	defer rows.Close()
+
+	adjustments := []domain.Adjustment{}
+	for rows.Next() {
+		var (
+			adjustment domain.Adjustment
+			reason     string
+			createdAt  pgtype.Timestamp
+		)
+		err = rows.Scan(&adjustment.ID, &adjustment.SKU, &adjustment.Warehouse, &adjustment.Delta, &reason,
+			&adjustment.Note, &adjustment.OnHand, &createdAt)
+		if err != nil {
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan adjustment`)
+		}
+		adjustment.Reason = domain.AdjustmentReason(reason)
+		adjustment.CreatedAt = createdAt.Time
+		adjustments = append(adjustments, adjustment)
+	}
+	if rows.Err() != nil {
+		return nil, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't list adjustments`)
+	}
+	return adjustments, nil
+}
+
+// FindReservation returns reservation of order with its items.
+func FindReservation(ctx context.Context, orderID uuid.UUID) (Reservation, error) {
+	var reservation Reservation
+	err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		var err error
+		reservation, err = findReservationModel(ctx, tx, orderID)
+		return err
+	})
+	switch err {
+	case nil:
+		return reservation, nil
+	case pgx.ErrNoRows:
+		return Reservation{}, errors.MarkAndWrapError(domain.ErrReservationNotFound, domain.ErrDomain, orderID.String())
+	default:
+		return Reservation{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find reservation`)
+	}
+}
+
+func findProduct(ctx context.Context, q querier, sku, query string) (domain.Product, error) {
+	rows, err := q.Query(ctx, query, []string{sku})
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	products, err := scanProducts(rows)
+	switch {
+	case err != nil:
+		return domain.Product{}, err
+	case len(products) == 0:
+		return domain.Product{}, pgx.ErrNoRows
+	default:
+		return products[0], nil
+	}
+}
+
+// scanProducts scans products with their locations, rows of product must go in a row.
+func scanProducts(rows pgx.Rows) ([]domain.Product, error) {
+	defer rows.Close()
+
+	var (
+		products  = []domain.Product{}
+		sku, name string
+		locations []domain.Location
+	)
+	for rows.Next() {
+		var (
+			productSKU, productName string
+			warehouse               *string
+			distance                *int
+			onHand, reserved        *int
+		)
+		err := rows.Scan(&productSKU, &productName, &warehouse, &distance, &onHand, &reserved)
+		if err != nil {
+			return nil, err
+		}
+		if productSKU != sku && sku != `` {
+			products = append(products, domain.NewProduct(sku, name, locations))
+			locations = nil
+		}
+		sku, name = productSKU, productName
+		if warehouse != nil {
+			locations = append(locations, domain.Location{Warehouse: *warehouse, Distance: *distance, OnHand: *onHand, Reserved: *reserved})
+		}
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	if sku != `` {
+		products = append(products, domain.NewProduct(sku, name, locations))
+	}
+	return products, nil
+}
+
+func findLocation(product domain.Product, warehouse string) domain.Location {
+	for _, location := range product.Locations {
+		if location.Warehouse == warehouse {
+			return location
+		}
+	}
+	return domain.Location{Warehouse: warehouse}
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const (
+	// uniqueViolation is postgres error code of unique constraint violation.
+	uniqueViolation = `23505`
+	// foreignKeyViolation is postgres error code of foreign key violation.
+	foreignKeyViolation = `23503`
+)
+
+// selectProducts selects products with their locations, the nearest first.
+const selectProducts = `
+	SELECT p.sku, p.name, s.warehouse_id, w.distance, s.on_hand, s.reserved
+	FROM products p
+	LEFT JOIN warehouse_stock s ON s.sku = p.sku
+	LEFT JOIN warehouses w ON w.warehouse_id = s.warehouse_id`
+
+const (
+	insertProductQuery = `INSERT INTO products(sku, name) VALUES ($1, $2)`
+	findProductsQuery  = selectProducts + `
+	WHERE p.sku = ANY($1) ORDER BY p.sku, w.distance, s.warehouse_id`
+	lockProductQuery  = findProductsQuery + ` FOR UPDATE OF p`
+	listProductsQuery = selectProducts + `
+	ORDER BY p.sku, w.distance, s.warehouse_id`
+	upsertLocationQuery = `
+	INSERT INTO warehouse_stock(warehouse_id, sku, on_hand, reserved) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (warehouse_id, sku) DO UPDATE SET on_hand = EXCLUDED.on_hand, reserved = EXCLUDED.reserved`
+
+	insertAdjustmentQuery = `
+	INSERT INTO stock_adjustments(sku, warehouse_id, delta, reason, note, on_hand) VALUES ($1, $2, $3, $4, $5, $6)`
+	listAdjustmentsQuery = `
+	SELECT id, sku, warehouse_id, delta, reason, note, on_hand, created_at
+	FROM stock_adjustments WHERE sku = $1 ORDER BY id DESC`
+)
@@ -0,0 +1,82 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+func TestIntegration_Inventory(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=stock`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	sku := uuid.New().String()
+
+	product, err := CreateProduct(ctx, sku, `test`)
+	require.NoError(t, err)
+	require.Equal(t, domain.Product{SKU: sku, Name: `test`}, product)
+
+	_, err = CreateProduct(ctx, sku, `test`)
+	require.True(t, errors.Is(err, domain.ErrProductExists))
+
+	_, err = AdjustStock(ctx, uuid.New().String(), domain.DefaultWarehouse, 1, domain.Restock, ``)
+	require.True(t, errors.Is(err, domain.ErrUnknownProduct))
+
+	_, err = AdjustStock(ctx, sku, uuid.New().String(), 1, domain.Restock, ``)
+	require.True(t, errors.Is(err, domain.ErrUnknownWarehouse))
+
+	product, err = AdjustStock(ctx, sku, domain.DefaultWarehouse, 5, domain.Restock, `delivery`)
+	require.NoError(t, err)
+	require.Equal(t, 5, product.OnHand)
+
+	orderID := uuid.New()
+	_, err = PersistStock(ctx, orderID, domain.StockOrder{OrderID: orderID, Items: []domain.Item{{SKU: sku, Quantity: 3}}}, domain.SingleLocation)
+	require.NoError(t, err)
+
+	// reserved units can't be taken by adjustment.
+	_, err = AdjustStock(ctx, sku, domain.DefaultWarehouse, -3, domain.Damaged, ``)
+	require.True(t, errors.Is(err, domain.ErrInsufficientStock))
+
+	product, err = AdjustStock(ctx, sku, domain.DefaultWarehouse, -2, domain.Damaged, `broken box`)
+	require.NoError(t, err)
+	require.Equal(t, domain.NewProduct(sku, `test`, []domain.Location{{Warehouse: domain.DefaultWarehouse, OnHand: 3, Reserved: 3}}), product)
+
+	found, err := FindProduct(ctx, sku)
+	require.NoError(t, err)
+	require.Equal(t, product, found)
+	require.Zero(t, found.Available())
+
+	adjustments, err := ListAdjustments(ctx, sku)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 2)
+	require.Equal(t, domain.Damaged, adjustments[0].Reason)
+	require.Equal(t, domain.DefaultWarehouse, adjustments[0].Warehouse)
+	require.Equal(t, -2, adjustments[0].Delta)
+	require.Equal(t, `broken box`, adjustments[0].Note)
+	require.Equal(t, 3, adjustments[0].OnHand)
+	require.Equal(t, domain.Restock, adjustments[1].Reason)
+
+	reservation, err := FindReservation(ctx, orderID)
+	require.NoError(t, err)
+	require.Equal(t, reserved, reservation.Status)
+	require.Equal(t, []domain.Item{{SKU: sku, Quantity: 3, Warehouse: domain.DefaultWarehouse}}, reservation.Items)
+
+	_, err = FindReservation(ctx, uuid.New())
+	require.True(t, errors.Is(err, domain.ErrReservationNotFound))
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+// PersistStock applies event to reservation of order and inventory of its items,
+// reply of saga is inserted to event log in the same transaction.
+// Items of stocked order are allocated to warehouses by strategy.
+func PersistStock(ctx context.Context, orderID uuid.UUID, event domain.Event, strategy domain.AllocationStrategy) (domain.Stock, error) {
+	var stock domain.Stock
+	err := pool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, lockOrderQuery, orderID.String())
+		if err != nil {
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't lock order stock`)
+		}
+
+		current, err := findReservation(ctx, tx, orderID)
+		if err != nil {
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find reservation`)
+		}
+
+		if event, ok := event.(domain.ReturnStock); ok {
+			err = insertRestock(ctx, tx, event)
+			if err != nil {
+				return err
+			}
+		}
+
+		inventory, err := findInventory(ctx, tx, stockItems(current, event))
+		if err != nil {
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't find inventory`)
+		}
+
+		inventory, stock, err = inventory.Transaction(domain.Tx{Stock: current, Event: event, Strategy: strategy})
+		if err != nil {
+			return errors.MarkAndWrapError(err, domain.ErrDomain, `couldn't apply event`)
+		}
+
+		err = saveInventory(ctx, tx, inventory)
+		if err != nil {
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't update inventory`)
+		}
+
+		if _, ok := stock.(domain.ReturnedStock); ok {
+			// returns don't change reservation.
+			return insertEvent(ctx, tx, stock)
+		}
+		err = saveReservation(ctx, tx, stockToModel(stock))
+		if err != nil {
+			return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't update reservation`)
+		}
+		if current != nil {
+			// reply on redelivered command is already in log.
+			return nil
+		}
+		return insertEvent(ctx, tx, stock)
+	})
+	return stock, err
+}
+
+// stockItems returns items which inventory is changed by event.
+func stockItems(stock domain.Stock, event domain.Event) []domain.Item {
+	switch event := event.(type) {
+	case domain.StockOrder:
+		return event.Items
+	case domain.ReturnStock:
+		return event.Items
+	}
+	switch stock := stock.(type) {
+	case domain.ActiveStock:
+		return stock.Items
+	case domain.CommittedStock:
+		return stock.Items
+	}
+	return nil
+}
+
+func findReservation(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) (domain.Stock, error) {
+	model, err := findReservationModel(ctx, tx, orderID)
+	switch err {
+	case nil:
+		return modelToStock(model), nil
+	case pgx.ErrNoRows:
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+func findReservationModel(ctx context.Context, tx pgx.Tx, orderID uuid.UUID) (Reservation, error) {
+	var (
+		model                Reservation
+		createdAt, updatedAt pgtype.Timestamp
+	)
+	err := tx.QueryRow(ctx, findReservationQuery, orderID.String()).Scan(&model.ID, &model.OrderID, &model.Status, &model.Reason, &createdAt, &updatedAt)
+	if err != nil {
+		return Reservation{}, err
+	}
+	model.CreatedAt, model.UpdatedAt = createdAt.Time, updatedAt.Time
+
+	rows, err := tx.Query(ctx, findReservationItemsQuery, model.ID)
+	if err != nil {
+		return Reservation{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item domain.Item
+		err = rows.Scan(&item.SKU, &item.Warehouse, &item.Quantity)
+		if err != nil {
+			return Reservation{}, err
+		}
+		model.Items = append(model.Items, item)
+	}
+	return model, rows.Err()
+}
+
+func saveReservation(ctx context.Context, tx pgx.Tx, model Reservation) error {
+	_, err := tx.Exec(ctx, upsertReservationQuery, model.ID, model.OrderID, model.Status, model.Reason)
+	if err != nil {
+		return err
+	}
+
+	if model.Status != reserved {
+		return nil
+	}
+	for _, item := range model.Items {
+		_, err = tx.Exec(ctx, insertReservationItemQuery, model.ID, item.SKU, item.Warehouse, item.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findInventory locks products of items in order of SKU to prevent deadlocks,
+// stock of products in warehouses is changed only under lock of product.
+func findInventory(ctx context.Context, tx pgx.Tx, items []domain.Item) (domain.Inventory, error) {
+	skus := make([]string, 0, len(items))
+	for _, item := range items {
+		skus = append(skus, item.SKU)
+	}
+
+	rows, err := tx.Query(ctx, lockProductQuery, skus)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := scanProducts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := make(domain.Inventory, len(products))
+	for _, product := range products {
+		inventory[product.SKU] = product
+	}
+	return inventory, nil
+}
+
+func saveInventory(ctx context.Context, tx pgx.Tx, inventory domain.Inventory) error {
+	for _, product := range inventory {
+		// returned items may be unknown.
+		_, err := tx.Exec(ctx, ensureProductQuery, product.SKU)
+		if err != nil {
+			return err
+		}
+
+		for _, location := range product.Locations {
+			_, err = tx.Exec(ctx, upsertLocationQuery, location.Warehouse, product.SKU, location.OnHand, location.Reserved)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func insertRestock(ctx context.Context, tx pgx.Tx, event domain.ReturnStock) error {
+	tag, err := tx.Exec(ctx, insertRestockQuery, event.ReturnID, event.OrderID)
+	if err != nil {
+		return errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't save restock`)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.MarkAndWrapError(domain.ErrRestockApplied, domain.ErrDomain, event.ReturnID.String())
+	}
+	return nil
+}
+
+const (
+	lockOrderQuery       = `SELECT pg_advisory_xact_lock(hashtext($1))`
+	findReservationQuery = `
+	SELECT reservation_id, order_id, status, reason, created_at, updated_at FROM reservations WHERE order_id = $1`
+	findReservationItemsQuery = `
+	SELECT sku, warehouse_id, quantity FROM reservation_items WHERE reservation_id = $1 ORDER BY sku, warehouse_id`
+	upsertReservationQuery = `
+	INSERT INTO reservations(reservation_id, order_id, status, reason) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (order_id) DO UPDATE SET status = EXCLUDED.status, reason = EXCLUDED.reason, updated_at = CURRENT_TIMESTAMP
+	WHERE reservations.status IS DISTINCT FROM EXCLUDED.status`
+	insertReservationItemQuery = `
+	INSERT INTO reservation_items(reservation_id, sku, warehouse_id, quantity) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (reservation_id, sku, warehouse_id) DO NOTHING`
+	ensureProductQuery = `INSERT INTO products(sku) VALUES ($1) ON CONFLICT (sku) DO NOTHING`
+	insertRestockQuery = `
+	INSERT INTO restocks(return_id, order_id) VALUES ($1, $2) ON CONFLICT (return_id) DO NOTHING`
+)
@@ -0,0 +1,301 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/schema"
+)
+
+func TestIntegration_Stock(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=stock`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	product := func(t *testing.T, onHand int) string {
+		sku := uuid.New().String()
+		stockProduct(t, sku, domain.Location{Warehouse: domain.DefaultWarehouse, OnHand: onHand})
+		return sku
+	}
+	inventory := func(t *testing.T, sku string) domain.Product {
+		product := domain.Product{SKU: sku}
+		err := pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(on_hand), 0), COALESCE(SUM(reserved), 0) FROM warehouse_stock WHERE sku = $1`,
+			sku).Scan(&product.OnHand, &product.Reserved)
+		require.NoError(t, err)
+		return product
+	}
+
+	testcases := map[string]struct {
+		onHand          int
+		quantity        int
+		events          func(orderID uuid.UUID) []domain.Event
+		expectedStock   func(orderID uuid.UUID, stock domain.Stock)
+		expectedProduct domain.Product
+		expectedErr     error
+	}{
+		`reserved stock`: {
+			onHand:   2,
+			quantity: 2,
+			events: func(uuid.UUID) []domain.Event {
+				return nil
+			},
+			expectedStock: func(orderID uuid.UUID, stock domain.Stock) {
+				require.IsType(t, domain.ActiveStock{}, stock)
+			},
+			expectedProduct: domain.Product{OnHand: 2, Reserved: 2},
+		},
+		`rejected stock`: {
+			onHand:   1,
+			quantity: 2,
+			events: func(orderID uuid.UUID) []domain.Event {
+				return nil
+			},
+			expectedStock: func(orderID uuid.UUID, stock domain.Stock) {
+				require.IsType(t, domain.RejectedStock{}, stock)
+				require.Contains(t, stock.(domain.RejectedStock).Reason, `insufficient stock`)
+			},
+			expectedProduct: domain.Product{OnHand: 1},
+		},
+		`released stock`: {
+			onHand:   2,
+			quantity: 1,
+			events: func(orderID uuid.UUID) []domain.Event {
+				return []domain.Event{domain.CancelStock{OrderID: orderID}, domain.CancelStock{OrderID: orderID}}
+			},
+			expectedStock: func(orderID uuid.UUID, stock domain.Stock) {
+				require.Equal(t, domain.CanceledStock{OrderID: orderID}, stock)
+			},
+			expectedProduct: domain.Product{OnHand: 2},
+		},
+		`committed stock`: {
+			onHand:   2,
+			quantity: 1,
+			events: func(orderID uuid.UUID) []domain.Event {
+				return []domain.Event{domain.CommitStock{OrderID: orderID}, domain.CommitStock{OrderID: orderID}}
+			},
+			expectedStock: func(orderID uuid.UUID, stock domain.Stock) {
+				require.IsType(t, domain.CommittedStock{}, stock)
+			},
+			expectedProduct: domain.Product{OnHand: 1},
+		},
+		`restocked items`: {
+			onHand:   2,
+			quantity: 1,
+			events: func(orderID uuid.UUID) []domain.Event {
+				return []domain.Event{domain.CommitStock{OrderID: orderID}, domain.ReturnStock{OrderID: orderID, ReturnID: orderID}}
+			},
+			expectedStock: func(orderID uuid.UUID, stock domain.Stock) {
+				require.IsType(t, domain.ReturnedStock{}, stock)
+			},
+			expectedProduct: domain.Product{OnHand: 2},
+		},
+		`repeated restock`: {
+			onHand:   2,
+			quantity: 1,
+			events: func(orderID uuid.UUID) []domain.Event {
+				return []domain.Event{
+					domain.CommitStock{OrderID: orderID},
+					domain.ReturnStock{OrderID: orderID, ReturnID: orderID},
+					domain.ReturnStock{OrderID: orderID, ReturnID: orderID},
+				}
+			},
+			expectedErr:     domain.ErrRestockApplied,
+			expectedProduct: domain.Product{OnHand: 2},
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			orderID, sku := uuid.New(), product(t, tc.onHand)
+			items := []domain.Item{{SKU: sku, Quantity: tc.quantity}}
+
+			stock, err := PersistStock(ctx, orderID, domain.StockOrder{OrderID: orderID, Items: items}, domain.SingleLocation)
+			require.NoError(t, err)
+
+			// redelivered command doesn't reserve stock twice.
+			redelivered, err := PersistStock(ctx, orderID, domain.StockOrder{OrderID: orderID, Items: items}, domain.SingleLocation)
+			require.NoError(t, err)
+			require.Equal(t, stock, redelivered)
+
+			for _, event := range tc.events(orderID) {
+				if event, ok := event.(domain.ReturnStock); ok {
+					event.Items = items
+					stock, err = PersistStock(ctx, orderID, event, domain.SingleLocation)
+				} else {
+					stock, err = PersistStock(ctx, orderID, event, domain.SingleLocation)
+				}
+				if err != nil {
+					break
+				}
+			}
+			if tc.expectedErr != nil {
+				require.True(t, errors.Is(err, tc.expectedErr))
+				require.True(t, errors.Is(err, domain.ErrDomain))
+			} else {
+				require.NoError(t, err)
+				tc.expectedStock(orderID, stock)
+			}
+
+			tc.expectedProduct.SKU = sku
+			require.Equal(t, tc.expectedProduct, inventory(t, sku))
+		})
+	}
+}
+
+func TestIntegration_StockEventLog(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=stock`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	_, err = pool.Exec(ctx, `TRUNCATE event_log`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `UPDATE event_offset SET xid_acked = '0', offset_acked = 0`)
+	require.NoError(t, err)
+
+	orderID, sku := uuid.New(), uuid.New().String()
+	stockProduct(t, sku, domain.Location{Warehouse: domain.DefaultWarehouse, OnHand: 1})
+
+	items := []domain.Item{{SKU: sku, Quantity: 1}}
+	for _, event := range []domain.Event{
+		domain.StockOrder{OrderID: orderID, Items: items},
+		// redelivered command and compensation don't produce replies.
+		domain.StockOrder{OrderID: orderID, Items: items},
+		domain.CancelStock{OrderID: orderID},
+	} {
+		_, err = PersistStock(ctx, orderID, event, domain.SingleLocation)
+		require.NoError(t, err)
+	}
+	rejectedID := uuid.New()
+	_, err = PersistStock(ctx, rejectedID, domain.StockOrder{OrderID: rejectedID, Items: []domain.Item{{SKU: `unknown`, Quantity: 1}}}, domain.SingleLocation)
+	require.NoError(t, err)
+
+	entries, err := EventLog().Read(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Less(t, entries[0].Offset, entries[1].Offset)
+
+	id, event, err := getEvent(ctx)
+	require.NoError(t, err)
+	require.Equal(t, entries[0].Offset, id)
+	require.Equal(t, schema.StockEvent{
+		Event:      schema.Event{Type: schema.StockConfirmed},
+		OrderID:    orderID,
+		Allocation: schema.Allocation{{SKU: sku, Warehouse: domain.DefaultWarehouse, Quantity: 1}},
+	}, event)
+	require.NoError(t, EventLog().Ack(ctx, id))
+
+	id, event, err = getEvent(ctx)
+	require.NoError(t, err)
+	require.Equal(t, schema.StockFailed, event.Type)
+	require.Equal(t, `unknown product unknown`, event.Reason)
+	require.NoError(t, EventLog().Ack(ctx, id))
+
+	_, _, err = getEvent(ctx)
+	require.ErrorIs(t, err, ErrNoEvents)
+}
+
+func TestIntegration_WarehouseAllocation(t *testing.T) {
+	config, err := pgxpool.ParseConfig(`user=test password=pass host=localhost port=5432 dbname=stock`)
+	require.NoError(t, err)
+
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		pool.Close()
+	}()
+
+	ctx := context.Background()
+	near, err := CreateWarehouse(ctx, domain.Warehouse{ID: uuid.New().String(), Name: `near`, Distance: 1})
+	require.NoError(t, err)
+	far, err := CreateWarehouse(ctx, domain.Warehouse{ID: uuid.New().String(), Name: `far`, Distance: 100})
+	require.NoError(t, err)
+
+	_, err = CreateWarehouse(ctx, near)
+	require.True(t, errors.Is(err, domain.ErrWarehouseExists))
+
+	sku := uuid.New().String()
+	stockProduct(t, sku,
+		domain.Location{Warehouse: near.ID, OnHand: 1},
+		domain.Location{Warehouse: far.ID, OnHand: 2},
+	)
+
+	orderID := uuid.New()
+	stock, err := PersistStock(ctx, orderID, domain.StockOrder{OrderID: orderID, Items: []domain.Item{{SKU: sku, Quantity: 2}}}, domain.Nearest)
+	require.NoError(t, err)
+	require.IsType(t, domain.ActiveStock{}, stock)
+
+	reservation, err := FindReservation(ctx, orderID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []domain.Item{
+		{SKU: sku, Quantity: 1, Warehouse: near.ID},
+		{SKU: sku, Quantity: 1, Warehouse: far.ID},
+	}, reservation.Items)
+
+	product, err := FindProduct(ctx, sku)
+	require.NoError(t, err)
+	require.Equal(t, domain.NewProduct(sku, sku, []domain.Location{
+		{Warehouse: near.ID, Distance: 1, OnHand: 1, Reserved: 1},
+		{Warehouse: far.ID, Distance: 100, OnHand: 2, Reserved: 1},
+	}), product)
+
+	_, err = PersistStock(ctx, orderID, domain.CommitStock{OrderID: orderID}, domain.Nearest)
+	require.NoError(t, err)
+
+	// returned items go back to warehouses which fulfilled them.
+	_, err = PersistStock(ctx, orderID, domain.ReturnStock{
+		OrderID:  orderID,
+		ReturnID: uuid.New(),
+		Items:    []domain.Item{{SKU: sku, Quantity: 1}},
+	}, domain.Nearest)
+	require.NoError(t, err)
+
+	product, err = FindProduct(ctx, sku)
+	require.NoError(t, err)
+	require.Len(t, product.Locations, 2)
+	require.Equal(t, 2, product.OnHand)
+	require.Zero(t, product.Reserved)
+}
+
+func stockProduct(t *testing.T, sku string, locations ...domain.Location) {
+	ctx := context.Background()
+	_, err := pool.Exec(ctx, `INSERT INTO products(sku, name) VALUES ($1, $1)`, sku)
+	require.NoError(t, err)
+	for _, location := range locations {
+		_, err = pool.Exec(ctx, `
+		INSERT INTO warehouse_stock(warehouse_id, sku, on_hand, reserved) VALUES ($1, $2, $3, $4)`,
+			location.Warehouse, sku, location.OnHand, location.Reserved)
+		require.NoError(t, err)
+	}
+}
+
+// getEvent returns the oldest not acknowledged event of log.
+func getEvent(ctx context.Context) (int, schema.StockEvent, error) {
+	entries, err := EventLog().Read(ctx, 1)
+	if err != nil {
+		return 0, schema.StockEvent{}, err
+	}
+	return entries[0].Offset, entries[0].Event, nil
+}
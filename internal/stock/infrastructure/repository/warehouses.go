@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+func CreateWarehouse(ctx context.Context, warehouse domain.Warehouse) (domain.Warehouse, error) {
+	_, err := pool.Exec(ctx, insertWarehouseQuery, warehouse.ID, warehouse.Name, warehouse.Distance)
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return warehouse, nil
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		return domain.Warehouse{}, errors.MarkAndWrapError(domain.ErrWarehouseExists, domain.ErrDomain, `couldn't create warehouse`)
+	default:
+		return domain.Warehouse{}, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't create warehouse`)
+	}
+}
+
+// ListWarehouses returns warehouses, the nearest first.
+func ListWarehouses(ctx context.Context) ([]domain.Warehouse, error) {
+	rows, err := pool.Query(ctx, listWarehousesQuery)
+	if err != nil {
+		return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't list warehouses`)
+	}
+	defer rows.Close()
+
+	warehouses := []domain.Warehouse{}
+	for rows.Next() {
+		var warehouse domain.Warehouse
+		err = rows.Scan(&warehouse.ID, &warehouse.Name, &warehouse.Distance)
+		if err != nil {
+			return nil, errors.MarkAndWrapError(err, ErrInfrastructure, `couldn't scan warehouse`)
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+	if rows.Err() != nil {
+		return nil, errors.MarkAndWrapError(rows.Err(), ErrInfrastructure, `couldn't list warehouses`)
+	}
+	return warehouses, nil
+}
+
+const (
+	insertWarehouseQuery = `INSERT INTO warehouses(warehouse_id, name, distance) VALUES ($1, $2, $3)`
+	listWarehousesQuery  = `SELECT warehouse_id, name, distance FROM warehouses ORDER BY distance, warehouse_id`
+)
@@ -1,7 +1,50 @@
 package service
 
-import "github.com/moeryomenko/saga/internal/stock/domain"
+import (
+	"context"
 
-func HandleEvent(event domain.Event) (domain.Stock, error) {
-	return domain.Apply(event)
+	"github.com/google/uuid"
+
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/internal/stock/infrastructure/eventhandler"
+	"github.com/moeryomenko/saga/internal/stock/infrastructure/repository"
+	"github.com/moeryomenko/saga/pkg/errors"
+	"github.com/moeryomenko/saga/pkg/outbox"
+)
+
+// HandleEvents returns handler which applies event to reservation of order and
+// inventory of its items, strategy allocates items of stocked orders to warehouses.
+// Events rejected by domain, e.g. redelivered restock, are skipped.
+func HandleEvents(strategy domain.AllocationStrategy) eventhandler.EventHandler {
+	return func(ctx context.Context, orderID uuid.UUID, event domain.Event) error {
+		return handleEvent(ctx, orderID, event, strategy)
+	}
+}
+
+func handleEvent(ctx context.Context, orderID uuid.UUID, event domain.Event, strategy domain.AllocationStrategy) error {
+	var err error
+	switch event.(type) {
+	case domain.StockOrder, domain.CancelStock, domain.CommitStock, domain.ReturnStock:
+		_, err = repository.PersistStock(ctx, orderID, event, strategy)
+	default:
+		panic(`bug: invalid domain event`)
+	}
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, domain.ErrDomain):
+		return nil
+	default:
+		return err
+	}
+}
+
+// Producer relays replies from event log to saga orchestrator.
+func Producer(cfg outbox.RelayConfig) func(ctx context.Context) error {
+	return repository.EventLog().Relay(cfg, eventhandler.Publisher())
+}
+
+// PurgeEvents periodically removes published events from event log.
+func PurgeEvents(cfg outbox.RetentionConfig) func(ctx context.Context) error {
+	return repository.EventLog().Retain(cfg)
 }
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/moeryomenko/saga/internal/stock/domain"
+	"github.com/moeryomenko/saga/internal/stock/infrastructure/repository"
+)
+
+func CreateProduct(ctx context.Context, sku, name string) (domain.Product, error) {
+	return repository.CreateProduct(ctx, sku, name)
+}
+
+func GetProduct(ctx context.Context, sku string) (domain.Product, error) {
+	return repository.FindProduct(ctx, sku)
+}
+
+func ListProducts(ctx context.Context) ([]domain.Product, error) {
+	return repository.ListProducts(ctx)
+}
+
+// Restock adds received units to on-hand quantity of product in warehouse.
+func Restock(ctx context.Context, sku, warehouse string, quantity int, note string) (domain.Product, error) {
+	return repository.AdjustStock(ctx, sku, warehouse, quantity, domain.Restock, note)
+}
+
+// AdjustStock changes on-hand quantity of product in warehouse by reason, e.g. after stocktaking.
+func AdjustStock(ctx context.Context, sku, warehouse string, delta int, reason domain.AdjustmentReason, note string) (domain.Product, error) {
+	return repository.AdjustStock(ctx, sku, warehouse, delta, reason, note)
+}
+
+func ListAdjustments(ctx context.Context, sku string) ([]domain.Adjustment, error) {
+	return repository.ListAdjustments(ctx, sku)
+}
+
+func GetReservation(ctx context.Context, orderID uuid.UUID) (repository.Reservation, error) {
+	return repository.FindReservation(ctx, orderID)
+}
+
+func CreateWarehouse(ctx context.Context, warehouse domain.Warehouse) (domain.Warehouse, error) {
+	return repository.CreateWarehouse(ctx, warehouse)
+}
+
+func ListWarehouses(ctx context.Context) ([]domain.Warehouse, error) {
+	return repository.ListWarehouses(ctx)
+}
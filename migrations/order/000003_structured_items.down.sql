@@ -0,0 +1,7 @@
+UPDATE orders
+SET items = (
+	SELECT jsonb_agg(line ->> 'sku')
+	FROM jsonb_array_elements(orders.items) AS line,
+		generate_series(1, (line ->> 'quantity')::INT)
+)
+WHERE jsonb_typeof(items -> 0) = 'object';
@@ -0,0 +1,10 @@
+UPDATE orders
+SET items = (
+	SELECT jsonb_agg(jsonb_build_object('sku', line.sku, 'quantity', line.quantity))
+	FROM (
+		SELECT value AS sku, count(*) AS quantity
+		FROM jsonb_array_elements_text(orders.items)
+		GROUP BY value
+	) AS line
+)
+WHERE jsonb_typeof(items -> 0) = 'string';
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS products (
+	sku        TEXT    NOT NULL,
+	name       TEXT    NOT NULL DEFAULT '',
+	price      DECIMAL NOT NULL CHECK (price >= 0),
+	created_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT NULL,
+	PRIMARY KEY(sku)
+);
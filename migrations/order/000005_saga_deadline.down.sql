@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS orders_deadline_idx;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS deadline;
@@ -0,0 +1,3 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS deadline TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS orders_deadline_idx ON orders(deadline) WHERE kind IN ('pending', 'paid', 'stocked');
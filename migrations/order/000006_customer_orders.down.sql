@@ -0,0 +1 @@
+DROP INDEX IF EXISTS orders_customer_idx;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS orders_customer_idx ON orders(customer_id, created_at DESC, order_id DESC);
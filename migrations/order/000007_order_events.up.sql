@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS order_events (
+	order_id   UUID    NOT NULL,
+	version    INTEGER NOT NULL,
+	event_type TEXT    NOT NULL,
+	payload    JSONB   NOT NULL,
+	created_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(order_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS order_snapshots (
+	order_id    UUID       NOT NULL,
+	version     INTEGER    NOT NULL,
+	customer_id UUID       NOT NULL,
+	items       JSONB      DEFAULT NULL,
+	price       DECIMAL    DEFAULT NULL,
+	payment_id  UUID       DEFAULT NULL,
+	deadline    TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT NULL,
+	kind        order_kind NOT NULL,
+	created_at  TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(order_id, version)
+);
+
+-- orders created before event store have no events, their current state is baseline.
+INSERT INTO order_snapshots(order_id, version, customer_id, items, price, payment_id, deadline, kind)
+SELECT order_id, 0, customer_id, items, price, payment_id, deadline, kind FROM orders
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 0;
+
+UPDATE orders SET version = events.version
+FROM (SELECT order_id, max(version) AS version FROM order_events GROUP BY order_id) AS events
+WHERE orders.order_id = events.order_id;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	key         TEXT    NOT NULL,
+	fingerprint TEXT    NOT NULL,
+	status      INTEGER DEFAULT NULL,
+	headers     JSONB   DEFAULT NULL,
+	body        BYTEA   DEFAULT NULL,
+	created_at  TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	expires_at  TIMESTAMP(6) WITHOUT TIME ZONE NOT NULL,
+	PRIMARY KEY(key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys(expires_at);
@@ -0,0 +1,5 @@
+ALTER TABLE order_snapshots DROP COLUMN IF EXISTS discounts;
+ALTER TABLE orders DROP COLUMN IF EXISTS discounts;
+
+DROP TABLE IF EXISTS promotion_usages;
+DROP TABLE IF EXISTS promotions;
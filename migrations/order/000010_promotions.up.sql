@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS promotions (
+	code        TEXT    NOT NULL,
+	kind        TEXT    NOT NULL CHECK (kind IN ('percentage', 'fixed')),
+	value       DECIMAL NOT NULL CHECK (value > 0),
+	expires_at  TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT NULL,
+	usage_limit INTEGER NOT NULL DEFAULT 0 CHECK (usage_limit >= 0),
+	created_at  TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(code)
+);
+
+CREATE TABLE IF NOT EXISTS promotion_usages (
+	code        TEXT NOT NULL,
+	customer_id UUID NOT NULL,
+	order_id    UUID NOT NULL,
+	created_at  TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(order_id, code)
+);
+
+CREATE INDEX IF NOT EXISTS promotion_usages_customer_idx ON promotion_usages(code, customer_id);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discounts JSONB DEFAULT NULL;
+ALTER TABLE order_snapshots ADD COLUMN IF NOT EXISTS discounts JSONB DEFAULT NULL;
@@ -0,0 +1,11 @@
+UPDATE event_log SET payload = jsonb_set(payload, '{price}', to_jsonb(split_part(payload->>'price', ' ', 1)))
+WHERE payload ? 'price';
+
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_currency_check;
+ALTER TABLE promotions DROP COLUMN IF EXISTS currency;
+
+UPDATE order_events SET payload = payload - 'currency' WHERE event_type = 'processed';
+
+ALTER TABLE order_snapshots DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,18 @@
+-- prices before currency support were in US dollars.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency CHAR(3) DEFAULT NULL;
+UPDATE orders SET currency = 'USD' WHERE price IS NOT NULL;
+
+ALTER TABLE order_snapshots ADD COLUMN IF NOT EXISTS currency CHAR(3) DEFAULT NULL;
+UPDATE order_snapshots SET currency = 'USD' WHERE price IS NOT NULL;
+
+UPDATE order_events SET payload = payload || '{"currency": "USD"}'::jsonb WHERE event_type = 'processed';
+
+ALTER TABLE promotions ADD COLUMN IF NOT EXISTS currency CHAR(3) DEFAULT NULL;
+UPDATE promotions SET currency = 'USD' WHERE kind = 'fixed';
+ALTER TABLE promotions ADD CONSTRAINT promotions_currency_check CHECK (kind = 'percentage' OR currency IS NOT NULL);
+
+-- saga events carry price with currency, e.g. "9.99 USD".
+UPDATE event_log SET payload = jsonb_set(payload, '{price}', to_jsonb((payload->>'price') || ' USD'))
+WHERE payload ? 'price' AND strpos(payload->>'price', ' ') = 0;
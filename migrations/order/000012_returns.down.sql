@@ -0,0 +1,6 @@
+-- postgres can't drop enum values, returned orders are rolled back to completed ones.
+UPDATE order_snapshots SET kind = 'completed' WHERE kind IN ('partially_returned', 'returned');
+UPDATE orders SET kind = 'completed' WHERE kind IN ('partially_returned', 'returned');
+
+ALTER TABLE order_snapshots DROP COLUMN IF EXISTS returns;
+ALTER TABLE orders DROP COLUMN IF EXISTS returns;
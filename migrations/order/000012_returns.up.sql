@@ -0,0 +1,5 @@
+ALTER TYPE order_kind ADD VALUE IF NOT EXISTS 'partially_returned';
+ALTER TYPE order_kind ADD VALUE IF NOT EXISTS 'returned';
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS returns JSONB DEFAULT NULL;
+ALTER TABLE order_snapshots ADD COLUMN IF NOT EXISTS returns JSONB DEFAULT NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS saga_steps;
+DROP TABLE IF EXISTS saga_instances;
+DROP TYPE IF EXISTS saga_step_status;
+DROP TYPE IF EXISTS saga_state;
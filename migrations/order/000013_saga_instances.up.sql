@@ -0,0 +1,64 @@
+CREATE TYPE saga_state AS ENUM ('running', 'completed', 'aborted');
+CREATE TYPE saga_step_status AS ENUM ('pending', 'succeeded', 'failed', 'compensated');
+
+CREATE TABLE IF NOT EXISTS saga_instances (
+	saga_id        UUID       NOT NULL,
+	saga_name      TEXT       NOT NULL,
+	correlation_id TEXT       NOT NULL,
+	state          saga_state NOT NULL,
+	created_at     TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at     TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(saga_id)
+);
+
+CREATE INDEX IF NOT EXISTS saga_instances_correlation_idx ON saga_instances(correlation_id);
+
+CREATE TABLE IF NOT EXISTS saga_steps (
+	saga_id        UUID             NOT NULL,
+	position       INTEGER          NOT NULL,
+	name           TEXT             NOT NULL,
+	status         saga_step_status NOT NULL DEFAULT 'pending',
+	attempts       INTEGER          NOT NULL DEFAULT 0,
+	last_error     TEXT,
+	correlation_id TEXT,
+	created_at     TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at     TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(saga_id, position),
+	CONSTRAINT fk_saga FOREIGN KEY (saga_id)
+		REFERENCES saga_instances(saga_id) ON DELETE CASCADE
+);
+
+-- in-flight sagas were tracked by order state only.
+INSERT INTO saga_instances(saga_id, saga_name, correlation_id, state)
+SELECT order_id, 'order', order_id::text, 'running'
+FROM orders WHERE kind IN ('pending', 'stocked', 'paid');
+
+INSERT INTO saga_steps(saga_id, position, name, status, attempts)
+SELECT order_id, 0, 'stock', CASE WHEN kind = 'stocked' THEN 'succeeded' ELSE 'pending' END::saga_step_status, 1
+FROM orders WHERE kind IN ('pending', 'stocked', 'paid');
+
+INSERT INTO saga_steps(saga_id, position, name, status, attempts, correlation_id)
+SELECT order_id, 1, 'payment', CASE WHEN kind = 'paid' THEN 'succeeded' ELSE 'pending' END::saga_step_status, 1,
+	CASE WHEN kind = 'paid' THEN payment_id::text END
+FROM orders WHERE kind IN ('pending', 'stocked', 'paid');
+
+WITH returns AS (
+	SELECT orders.order_id, r.value AS r
+	FROM orders, jsonb_array_elements(orders.returns) AS r
+	WHERE orders.kind IN ('partially_returned', 'returned')
+		AND NOT ((r.value->>'refunded')::boolean AND (r.value->>'restocked')::boolean)
+)
+INSERT INTO saga_instances(saga_id, saga_name, correlation_id, state)
+SELECT (r->>'id')::uuid, 'return', order_id::text, 'running' FROM returns;
+
+INSERT INTO saga_steps(saga_id, position, name, status, attempts)
+SELECT saga_id, step.position, step.name,
+	CASE WHEN step.done THEN 'succeeded' ELSE 'pending' END::saga_step_status, 1
+FROM saga_instances, orders, jsonb_array_elements(orders.returns) AS r,
+	LATERAL (VALUES
+		(0, 'refund', (r.value->>'refunded')::boolean),
+		(1, 'restock', (r.value->>'restocked')::boolean)
+	) AS step(position, name, done)
+WHERE saga_instances.saga_name = 'return'
+	AND saga_instances.correlation_id = orders.order_id::text
+	AND saga_instances.saga_id = (r.value->>'id')::uuid;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS event_log_archive;
+
+ALTER TABLE event_log DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,7 @@
+-- inbox keeps consumed messages of streams, so redelivered messages are skipped.
+CREATE TABLE IF NOT EXISTS inbox (
+	stream      TEXT NOT NULL,
+	message_id  TEXT NOT NULL,
+	received_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(stream, message_id)
+);
@@ -0,0 +1,3 @@
+-- lease of in-progress request, key is taken over by retry of the same request after lease expires,
+-- e.g. when service crashed before response was stored.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP(6) WITHOUT TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
@@ -0,0 +1,3 @@
+ALTER TABLE event_offset DROP COLUMN IF EXISTS xid_acked;
+DROP INDEX IF EXISTS event_log_xid_id_idx;
+ALTER TABLE event_log DROP COLUMN IF EXISTS xid;
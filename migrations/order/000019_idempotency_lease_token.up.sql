@@ -0,0 +1,3 @@
+-- token of request holding lease, response is stored only by request which holds lease,
+-- not by request which lost lease to retry of the same request.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS lease_token UUID;
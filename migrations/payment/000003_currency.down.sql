@@ -0,0 +1,2 @@
+ALTER TABLE payments DROP COLUMN IF EXISTS currency;
+ALTER TABLE balances DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,3 @@
+-- amounts before currency support were in US dollars.
+ALTER TABLE balances ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS refunds;
+
+-- postgres can't drop enum values, refunded payments are rolled back to completed ones.
+UPDATE payments SET status = 'completed' WHERE status = 'refunded';
+ALTER TABLE payments DROP COLUMN IF EXISTS refunded_amount;
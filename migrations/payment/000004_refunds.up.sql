@@ -0,0 +1,14 @@
+ALTER TYPE payment_status ADD VALUE IF NOT EXISTS 'refunded';
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS refunded_amount DECIMAL NOT NULL DEFAULT 0;
+
+-- refunds makes refund of order return idempotent.
+CREATE TABLE IF NOT EXISTS refunds (
+	return_id  UUID    UNIQUE NOT NULL,
+	payment_id UUID           NOT NULL,
+	amount     DECIMAL        NOT NULL,
+	currency   CHAR(3)        NOT NULL,
+	PRIMARY KEY(return_id),
+	CONSTRAINT fk_payment FOREIGN KEY (payment_id)
+		REFERENCES payments(payment_id)
+);
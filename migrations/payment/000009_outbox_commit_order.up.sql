@@ -0,0 +1,8 @@
+-- events are relayed in order of transactions, which wrote them, so event of transaction
+-- committed later than event with greater id isn't skipped by relay.
+ALTER TABLE event_log ADD COLUMN IF NOT EXISTS xid xid8 NOT NULL DEFAULT pg_current_xact_id();
+CREATE INDEX IF NOT EXISTS event_log_xid_id_idx ON event_log(xid, id);
+
+-- logged events share transaction of migration, so acknowledged ones stay acknowledged.
+ALTER TABLE event_offset ADD COLUMN IF NOT EXISTS xid_acked xid8 NOT NULL DEFAULT '0';
+UPDATE event_offset SET xid_acked = pg_current_xact_id();
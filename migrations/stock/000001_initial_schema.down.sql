@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS restocks;
+DROP TABLE IF EXISTS reservation_items;
+DROP TABLE IF EXISTS reservations;
+DROP TABLE IF EXISTS products;
+DROP TYPE IF EXISTS reservation_status;
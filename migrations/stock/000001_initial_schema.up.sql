@@ -0,0 +1,37 @@
+CREATE TYPE reservation_status AS ENUM ('reserved', 'rejected', 'released', 'committed');
+
+CREATE TABLE IF NOT EXISTS products (
+	sku      TEXT    NOT NULL,
+	name     TEXT    NOT NULL DEFAULT '',
+	on_hand  INTEGER NOT NULL DEFAULT 0,
+	reserved INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY(sku),
+	CONSTRAINT reserved_on_hand CHECK (reserved >= 0 AND reserved <= on_hand)
+);
+
+CREATE TABLE IF NOT EXISTS reservations (
+	reservation_id UUID               NOT NULL,
+	order_id       UUID UNIQUE        NOT NULL,
+	status         reservation_status NOT NULL,
+	reason         TEXT,
+	created_at     TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	updated_at     TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(reservation_id)
+);
+
+CREATE TABLE IF NOT EXISTS reservation_items (
+	reservation_id UUID    NOT NULL,
+	sku            TEXT    NOT NULL,
+	quantity       INTEGER NOT NULL,
+	PRIMARY KEY(reservation_id, sku),
+	CONSTRAINT fk_reservation FOREIGN KEY (reservation_id)
+		REFERENCES reservations(reservation_id) ON DELETE CASCADE
+);
+
+-- restocks makes restock of returned items idempotent.
+CREATE TABLE IF NOT EXISTS restocks (
+	return_id  UUID NOT NULL,
+	order_id   UUID NOT NULL,
+	created_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(return_id)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_adjustments;
+
+DROP TYPE IF EXISTS adjustment_reason;
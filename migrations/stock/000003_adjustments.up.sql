@@ -0,0 +1,17 @@
+CREATE TYPE adjustment_reason AS ENUM ('restock', 'damaged', 'lost', 'found', 'correction');
+
+-- stock_adjustments is audit trail of manual changes of on-hand quantity.
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+	id         BIGSERIAL,
+	sku        TEXT              NOT NULL,
+	delta      INTEGER           NOT NULL,
+	reason     adjustment_reason NOT NULL,
+	note       TEXT              NOT NULL DEFAULT '',
+	on_hand    INTEGER           NOT NULL,
+	created_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(id),
+	CONSTRAINT fk_product FOREIGN KEY (sku)
+		REFERENCES products(sku) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS stock_adjustments_sku_idx ON stock_adjustments(sku, id);
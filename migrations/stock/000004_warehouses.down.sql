@@ -0,0 +1,22 @@
+ALTER TABLE stock_adjustments DROP COLUMN warehouse_id;
+
+CREATE TABLE reservation_items_merged AS
+SELECT reservation_id, sku, SUM(quantity)::INTEGER AS quantity FROM reservation_items GROUP BY reservation_id, sku;
+DELETE FROM reservation_items;
+ALTER TABLE reservation_items
+	DROP CONSTRAINT reservation_items_pkey,
+	DROP COLUMN warehouse_id,
+	ADD PRIMARY KEY(reservation_id, sku);
+INSERT INTO reservation_items(reservation_id, sku, quantity) SELECT reservation_id, sku, quantity FROM reservation_items_merged;
+DROP TABLE reservation_items_merged;
+
+ALTER TABLE products
+	ADD COLUMN on_hand INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN reserved INTEGER NOT NULL DEFAULT 0;
+UPDATE products p SET on_hand = s.on_hand, reserved = s.reserved
+FROM (SELECT sku, SUM(on_hand) AS on_hand, SUM(reserved) AS reserved FROM warehouse_stock GROUP BY sku) s
+WHERE s.sku = p.sku;
+ALTER TABLE products ADD CONSTRAINT reserved_on_hand CHECK (reserved >= 0 AND reserved <= on_hand);
+
+DROP TABLE IF EXISTS warehouse_stock;
+DROP TABLE IF EXISTS warehouses;
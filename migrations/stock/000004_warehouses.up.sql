@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+	warehouse_id TEXT    NOT NULL,
+	name         TEXT    NOT NULL DEFAULT '',
+	distance     INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY(warehouse_id)
+);
+
+-- default warehouse keeps stock of single location setup and receives returns of unknown origin.
+INSERT INTO warehouses(warehouse_id, name) VALUES ('default', 'default') ON CONFLICT DO NOTHING;
+
+-- warehouse_stock is stock of product by warehouse, totals of product are sums of its locations.
+CREATE TABLE IF NOT EXISTS warehouse_stock (
+	warehouse_id TEXT    NOT NULL,
+	sku          TEXT    NOT NULL,
+	on_hand      INTEGER NOT NULL DEFAULT 0,
+	reserved     INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY(warehouse_id, sku),
+	CONSTRAINT fk_warehouse FOREIGN KEY (warehouse_id)
+		REFERENCES warehouses(warehouse_id),
+	CONSTRAINT fk_product FOREIGN KEY (sku)
+		REFERENCES products(sku) ON DELETE CASCADE,
+	CONSTRAINT reserved_on_hand CHECK (reserved >= 0 AND reserved <= on_hand)
+);
+
+INSERT INTO warehouse_stock(warehouse_id, sku, on_hand, reserved)
+SELECT 'default', sku, on_hand, reserved FROM products;
+
+ALTER TABLE products
+	DROP CONSTRAINT reserved_on_hand,
+	DROP COLUMN on_hand,
+	DROP COLUMN reserved;
+
+ALTER TABLE reservation_items ADD COLUMN warehouse_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE reservation_items
+	DROP CONSTRAINT reservation_items_pkey,
+	ADD PRIMARY KEY(reservation_id, sku, warehouse_id);
+
+ALTER TABLE stock_adjustments ADD COLUMN warehouse_id TEXT NOT NULL DEFAULT 'default';
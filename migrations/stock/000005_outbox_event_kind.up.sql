@@ -0,0 +1,4 @@
+-- event log is shared outbox, kind of event is kept alongside its payload.
+ALTER TABLE event_log ADD COLUMN IF NOT EXISTS event_kind TEXT NOT NULL DEFAULT '';
+UPDATE event_log SET event_kind = payload->>'type';
+ALTER TABLE event_log ALTER COLUMN event_kind DROP DEFAULT;
@@ -0,0 +1,12 @@
+-- events logged before retention are treated as created now.
+ALTER TABLE event_log ADD COLUMN IF NOT EXISTS created_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+-- event_log_archive keeps published events removed from log by archive retention policy.
+CREATE TABLE IF NOT EXISTS event_log_archive (
+	id          INTEGER,
+	event_kind  TEXT  NOT NULL,
+	payload     JSONB NOT NULL,
+	created_at  TIMESTAMP(6) WITHOUT TIME ZONE,
+	archived_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY(id)
+);
@@ -0,0 +1,4 @@
+SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots
+WHERE slot_name LIKE 'event_log_relay_%' AND database = current_database() AND NOT active;
+
+DROP PUBLICATION IF EXISTS event_log_relay;
@@ -0,0 +1,7 @@
+-- event_log_relay publication is streamed by replication relay mode, its slot is created by relay.
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_publication WHERE pubname = 'event_log_relay') THEN
+		CREATE PUBLICATION event_log_relay FOR TABLE event_log;
+	END IF;
+END $$;
@@ -6,3 +6,14 @@ import "github.com/cockroachdb/errors"
 func MarkAndWrapError(original, markAs error, wrapWith string) error {
 	return errors.Mark(errors.Wrap(original, wrapWith), markAs)
 }
+
+// Is reports whether any error in err's chain matches reference or
+// err was marked as reference.
+func Is(err, reference error) bool {
+	return errors.Is(err, reference)
+}
+
+// As finds the first error in err's chain that matches target.
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
@@ -0,0 +1,58 @@
+// Package inbox implements inbox of consumed messages. Message is received in
+// transaction of its domain change, so redelivered message, e.g. when consumer
+// crashed before acknowledgment, or event published again by relay is skipped.
+//
+// Inbox is kept in table of service database:
+//
+//	CREATE TABLE inbox (
+//		stream      TEXT NOT NULL,
+//		message_id  TEXT NOT NULL,
+//		received_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+//		PRIMARY KEY(stream, message_id)
+//	);
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/moeryomenko/saga/pkg/outbox"
+)
+
+var ErrDuplicate = errors.New(`message was already handled`)
+
+// Message identifies message of stream.
+type Message struct {
+	Stream string
+	ID     string
+}
+
+// MessageOf returns message of stream identified by id of outbox event, which it carries,
+// so event published again by relay is handled once too. Message without event id is
+// identified by id of stream message.
+func MessageOf(stream, id string, values map[string]any) Message {
+	if eventID, ok := values[outbox.EventIDKey].(string); ok && eventID != `` {
+		return Message{Stream: stream, ID: eventID}
+	}
+	return Message{Stream: stream, ID: id}
+}
+
+// Receive records message in inbox in transaction of domain change,
+// ErrDuplicate is returned if message was already handled.
+func Receive(ctx context.Context, tx pgx.Tx, msg Message) error {
+	tag, err := tx.Exec(ctx, receiveMessageQuery, msg.Stream, msg.ID)
+	if err != nil {
+		return fmt.Errorf(`couldn't receive message: %w`, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf(`%w: %s of %s`, ErrDuplicate, msg.ID, msg.Stream)
+	}
+	return nil
+}
+
+const receiveMessageQuery = `
+	INSERT INTO inbox(stream, message_id) VALUES ($1, $2)
+	ON CONFLICT (stream, message_id) DO NOTHING`
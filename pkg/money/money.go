@@ -0,0 +1,146 @@
+// Package money represents monetary amounts in ISO 4217 currencies.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New(`unknown currency`)
+	ErrCurrencyMismatch = errors.New(`currency mismatch`)
+	ErrInvalidMoney     = errors.New(`invalid money`)
+)
+
+// Currency is ISO 4217 currency code.
+type Currency string
+
+// minorUnits is number of digits after the decimal separator of currency.
+var minorUnits = map[Currency]int32{
+	`AUD`: 2,
+	`BHD`: 3,
+	`CAD`: 2,
+	`CHF`: 2,
+	`CNY`: 2,
+	`EUR`: 2,
+	`GBP`: 2,
+	`INR`: 2,
+	`JPY`: 0,
+	`KRW`: 0,
+	`KWD`: 3,
+	`RUB`: 2,
+	`SEK`: 2,
+	`USD`: 2,
+}
+
+// ParseCurrency returns currency by its code or ErrUnknownCurrency.
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(code))
+	if _, ok := minorUnits[currency]; !ok {
+		return ``, fmt.Errorf(`%w: %q`, ErrUnknownCurrency, code)
+	}
+	return currency, nil
+}
+
+// MinorUnits returns number of digits after the decimal separator.
+func (c Currency) MinorUnits() int32 {
+	return minorUnits[c]
+}
+
+// Money is amount in currency, amount is always rounded to currency minor units.
+type Money struct {
+	Amount   decimal.Decimal
+	Currency Currency
+}
+
+// New returns amount rounded to currency minor units.
+func New(amount decimal.Decimal, currency Currency) Money {
+	return Money{Amount: amount.Round(currency.MinorUnits()), Currency: currency}
+}
+
+// Zero returns zero amount in currency.
+func Zero(currency Currency) Money {
+	return Money{Amount: decimal.Zero, Currency: currency}
+}
+
+// Parse parses money formatted as "<amount> <currency>".
+func Parse(s string) (Money, error) {
+	amount, code, ok := strings.Cut(s, ` `)
+	if !ok {
+		return Money{}, fmt.Errorf(`%w: %q`, ErrInvalidMoney, s)
+	}
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return Money{}, fmt.Errorf(`%w: %q`, ErrInvalidMoney, s)
+	}
+	currency, err := ParseCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(value, currency), nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount.IsNegative()
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, mismatch(m, other)
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, mismatch(m, other)
+	}
+	return Money{Amount: m.Amount.Sub(other.Amount), Currency: m.Currency}, nil
+}
+
+// Cmp compares amounts in the same currency, it returns -1, 0 or +1.
+func (m Money) Cmp(other Money) (int, error) {
+	if m.Currency != other.Currency {
+		return 0, mismatch(m, other)
+	}
+	return m.Amount.Cmp(other.Amount), nil
+}
+
+// Mul returns amount multiplied by quantity.
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount.Mul(decimal.NewFromInt(quantity)), Currency: m.Currency}
+}
+
+// Percent returns given percent of amount rounded to currency minor units.
+func (m Money) Percent(percent decimal.Decimal) Money {
+	return New(m.Amount.Mul(percent).Div(decimal.NewFromInt(100)), m.Currency)
+}
+
+// String formats money as "<amount> <currency>", amount has all currency minor units.
+func (m Money) String() string {
+	return m.Amount.StringFixed(m.Currency.MinorUnits()) + ` ` + string(m.Currency)
+}
+
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func mismatch(a, b Money) error {
+	return fmt.Errorf(`%w: %s and %s`, ErrCurrencyMismatch, a.Currency, b.Currency)
+}
@@ -0,0 +1,87 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testcases := map[string]struct {
+		amount   string
+		currency Currency
+		expected string
+	}{
+		`cents`:        {amount: `10.005`, currency: `USD`, expected: `10.01 USD`},
+		`no minor`:     {amount: `1000.5`, currency: `JPY`, expected: `1001 JPY`},
+		`three digits`: {amount: `1.2345`, currency: `KWD`, expected: `1.235 KWD`},
+		`padding`:      {amount: `3`, currency: `EUR`, expected: `3.00 EUR`},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			money := New(decimal.RequireFromString(tc.amount), tc.currency)
+			require.Equal(t, tc.expected, money.String())
+
+			parsed, err := Parse(money.String())
+			require.NoError(t, err)
+			require.True(t, money.Amount.Equal(parsed.Amount))
+			require.Equal(t, money.Currency, parsed.Currency)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, invalid := range []string{``, `10`, `ten USD`, `10 usd1`} {
+		_, err := Parse(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	_, err := Parse(`10 XXX`)
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestMoney_Add(t *testing.T) {
+	sum, err := New(decimal.NewFromFloat(1.5), `USD`).Add(New(decimal.NewFromFloat(2.25), `USD`))
+	require.NoError(t, err)
+	require.Equal(t, `3.75 USD`, sum.String())
+
+	_, err = New(decimal.NewFromInt(1), `USD`).Add(New(decimal.NewFromInt(1), `EUR`))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = New(decimal.NewFromInt(1), `USD`).Cmp(New(decimal.NewFromInt(1), `EUR`))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestMoney_Percent(t *testing.T) {
+	require.Equal(t, `3.33 USD`, New(decimal.NewFromInt(10), `USD`).Percent(decimal.RequireFromString(`33.33`)).String())
+	require.Equal(t, `333 JPY`, New(decimal.NewFromInt(1000), `JPY`).Percent(decimal.RequireFromString(`33.33`)).String())
+}
+
+func TestRates_Convert(t *testing.T) {
+	rates, err := ParseRates(map[string]string{`EUR/USD`: `1.0812`, `usd/jpy`: `145.5`})
+	require.NoError(t, err)
+
+	converted, err := rates.Convert(New(decimal.NewFromInt(10), `EUR`), `USD`)
+	require.NoError(t, err)
+	require.Equal(t, `10.81 USD`, converted.String())
+
+	converted, err = rates.Convert(New(decimal.NewFromFloat(9.99), `USD`), `JPY`)
+	require.NoError(t, err)
+	require.Equal(t, `1454 JPY`, converted.String())
+
+	same := New(decimal.NewFromInt(10), `EUR`)
+	converted, err = rates.Convert(same, `EUR`)
+	require.NoError(t, err)
+	require.Equal(t, same, converted)
+
+	_, err = rates.Convert(New(decimal.NewFromInt(10), `USD`), `EUR`)
+	require.ErrorIs(t, err, ErrUnknownRate)
+
+	for _, invalid := range []map[string]string{{`EURUSD`: `1`}, {`EUR/XXX`: `1`}, {`EUR/USD`: `-1`}} {
+		_, err = ParseRates(invalid)
+		require.Error(t, err)
+	}
+}
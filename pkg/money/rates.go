@@ -0,0 +1,62 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrUnknownRate = errors.New(`unknown exchange rate`)
+
+// Pair is currency pair, e.g. EUR/USD.
+type Pair struct {
+	Base  Currency
+	Quote Currency
+}
+
+func (p Pair) String() string {
+	return string(p.Base) + `/` + string(p.Quote)
+}
+
+// Rates is table of exchange rates, rate is amount of quote currency for one unit of base currency.
+type Rates map[Pair]decimal.Decimal
+
+// ParseRates parses rates given as pairs, e.g. {"EUR/USD": "1.08"}.
+func ParseRates(rates map[string]string) (Rates, error) {
+	parsed := make(Rates, len(rates))
+	for pair, rate := range rates {
+		base, quote, ok := strings.Cut(pair, `/`)
+		if !ok {
+			return nil, fmt.Errorf(`invalid currency pair %q`, pair)
+		}
+		baseCurrency, err := ParseCurrency(base)
+		if err != nil {
+			return nil, err
+		}
+		quoteCurrency, err := ParseCurrency(quote)
+		if err != nil {
+			return nil, err
+		}
+		value, err := decimal.NewFromString(rate)
+		if err != nil || !value.IsPositive() {
+			return nil, fmt.Errorf(`invalid rate %q of %s`, rate, pair)
+		}
+		parsed[Pair{Base: baseCurrency, Quote: quoteCurrency}] = value
+	}
+	return parsed, nil
+}
+
+// Convert exchanges money to currency, result is rounded to currency minor units.
+func (r Rates) Convert(m Money, to Currency) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	pair := Pair{Base: m.Currency, Quote: to}
+	rate, ok := r[pair]
+	if !ok {
+		return Money{}, fmt.Errorf(`%w: %s`, ErrUnknownRate, pair)
+	}
+	return New(m.Amount.Mul(rate), to), nil
+}
@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// lockKey is key of advisory lock held by leader of relay.
+const lockKey = `event_log_relay`
+
+// Leadership is leadership of relay among service replicas. It's held
+// by session of connection, so it's released when leader dies.
+type Leadership struct {
+	conn *pgxpool.Conn
+}
+
+// TryLead returns leadership of relay or nil, if another replica holds it.
+func (o Outbox[T]) TryLead(ctx context.Context) (*Leadership, error) {
+	conn, err := o.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(`couldn't acquire connection: %w`, err)
+	}
+
+	var acquired bool
+	err = conn.QueryRow(ctx, tryLockQuery, lockKey).Scan(&acquired)
+	if err != nil {
+		conn.Release()
+		return nil, fmt.Errorf(`couldn't lock relay: %w`, err)
+	}
+	if !acquired {
+		conn.Release()
+		return nil, nil
+	}
+	return &Leadership{conn: conn}, nil
+}
+
+// Check returns ErrLeadershipLost if connection which holds leadership is broken,
+// other replica may have taken over then.
+func (l *Leadership) Check(ctx context.Context) error {
+	err := l.conn.Ping(ctx)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf(`%w: %s`, ErrLeadershipLost, err)
+	}
+	return err
+}
+
+// Release gives up leadership and returns connection to pool.
+func (l *Leadership) Release() {
+	_, _ = l.conn.Exec(context.Background(), unlockQuery, lockKey)
+	l.conn.Release()
+}
+
+const (
+	tryLockQuery = `SELECT pg_try_advisory_lock(hashtext($1))`
+	unlockQuery  = `SELECT pg_advisory_unlock(hashtext($1))`
+)
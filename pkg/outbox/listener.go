@@ -0,0 +1,54 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Listener holds connection which listens to notifications of event log.
+type Listener struct {
+	conn *pgxpool.Conn
+}
+
+// Listen starts listening to notifications of events inserted into log.
+func (o Outbox[T]) Listen(ctx context.Context) (*Listener, error) {
+	conn, err := o.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(`couldn't acquire connection: %w`, err)
+	}
+
+	_, err = conn.Exec(ctx, `LISTEN `+channel)
+	if err != nil {
+		conn.Release()
+		return nil, fmt.Errorf(`couldn't listen event log: %w`, err)
+	}
+	return &Listener{conn: conn}, nil
+}
+
+// Wait blocks until event is inserted into log or timeout expires.
+func (l *Listener) Wait(ctx context.Context, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, err := l.conn.Conn().WaitForNotification(waitCtx)
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case waitCtx.Err() != nil:
+		// connection is kept on timeout.
+		return nil
+	default:
+		return fmt.Errorf(`couldn't wait for events: %w`, err)
+	}
+}
+
+// Close stops listening and returns connection to pool.
+func (l *Listener) Close() {
+	_, _ = l.conn.Exec(context.Background(), `UNLISTEN `+channel)
+	l.conn.Release()
+}
@@ -0,0 +1,193 @@
+// Package outbox implements transactional outbox. Events are written to log in
+// transaction of domain change and relayed to message broker in order of log.
+//
+// Log is ordered by transaction of event and its id, since id is taken before
+// commit, so transaction with lower id may commit later. Only events of transactions
+// older than any running one of service database are read, so later committed events
+// never precede acknowledged offset. Transactions of other databases of cluster are
+// ignored, they don't write to log, but long running transaction of service database,
+// e.g. idle in transaction session, holds relay of events until it's over.
+//
+// Outbox is kept in tables of service database:
+//
+//	CREATE TABLE event_log (
+//		id         SERIAL,
+//		xid        xid8  NOT NULL DEFAULT pg_current_xact_id(),
+//		event_kind TEXT  NOT NULL,
+//		payload    JSONB NOT NULL,
+//		created_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+//		PRIMARY KEY(id)
+//	);
+//
+//	CREATE INDEX event_log_xid_id_idx ON event_log(xid, id);
+//
+//	CREATE TABLE event_offset (
+//		xid_acked    xid8 NOT NULL DEFAULT '0',
+//		offset_acked BIGINT
+//	);
+//
+//	INSERT INTO event_offset(offset_acked) VALUES (0);
+//
+// Published events are moved by archive retention policy to table:
+//
+//	CREATE TABLE event_log_archive (
+//		id          INTEGER,
+//		event_kind  TEXT  NOT NULL,
+//		payload     JSONB NOT NULL,
+//		created_at  TIMESTAMP(6) WITHOUT TIME ZONE,
+//		archived_at TIMESTAMP(6) WITHOUT TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+//		PRIMARY KEY(id)
+//	);
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var (
+	ErrNoEvents       = errors.New(`no new event into log`)
+	ErrLeadershipLost = errors.New(`relay leadership lost`)
+)
+
+// Message is event of outbox, it's flattened to values of stream message.
+type Message interface {
+	Kind() string
+	Map() map[string]string
+}
+
+// Entry is event of log with its offset.
+type Entry[T Message] struct {
+	Offset int
+	Event  T
+}
+
+// EventIDKey is key of published message values, which holds id of event. Id is kept
+// when event is published again, e.g. relay failed before acknowledgment, so consumers
+// skip events, which were already handled.
+const EventIDKey = `event_id`
+
+// eventMessage returns values of published event with id of event among logs of services.
+func eventMessage[T Message](source string, entry Entry[T]) map[string]string {
+	values := entry.Event.Map()
+	values[EventIDKey] = source + `/` + strconv.Itoa(entry.Offset)
+	return values
+}
+
+// Outbox is log of events of type T.
+type Outbox[T Message] struct {
+	pool *pgxpool.Pool
+}
+
+// New returns outbox kept in database of pool.
+func New[T Message](pool *pgxpool.Pool) Outbox[T] {
+	return Outbox[T]{pool: pool}
+}
+
+// source returns name of log, it's database of log, so logs of services don't clash.
+func (o Outbox[T]) source() string {
+	return o.pool.Config().ConnConfig.Database
+}
+
+// Write inserts event to log in transaction of domain change,
+// listeners of log are notified when transaction is committed.
+func (o Outbox[T]) Write(ctx context.Context, tx pgx.Tx, event T) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf(`invalid event: %w`, err)
+	}
+
+	_, err = tx.Exec(ctx, insertEventQuery, pgtype.JSONB{Bytes: payload, Status: pgtype.Present}, event.Kind())
+	if err != nil {
+		return fmt.Errorf(`couldn't insert event to log: %w`, err)
+	}
+	return nil
+}
+
+// Read returns not acknowledged events of finished transactions in order of log, up to limit.
+func (o Outbox[T]) Read(ctx context.Context, limit int) ([]Entry[T], error) {
+	rows, err := o.pool.Query(ctx, selectEventsQuery, limit)
+	if err != nil {
+		return nil, fmt.Errorf(`couldn't read events: %w`, err)
+	}
+	defer rows.Close()
+
+	var entries []Entry[T]
+	for rows.Next() {
+		var (
+			entry   Entry[T]
+			payload pgtype.JSONB
+		)
+		err = rows.Scan(&entry.Offset, &payload)
+		if err != nil {
+			return nil, fmt.Errorf(`couldn't scan event: %w`, err)
+		}
+		err = json.Unmarshal(payload.Bytes, &entry.Event)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid event payload: %w`, err)
+		}
+		entries = append(entries, entry)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf(`couldn't read events: %w`, rows.Err())
+	}
+	if len(entries) == 0 {
+		return nil, ErrNoEvents
+	}
+	return entries, nil
+}
+
+// Ack acknowledges events of log up to event with offset, offset never goes back,
+// e.g. on ack of relay which lost leadership.
+func (o Outbox[T]) Ack(ctx context.Context, offset int) error {
+	_, err := o.pool.Exec(ctx, submitOffsetQuery, offset)
+	if err != nil {
+		return fmt.Errorf(`couldn't submit offset: %w`, err)
+	}
+	return nil
+}
+
+// channel is notified with offset of every event inserted into log.
+const channel = `event_log`
+
+const (
+	insertEventQuery = `
+	WITH event AS (INSERT INTO event_log(payload, event_kind) VALUES ($1, $2) RETURNING id)
+	SELECT pg_notify('` + channel + `', id::TEXT) FROM event`
+	// selectEventsQuery reads events of transactions older than horizon, which is the oldest
+	// running transaction of database. Snapshot of cluster has running transactions of all
+	// databases, so transactions known to belong to other databases are skipped, others,
+	// e.g. finished after snapshot, are kept to stay on the safe side.
+	selectEventsQuery = `
+	WITH current_snapshot AS (SELECT pg_current_snapshot() AS snapshot),
+	foreign_xact AS (
+		SELECT backend_xid AS xid FROM pg_stat_activity
+		WHERE datname <> current_database() AND backend_xid IS NOT NULL
+		UNION ALL
+		SELECT transaction FROM pg_prepared_xacts WHERE database <> current_database()
+	),
+	horizon AS (
+		SELECT COALESCE((
+			SELECT running FROM unnest(pg_snapshot_xip(snapshot)) running
+			WHERE NOT EXISTS (SELECT 1 FROM foreign_xact WHERE foreign_xact.xid = running::xid)
+			ORDER BY running LIMIT 1
+		), pg_snapshot_xmax(snapshot)) AS xid
+		FROM current_snapshot
+	)
+	SELECT id, payload
+	FROM event_log
+	WHERE (xid, id) > (SELECT xid_acked, offset_acked FROM event_offset)
+		AND xid < (SELECT xid FROM horizon)
+	ORDER BY xid, id ASC LIMIT $1`
+	submitOffsetQuery = `
+	UPDATE event_offset SET xid_acked = event_log.xid, offset_acked = event_log.id
+	FROM event_log
+	WHERE event_log.id = $1 AND (event_offset.xid_acked, event_offset.offset_acked) < (event_log.xid, event_log.id)`
+)
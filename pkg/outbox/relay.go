@@ -0,0 +1,203 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// Publisher publishes messages in order and returns number of messages published in a row.
+type Publisher interface {
+	Publish(ctx context.Context, messages []map[string]string) (int, error)
+}
+
+// PublisherFunc is function adapter of Publisher.
+type PublisherFunc func(ctx context.Context, messages []map[string]string) (int, error)
+
+func (f PublisherFunc) Publish(ctx context.Context, messages []map[string]string) (int, error) {
+	return f(ctx, messages)
+}
+
+// Relay modes define how relay learns about new events in log.
+const (
+	// PollRelay reads log periodically.
+	PollRelay = `poll`
+	// NotifyRelay is woken up by notifications of inserted events,
+	// log is still polled to pick up missed notifications.
+	NotifyRelay = `notify`
+	// ReplicationRelay streams inserted events from logical replication slot of log
+	// in commit order, it requires wal_level=logical.
+	ReplicationRelay = `replication`
+)
+
+// RelayConfig configures relay of log. Poll and notify relays read events of transactions
+// older than the oldest running transaction of service database, so long running
+// transaction of the database, e.g. backup or idle in transaction session, delays
+// events until it's over, transactions of other databases of cluster don't.
+type RelayConfig struct {
+	Mode string
+	// PollPeriod bounds latency of idle relay.
+	PollPeriod time.Duration
+	// BatchSize is max number of events published at once.
+	BatchSize int
+}
+
+// Relay relays events of log to publisher in batches. Only leader among service
+// replicas relays events, so they are published in order of log, other replicas
+// take over when leader dies. Log is drained without waiting while batches are full.
+func (o Outbox[T]) Relay(cfg RelayConfig, publisher Publisher) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for {
+			leadership, err := o.awaitLeadership(ctx, cfg.PollPeriod)
+			switch {
+			case ctx.Err() != nil:
+				return nil
+			case err != nil:
+				log.Println(err)
+				return err
+			}
+
+			if cfg.Mode == ReplicationRelay {
+				err = o.replicate(ctx, leadership, cfg, publisher)
+			} else {
+				err = o.relay(ctx, leadership, cfg, publisher)
+			}
+			leadership.Release()
+			switch {
+			case ctx.Err() != nil:
+				return nil
+			case errors.Is(err, ErrLeadershipLost):
+				log.Println(err)
+			default:
+				log.Println(err)
+				return err
+			}
+		}
+	}
+}
+
+// awaitLeadership tries to become leader of relay every period.
+func (o Outbox[T]) awaitLeadership(ctx context.Context, period time.Duration) (*Leadership, error) {
+	for {
+		leadership, err := o.TryLead(ctx)
+		if err != nil || leadership != nil {
+			return leadership, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(period):
+		}
+	}
+}
+
+// relay relays events while replica is leader.
+func (o Outbox[T]) relay(ctx context.Context, leadership *Leadership, cfg RelayConfig, publisher Publisher) error {
+	wait, stop, err := o.waiter(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	for {
+		err = wait(ctx)
+		if err != nil {
+			return err
+		}
+
+		for {
+			err = leadership.Check(ctx)
+			if err != nil {
+				return err
+			}
+
+			relayed, err := relayBatch[T](ctx, o, publisher, cfg.BatchSize)
+			if err != nil {
+				return err
+			}
+			if relayed == 0 || relayed < cfg.BatchSize {
+				break
+			}
+		}
+	}
+}
+
+// waiter returns function which blocks until log may have new events and its stop function.
+func (o Outbox[T]) waiter(ctx context.Context, cfg RelayConfig) (func(context.Context) error, func(), error) {
+	switch cfg.Mode {
+	case PollRelay:
+		ticker := time.NewTicker(cfg.PollPeriod)
+		return func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				return nil
+			}
+		}, ticker.Stop, nil
+	case NotifyRelay:
+		listener, err := o.Listen(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(ctx context.Context) error {
+			return listener.Wait(ctx, cfg.PollPeriod)
+		}, listener.Close, nil
+	default:
+		return nil, nil, fmt.Errorf(`unknown relay mode %q`, cfg.Mode)
+	}
+}
+
+// eventLog is log which relay reads.
+type eventLog[T Message] interface {
+	source() string
+	Read(ctx context.Context, limit int) ([]Entry[T], error)
+	Ack(ctx context.Context, offset int) error
+}
+
+// relayBatch publishes batch of events from log and returns its size, the highest
+// offset published in a row is acknowledged even if the rest of batch failed.
+func relayBatch[T Message](ctx context.Context, events eventLog[T], publisher Publisher, batchSize int) (int, error) {
+	entries, err := events.Read(ctx, batchSize)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNoEvents):
+		return 0, nil
+	default:
+		return 0, err
+	}
+
+	messages := make([]map[string]string, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, eventMessage(events.source(), entry))
+	}
+
+	err = publish(ctx, publisher, messages, func(published int) error {
+		return events.Ack(ctx, entries[published-1].Offset)
+	})
+	return len(entries), err
+}
+
+// publish publishes messages with retries, ack is called with number of messages
+// published in a row even if the rest of messages failed.
+func publish(ctx context.Context, publisher Publisher, messages []map[string]string, ack func(published int) error) error {
+	published := 0
+	return backoff.Retry(func() error {
+		n, err := publisher.Publish(ctx, messages[published:])
+		published += n
+		if published == 0 {
+			return err
+		}
+
+		ackErr := ack(published)
+		if err != nil {
+			return err
+		}
+		return ackErr
+	}, backoff.WithContext(backoff.NewExponentialBackOff(), ctx))
+}
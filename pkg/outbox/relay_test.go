@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type message string
+
+func (m message) Kind() string { return `test` }
+
+func (m message) Map() map[string]string { return map[string]string{`id`: string(m)} }
+
+type memoryLog struct {
+	entries []Entry[message]
+	acked   []int
+}
+
+func (l *memoryLog) source() string { return `test` }
+
+func (l *memoryLog) Read(_ context.Context, limit int) ([]Entry[message], error) {
+	if len(l.entries) == 0 {
+		return nil, ErrNoEvents
+	}
+	if len(l.entries) > limit {
+		return l.entries[:limit], nil
+	}
+	return l.entries, nil
+}
+
+func (l *memoryLog) Ack(_ context.Context, offset int) error {
+	l.acked = append(l.acked, offset)
+	return nil
+}
+
+// flakyPublisher fails once after given number of published messages.
+type flakyPublisher struct {
+	failAfter int
+	failed    bool
+	published []string
+}
+
+func (p *flakyPublisher) Publish(_ context.Context, messages []map[string]string) (int, error) {
+	for i, values := range messages {
+		if !p.failed && len(p.published) == p.failAfter {
+			p.failed = true
+			return i, errors.New(`broken pipe`)
+		}
+		p.published = append(p.published, values[`id`])
+	}
+	return len(messages), nil
+}
+
+func TestRelayBatch(t *testing.T) {
+	// log returns events of finished transactions only, so offset 3 is rolled back
+	// and it's never returned later.
+	entries := []Entry[message]{{Offset: 1, Event: `a`}, {Offset: 2, Event: `b`}, {Offset: 4, Event: `c`}}
+
+	testcases := map[string]struct {
+		entries           []Entry[message]
+		batchSize         int
+		failAfter         int
+		expectedRelayed   int
+		expectedPublished []string
+		expectedAcked     []int
+	}{
+		`empty log`: {
+			batchSize: 10,
+			failAfter: -1,
+		},
+		`whole log`: {
+			entries:           entries,
+			batchSize:         10,
+			failAfter:         -1,
+			expectedRelayed:   3,
+			expectedPublished: []string{`a`, `b`, `c`},
+			expectedAcked:     []int{4},
+		},
+		`full batch`: {
+			entries:           entries,
+			batchSize:         2,
+			failAfter:         -1,
+			expectedRelayed:   2,
+			expectedPublished: []string{`a`, `b`},
+			expectedAcked:     []int{2},
+		},
+		`partially published batch`: {
+			entries:           entries,
+			batchSize:         10,
+			failAfter:         2,
+			expectedRelayed:   3,
+			expectedPublished: []string{`a`, `b`, `c`},
+			expectedAcked:     []int{2, 4},
+		},
+		`failed batch`: {
+			entries:           entries,
+			batchSize:         10,
+			failAfter:         0,
+			expectedRelayed:   3,
+			expectedPublished: []string{`a`, `b`, `c`},
+			expectedAcked:     []int{4},
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			events := &memoryLog{entries: tc.entries}
+			publisher := &flakyPublisher{failAfter: tc.failAfter}
+
+			relayed, err := relayBatch[message](context.Background(), events, publisher, tc.batchSize)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRelayed, relayed)
+			require.Equal(t, tc.expectedPublished, publisher.published)
+			require.Equal(t, tc.expectedAcked, events.acked)
+		})
+	}
+}
+
+func TestEventMessage(t *testing.T) {
+	// event published again has the same id.
+	entry := Entry[message]{Offset: 4, Event: `c`}
+	require.Equal(t, map[string]string{`id`: `c`, EventIDKey: `test/4`}, eventMessage(`test`, entry))
+	require.Equal(t, eventMessage(`test`, entry), eventMessage(`test`, entry))
+}
@@ -0,0 +1,250 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgproto3/v2"
+)
+
+// publication is publication of event log, which is streamed by replication relay.
+const publication = `event_log_relay`
+
+// standbyTimeout is period of replication status updates, it keeps connection
+// alive while there are no changes of database.
+const standbyTimeout = 10 * time.Second
+
+// replicate relays events streamed from logical replication slot of event log while
+// replica is leader. Events are published by transactions in commit order, position of
+// slot is confirmed only after events of transaction are published, so unpublished
+// events are streamed again after restart.
+func (o Outbox[T]) replicate(ctx context.Context, leadership *Leadership, cfg RelayConfig, publisher Publisher) error {
+	connConfig := o.pool.Config().ConnConfig
+	slot := slotName(connConfig.Database)
+
+	_, err := o.pool.Exec(ctx, createSlotQuery, slot)
+	if err != nil {
+		return fmt.Errorf(`couldn't create replication slot: %w`, err)
+	}
+
+	// events logged before slot was created, e.g. by other relay mode, aren't streamed.
+	for {
+		relayed, err := relayBatch[T](ctx, o, publisher, cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		if relayed == 0 || relayed < cfg.BatchSize {
+			break
+		}
+	}
+
+	connConfig.RuntimeParams[`replication`] = `database`
+	conn, err := pgconn.ConnectConfig(ctx, &connConfig.Config)
+	if err != nil {
+		return fmt.Errorf(`couldn't connect for replication: %w`, err)
+	}
+	defer conn.Close(context.Background())
+
+	err = pglogrepl.StartReplication(ctx, conn, slot, 0, pglogrepl.StartReplicationOptions{
+		PluginArgs: []string{`proto_version '1'`, `publication_names '` + publication + `'`},
+	})
+	if err != nil {
+		return fmt.Errorf(`couldn't start replication: %w`, err)
+	}
+
+	stream := replicationStream[T]{relation: make(map[uint32]*pglogrepl.RelationMessage)}
+	var confirmed pglogrepl.LSN
+	nextStatus := time.Now().Add(standbyTimeout)
+	for {
+		if !time.Now().Before(nextStatus) {
+			err = pglogrepl.SendStandbyStatusUpdate(ctx, conn, pglogrepl.StandbyStatusUpdate{WALWritePosition: confirmed})
+			if err != nil {
+				return fmt.Errorf(`couldn't confirm replication position: %w`, err)
+			}
+			nextStatus = time.Now().Add(standbyTimeout)
+		}
+
+		receiveCtx, cancel := context.WithDeadline(ctx, nextStatus)
+		msg, err := conn.ReceiveMessage(receiveCtx)
+		cancel()
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case pgconn.Timeout(err):
+			continue
+		default:
+			return fmt.Errorf(`couldn't receive replication message: %w`, err)
+		}
+
+		var data []byte
+		switch msg := msg.(type) {
+		case *pgproto3.CopyData:
+			data = msg.Data
+		case *pgproto3.ErrorResponse:
+			return fmt.Errorf(`replication failed: %w`, pgconn.ErrorResponseToPgError(msg))
+		default:
+			continue
+		}
+
+		switch data[0] {
+		case pglogrepl.PrimaryKeepaliveMessageByteID:
+			keepalive, err := pglogrepl.ParsePrimaryKeepaliveMessage(data[1:])
+			if err != nil {
+				return fmt.Errorf(`invalid keepalive message: %w`, err)
+			}
+			// slot retains WAL of all databases of cluster, so position is moved
+			// forward while there are no events, e.g. database of service is idle.
+			confirmed = stream.keepalivePosition(confirmed, keepalive.ServerWALEnd)
+			if keepalive.ReplyRequested {
+				nextStatus = time.Time{}
+			}
+		case pglogrepl.XLogDataByteID:
+			xld, err := pglogrepl.ParseXLogData(data[1:])
+			if err != nil {
+				return fmt.Errorf(`invalid replication message: %w`, err)
+			}
+
+			commit, err := stream.decode(xld.WALData)
+			if err != nil {
+				return err
+			}
+			if commit == nil {
+				continue
+			}
+
+			// transaction without events is confirmed too, it holds no events to publish.
+			err = o.publishCommitted(ctx, leadership, publisher, stream.entries)
+			if err != nil {
+				return err
+			}
+			stream.entries = stream.entries[:0]
+			confirmed = commit.TransactionEndLSN
+		}
+	}
+}
+
+// publishCommitted publishes events of committed transaction and acknowledges them in log,
+// so retention of log and other relay modes keep up with replication.
+func (o Outbox[T]) publishCommitted(ctx context.Context, leadership *Leadership, publisher Publisher, entries []Entry[T]) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	err := leadership.Check(ctx)
+	if err != nil {
+		return err
+	}
+
+	messages := make([]map[string]string, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, eventMessage(o.source(), entry))
+	}
+	return publish(ctx, publisher, messages, func(published int) error {
+		return o.Ack(ctx, entries[published-1].Offset)
+	})
+}
+
+// replicationStream decodes events of transactions streamed by pgoutput plugin.
+type replicationStream[T Message] struct {
+	relation map[uint32]*pglogrepl.RelationMessage
+	// entries are events inserted by current transaction.
+	entries []Entry[T]
+	// inTransaction is set while changes of transaction are streamed.
+	inTransaction bool
+}
+
+// keepalivePosition returns position of slot, which can be confirmed on keepalive
+// with end of WAL sent by server. All changes up to the end are streamed already,
+// so the end is confirmed unless transaction is streamed or its events aren't published.
+func (s *replicationStream[T]) keepalivePosition(confirmed, walEnd pglogrepl.LSN) pglogrepl.LSN {
+	if s.inTransaction || len(s.entries) > 0 || walEnd <= confirmed {
+		return confirmed
+	}
+	return walEnd
+}
+
+// decode decodes logical replication message and returns commit message, when transaction is over.
+func (s *replicationStream[T]) decode(data []byte) (*pglogrepl.CommitMessage, error) {
+	msg, err := pglogrepl.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid logical replication message: %w`, err)
+	}
+
+	switch msg := msg.(type) {
+	case *pglogrepl.BeginMessage:
+		s.inTransaction = true
+	case *pglogrepl.RelationMessage:
+		s.relation[msg.RelationID] = msg
+	case *pglogrepl.InsertMessage:
+		relation, ok := s.relation[msg.RelationID]
+		if !ok {
+			return nil, fmt.Errorf(`unknown relation %d`, msg.RelationID)
+		}
+		if relation.RelationName != `event_log` {
+			return nil, nil
+		}
+
+		entry, err := decodeEntry[T](relation, msg.Tuple)
+		if err != nil {
+			return nil, err
+		}
+		s.entries = append(s.entries, entry)
+	case *pglogrepl.CommitMessage:
+		s.inTransaction = false
+		return msg, nil
+	}
+	return nil, nil
+}
+
+// decodeEntry decodes inserted row of event log.
+func decodeEntry[T Message](relation *pglogrepl.RelationMessage, tuple *pglogrepl.TupleData) (entry Entry[T], err error) {
+	var hasID, hasPayload bool
+	for i, column := range tuple.Columns {
+		if i >= len(relation.Columns) || column.DataType != pglogrepl.TupleDataTypeText {
+			continue
+		}
+
+		switch relation.Columns[i].Name {
+		case `id`:
+			entry.Offset, err = strconv.Atoi(string(column.Data))
+			if err != nil {
+				return entry, fmt.Errorf(`invalid event offset: %w`, err)
+			}
+			hasID = true
+		case `payload`:
+			err = json.Unmarshal(column.Data, &entry.Event)
+			if err != nil {
+				return entry, fmt.Errorf(`invalid event payload: %w`, err)
+			}
+			hasPayload = true
+		}
+	}
+	if !hasID || !hasPayload {
+		return entry, fmt.Errorf(`incomplete row of event log`)
+	}
+	return entry, nil
+}
+
+// slotName returns name of replication slot of database, slots are
+// shared by databases of cluster.
+func slotName(database string) string {
+	return `event_log_relay_` + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(database))
+}
+
+// createSlotQuery creates replication slot unless it exists, slot keeps position
+// of relay between restarts.
+const createSlotQuery = `
+	SELECT pg_create_logical_replication_slot($1, 'pgoutput')
+	WHERE NOT EXISTS (SELECT 1 FROM pg_replication_slots WHERE slot_name = $1)`
@@ -0,0 +1,151 @@
+package outbox
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/jackc/pglogrepl"
+	"github.com/stretchr/testify/require"
+)
+
+// relationMessage encodes pgoutput relation message of table with text columns.
+func relationMessage(id uint32, table string, columns ...string) []byte {
+	msg := []byte{'R'}
+	msg = binary.BigEndian.AppendUint32(msg, id)
+	msg = append(msg, "public\x00"+table+"\x00"...)
+	msg = append(msg, 'd')
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(columns)))
+	for _, column := range columns {
+		msg = append(msg, 0)
+		msg = append(msg, column+"\x00"...)
+		msg = binary.BigEndian.AppendUint32(msg, 25)
+		msg = binary.BigEndian.AppendUint32(msg, 0xffffffff)
+	}
+	return msg
+}
+
+// insertMessage encodes pgoutput insert message of row with text values.
+func insertMessage(relation uint32, values ...string) []byte {
+	msg := []byte{'I'}
+	msg = binary.BigEndian.AppendUint32(msg, relation)
+	msg = append(msg, 'N')
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(values)))
+	for _, value := range values {
+		msg = append(msg, 't')
+		msg = binary.BigEndian.AppendUint32(msg, uint32(len(value)))
+		msg = append(msg, value...)
+	}
+	return msg
+}
+
+// beginMessage encodes pgoutput begin message of transaction.
+func beginMessage(final pglogrepl.LSN) []byte {
+	msg := []byte{'B'}
+	msg = binary.BigEndian.AppendUint64(msg, uint64(final))
+	msg = binary.BigEndian.AppendUint64(msg, 0)
+	return binary.BigEndian.AppendUint32(msg, 1)
+}
+
+// commitMessage encodes pgoutput commit message of transaction.
+func commitMessage(end pglogrepl.LSN) []byte {
+	msg := []byte{'C', 0}
+	msg = binary.BigEndian.AppendUint64(msg, uint64(end-1))
+	msg = binary.BigEndian.AppendUint64(msg, uint64(end))
+	return binary.BigEndian.AppendUint64(msg, 0)
+}
+
+func TestReplicationStream(t *testing.T) {
+	testcases := map[string]struct {
+		messages        [][]byte
+		expectedEntries []Entry[message]
+		expectedCommit  pglogrepl.LSN
+		expectedErr     bool
+	}{
+		`events of transaction`: {
+			messages: [][]byte{
+				relationMessage(1, `event_log`, `id`, `payload`, `event_kind`),
+				insertMessage(1, `1`, `"a"`, `test`),
+				insertMessage(1, `2`, `"b"`, `test`),
+				commitMessage(100),
+			},
+			expectedEntries: []Entry[message]{{Offset: 1, Event: `a`}, {Offset: 2, Event: `b`}},
+			expectedCommit:  100,
+		},
+		`transaction without events`: {
+			messages: [][]byte{
+				relationMessage(2, `orders`, `order_id`),
+				insertMessage(2, `1`),
+				commitMessage(200),
+			},
+			expectedCommit: 200,
+		},
+		`unknown relation`: {
+			messages:    [][]byte{insertMessage(1, `1`, `"a"`, `test`)},
+			expectedErr: true,
+		},
+		`invalid payload`: {
+			messages: [][]byte{
+				relationMessage(1, `event_log`, `id`, `payload`, `event_kind`),
+				insertMessage(1, `1`, `{`, `test`),
+			},
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			stream := replicationStream[message]{relation: make(map[uint32]*pglogrepl.RelationMessage)}
+
+			var (
+				commit *pglogrepl.CommitMessage
+				err    error
+			)
+			for _, msg := range tc.messages {
+				commit, err = stream.decode(msg)
+				if err != nil {
+					break
+				}
+			}
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, commit)
+			require.Equal(t, tc.expectedCommit, commit.TransactionEndLSN)
+			require.Equal(t, tc.expectedEntries, stream.entries)
+		})
+	}
+}
+
+func TestKeepalivePosition(t *testing.T) {
+	stream := replicationStream[message]{relation: make(map[uint32]*pglogrepl.RelationMessage)}
+
+	// database of service is idle, while other databases of cluster write WAL.
+	require.Equal(t, pglogrepl.LSN(300), stream.keepalivePosition(100, 300))
+	require.Equal(t, pglogrepl.LSN(300), stream.keepalivePosition(300, 200))
+
+	_, err := stream.decode(beginMessage(400))
+	require.NoError(t, err)
+	require.Equal(t, pglogrepl.LSN(300), stream.keepalivePosition(300, 500))
+
+	for _, msg := range [][]byte{
+		relationMessage(1, `event_log`, `id`, `payload`, `event_kind`),
+		insertMessage(1, `1`, `"a"`, `test`),
+		commitMessage(400),
+	} {
+		_, err = stream.decode(msg)
+		require.NoError(t, err)
+	}
+	// events of committed transaction aren't published yet.
+	require.Equal(t, pglogrepl.LSN(300), stream.keepalivePosition(300, 500))
+
+	stream.entries = stream.entries[:0]
+	require.Equal(t, pglogrepl.LSN(500), stream.keepalivePosition(400, 500))
+}
+
+func TestSlotName(t *testing.T) {
+	require.Equal(t, `event_log_relay_orders`, slotName(`orders`))
+	require.Equal(t, `event_log_relay_order_db`, slotName(`Order-DB`))
+}
@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgtype"
+)
+
+// Retention policies define what happens with published events.
+const (
+	// DeleteRetention deletes published events.
+	DeleteRetention = `delete`
+	// ArchiveRetention moves published events to event_log_archive table.
+	ArchiveRetention = `archive`
+)
+
+type RetentionConfig struct {
+	Policy string
+	// Age is age of published events to be removed.
+	Age time.Duration
+	// Period is period of retention runs.
+	Period time.Duration
+	// BatchSize is max number of events removed in one transaction.
+	BatchSize int
+}
+
+// Retain periodically removes published events older than age by policy
+// and reports number of removed events.
+func (o Outbox[T]) Retain(cfg RetentionConfig) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if _, ok := retentionQueries[cfg.Policy]; !ok {
+			return fmt.Errorf(`unknown retention policy %q`, cfg.Policy)
+		}
+
+		retentionTicker := time.NewTicker(cfg.Period)
+		defer retentionTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-retentionTicker.C:
+				removed, err := o.Purge(ctx, cfg.Policy, time.Now().Add(-cfg.Age), cfg.BatchSize)
+				if removed > 0 {
+					log.Printf(`outbox retention (%s): removed %d events`, cfg.Policy, removed)
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Println(err)
+				}
+			}
+		}
+	}
+}
+
+// Purge removes acknowledged events created before given time by policy and returns
+// number of removed events. Events are removed in transactions of batchSize events,
+// so relay and writers of log aren't blocked for long.
+func (o Outbox[T]) Purge(ctx context.Context, policy string, before time.Time, batchSize int) (int64, error) {
+	query, ok := retentionQueries[policy]
+	if !ok {
+		return 0, fmt.Errorf(`unknown retention policy %q`, policy)
+	}
+
+	var removed int64
+	for {
+		tag, err := o.pool.Exec(ctx, query, pgtype.Timestamp{Time: before.UTC(), Status: pgtype.Present}, batchSize)
+		if err != nil {
+			return removed, fmt.Errorf(`couldn't purge events: %w`, err)
+		}
+
+		removed += tag.RowsAffected()
+		if tag.RowsAffected() == 0 || tag.RowsAffected() < int64(batchSize) {
+			return removed, nil
+		}
+	}
+}
+
+// purgeBatch selects batch of acknowledged events, which are old enough,
+// replicas skip events locked by each other.
+const purgeBatch = `
+	SELECT id FROM event_log
+	WHERE (xid, id) <= (SELECT xid_acked, offset_acked FROM event_offset) AND created_at < $1
+	ORDER BY xid, id ASC LIMIT $2
+	FOR UPDATE SKIP LOCKED`
+
+var retentionQueries = map[string]string{
+	DeleteRetention: `DELETE FROM event_log WHERE id IN (` + purgeBatch + `)`,
+	ArchiveRetention: `
+	WITH removed AS (
+		DELETE FROM event_log WHERE id IN (` + purgeBatch + `)
+		RETURNING id, event_kind, payload, created_at
+	)
+	INSERT INTO event_log_archive(id, event_kind, payload, created_at)
+	SELECT id, event_kind, payload, created_at FROM removed`,
+}
@@ -0,0 +1,80 @@
+package saga
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Store persists saga instances, not started saga is loaded as zero instance.
+type Store interface {
+	Load(ctx context.Context, sagaID uuid.UUID) (Instance, error)
+	Save(ctx context.Context, instance Instance) error
+}
+
+// Transport sends saga commands to participants.
+type Transport interface {
+	Send(ctx context.Context, sagaID uuid.UUID, command MessageType) error
+}
+
+// Orchestrator drives saga instances by definition, commands are sent
+// after instance is saved, so transport should be transactional with store.
+type Orchestrator struct {
+	definition Definition
+	store      Store
+	transport  Transport
+}
+
+func New(definition Definition, store Store, transport Transport) Orchestrator {
+	return Orchestrator{definition: definition, store: store, transport: transport}
+}
+
+// Start starts saga for entity identified by correlation ID.
+func (o Orchestrator) Start(ctx context.Context, sagaID uuid.UUID, correlationID string) error {
+	return o.transition(ctx, sagaID, func(instance Instance) (Instance, []MessageType, error) {
+		if instance.State == NotStarted {
+			instance.ID, instance.CorrelationID = sagaID, correlationID
+		}
+		return o.definition.Start(instance)
+	})
+}
+
+func (o Orchestrator) Reply(ctx context.Context, sagaID uuid.UUID, reply Reply) error {
+	return o.transition(ctx, sagaID, func(instance Instance) (Instance, []MessageType, error) {
+		return o.definition.Reply(instance, reply)
+	})
+}
+
+func (o Orchestrator) Abort(ctx context.Context, sagaID uuid.UUID, reason string) error {
+	return o.transition(ctx, sagaID, func(instance Instance) (Instance, []MessageType, error) {
+		return o.definition.Abort(instance, reason)
+	})
+}
+
+func (o Orchestrator) transition(ctx context.Context, sagaID uuid.UUID, apply func(Instance) (Instance, []MessageType, error)) error {
+	instance, err := o.store.Load(ctx, sagaID)
+	if err != nil {
+		return err
+	}
+
+	instance, commands, err := apply(instance)
+	if err != nil {
+		return err
+	}
+
+	if instance.State == NotStarted {
+		return nil
+	}
+	err = o.store.Save(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	for _, command := range commands {
+		err = o.transport.Send(ctx, sagaID, command)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
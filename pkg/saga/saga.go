@@ -0,0 +1,205 @@
+// Package saga implements orchestration of distributed transactions. Saga is
+// declared as steps of participants, which are started concurrently, and is
+// either completed, when all steps succeeded, or aborted and compensated.
+package saga
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageType is type of command sent to participants or their reply.
+type MessageType string
+
+// Step is local transaction of saga participant.
+type Step struct {
+	Name string
+	// Action is command which starts step.
+	Action MessageType
+	// Success and Failure are replies of participant on action,
+	// step without failure reply can't fail.
+	Success MessageType
+	Failure MessageType
+	// Compensation is command which undoes step of aborted saga.
+	Compensation MessageType
+}
+
+// Definition declares saga, steps share commands, so participants which
+// consume the same command are started by single message.
+type Definition struct {
+	Name  string
+	Steps []Step
+	// Completion is command sent when all steps succeeded, optional.
+	Completion MessageType
+}
+
+// State is state of saga instance.
+type State string
+
+const (
+	NotStarted State = ``
+	Running    State = `running`
+	Completed  State = `completed`
+	Aborted    State = `aborted`
+)
+
+// StepStatus is status of saga step.
+type StepStatus string
+
+const (
+	StepPending     StepStatus = `pending`
+	StepSucceeded   StepStatus = `succeeded`
+	StepFailed      StepStatus = `failed`
+	StepCompensated StepStatus = `compensated`
+)
+
+type StepState struct {
+	Name   string
+	Status StepStatus
+	// Attempts is number of sent step actions.
+	Attempts int
+	// LastError is reason of step failure or compensation.
+	LastError string
+	// CorrelationID identifies result of step in participant, e.g. payment.
+	CorrelationID string
+	// UpdatedAt is set by store.
+	UpdatedAt time.Time
+}
+
+// Instance is saga execution, zero value is not started saga.
+type Instance struct {
+	ID   uuid.UUID
+	Saga string
+	// CorrelationID identifies entity which saga is started for, e.g. order.
+	CorrelationID string
+	State         State
+	Steps         []StepState
+	// CreatedAt and UpdatedAt are set by store.
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Reply is reply of participant on step action.
+type Reply struct {
+	Type          MessageType
+	CorrelationID string
+	// Reason describes failure, by default it's reply type.
+	Reason string
+}
+
+var (
+	ErrUnknownReply = errors.New(`unknown saga reply`)
+	ErrSagaStarted  = errors.New(`saga already started`)
+
+	// ErrRejected marks error of message, which can't be handled by redelivery too,
+	// e.g. message is malformed or it's rejected by domain, such message is acknowledged.
+	ErrRejected = errors.New(`message is rejected`)
+)
+
+// Start starts saga and returns actions of all steps.
+func (d Definition) Start(instance Instance) (Instance, []MessageType, error) {
+	if instance.State != NotStarted {
+		return instance, nil, ErrSagaStarted
+	}
+
+	started := Instance{ID: instance.ID, Saga: d.Name, CorrelationID: instance.CorrelationID, State: Running}
+	var commands []MessageType
+	for _, step := range d.Steps {
+		started.Steps = append(started.Steps, StepState{Name: step.Name, Status: StepPending, Attempts: 1})
+		commands = appendCommand(commands, step.Action)
+	}
+	return started, commands, nil
+}
+
+// Reply applies reply of participant. Saga is completed when all steps succeeded
+// and aborted on the first failure. Replies on finished saga are ignored,
+// because participants may reply after saga was aborted.
+func (d Definition) Reply(instance Instance, reply Reply) (Instance, []MessageType, error) {
+	index, succeeded := d.step(reply.Type)
+	if index < 0 {
+		return instance, nil, ErrUnknownReply
+	}
+	if instance.State != Running {
+		return instance, nil, nil
+	}
+
+	instance = instance.copy()
+	if reply.CorrelationID != `` {
+		instance.Steps[index].CorrelationID = reply.CorrelationID
+	}
+	if !succeeded {
+		reason := reply.Reason
+		if reason == `` {
+			reason = string(reply.Type)
+		}
+		instance.Steps[index].Status = StepFailed
+		instance.Steps[index].LastError = reason
+		return d.compensate(instance, reason)
+	}
+
+	instance.Steps[index].Status = StepSucceeded
+	for _, step := range instance.Steps {
+		if step.Status != StepSucceeded {
+			return instance, nil, nil
+		}
+	}
+	instance.State = Completed
+	return instance, appendCommand(nil, d.Completion), nil
+}
+
+// Abort aborts running saga by reason and returns compensations of not failed
+// steps, abort of not started or finished saga does nothing.
+func (d Definition) Abort(instance Instance, reason string) (Instance, []MessageType, error) {
+	if instance.State != Running {
+		return instance, nil, nil
+	}
+	return d.compensate(instance.copy(), reason)
+}
+
+func (d Definition) compensate(instance Instance, reason string) (Instance, []MessageType, error) {
+	var commands []MessageType
+	for i, step := range d.Steps {
+		switch instance.Steps[i].Status {
+		case StepPending, StepSucceeded:
+			instance.Steps[i].Status = StepCompensated
+			instance.Steps[i].LastError = reason
+			commands = appendCommand(commands, step.Compensation)
+		}
+	}
+	instance.State = Aborted
+	return instance, commands, nil
+}
+
+// step returns index of step, which has given reply, and whether reply is success.
+func (d Definition) step(reply MessageType) (int, bool) {
+	for i, step := range d.Steps {
+		switch reply {
+		case step.Success:
+			return i, true
+		case step.Failure:
+			if step.Failure != `` {
+				return i, false
+			}
+		}
+	}
+	return -1, false
+}
+
+func (i Instance) copy() Instance {
+	i.Steps = append([]StepState{}, i.Steps...)
+	return i
+}
+
+func appendCommand(commands []MessageType, command MessageType) []MessageType {
+	if command == `` {
+		return commands
+	}
+	for _, c := range commands {
+		if c == command {
+			return commands
+		}
+	}
+	return append(commands, command)
+}
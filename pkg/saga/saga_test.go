@@ -0,0 +1,144 @@
+package saga
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var definition = Definition{
+	Name: `order`,
+	Steps: []Step{
+		{Name: `stock`, Action: `new_order`, Success: `stock_confirmed`, Failure: `stock_failed`, Compensation: `cancel_order`},
+		{Name: `payment`, Action: `new_order`, Success: `payments_confirmed`, Failure: `payments_failed`, Compensation: `cancel_order`},
+		{Name: `notification`, Action: `notify`, Success: `notified`},
+	},
+	Completion: `complete_order`,
+}
+
+func TestDefinition(t *testing.T) {
+	testcases := map[string]struct {
+		replies          []MessageType
+		abort            bool
+		expectedState    State
+		expectedSteps    []StepStatus
+		expectedCommands []MessageType
+		expectedErr      error
+	}{
+		`running saga`: {
+			replies:       []MessageType{`stock_confirmed`},
+			expectedState: Running,
+			expectedSteps: []StepStatus{StepSucceeded, StepPending, StepPending},
+		},
+		`completed saga`: {
+			replies:          []MessageType{`stock_confirmed`, `notified`, `payments_confirmed`},
+			expectedState:    Completed,
+			expectedSteps:    []StepStatus{StepSucceeded, StepSucceeded, StepSucceeded},
+			expectedCommands: []MessageType{`complete_order`},
+		},
+		`duplicated reply`: {
+			replies:       []MessageType{`stock_confirmed`, `stock_confirmed`},
+			expectedState: Running,
+			expectedSteps: []StepStatus{StepSucceeded, StepPending, StepPending},
+		},
+		`failed step`: {
+			replies:          []MessageType{`stock_confirmed`, `payments_failed`},
+			expectedState:    Aborted,
+			expectedSteps:    []StepStatus{StepCompensated, StepFailed, StepCompensated},
+			expectedCommands: []MessageType{`cancel_order`},
+		},
+		`reply on aborted saga`: {
+			replies:       []MessageType{`payments_failed`, `stock_confirmed`},
+			expectedState: Aborted,
+			expectedSteps: []StepStatus{StepCompensated, StepFailed, StepCompensated},
+		},
+		`aborted saga`: {
+			replies:          []MessageType{`payments_confirmed`},
+			abort:            true,
+			expectedState:    Aborted,
+			expectedSteps:    []StepStatus{StepCompensated, StepCompensated, StepCompensated},
+			expectedCommands: []MessageType{`cancel_order`},
+		},
+		`unknown reply`: {
+			replies:     []MessageType{`unknown`},
+			expectedErr: ErrUnknownReply,
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			instance, commands, err := definition.Start(Instance{ID: uuid.New()})
+			require.NoError(t, err)
+			require.Equal(t, []MessageType{`new_order`, `notify`}, commands)
+
+			for _, reply := range tc.replies {
+				instance, commands, err = definition.Reply(instance, Reply{Type: reply})
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+					return
+				}
+				require.NoError(t, err)
+			}
+			if tc.abort {
+				instance, commands, err = definition.Abort(instance, `canceled`)
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.expectedState, instance.State)
+			require.Equal(t, tc.expectedCommands, commands)
+			steps := make([]StepStatus, 0, len(instance.Steps))
+			for _, step := range instance.Steps {
+				steps = append(steps, step.Status)
+			}
+			require.Equal(t, tc.expectedSteps, steps)
+		})
+	}
+}
+
+func TestOrchestrator(t *testing.T) {
+	ctx := context.Background()
+	store := memoryStore{}
+	transport := &memoryTransport{}
+	orchestrator := New(definition, store, transport)
+	sagaID := uuid.New()
+
+	// abort of not started saga does nothing.
+	require.NoError(t, orchestrator.Abort(ctx, sagaID, `canceled`))
+	require.Empty(t, store)
+	require.Empty(t, transport.commands)
+
+	require.NoError(t, orchestrator.Start(ctx, sagaID, `order`))
+	require.ErrorIs(t, orchestrator.Start(ctx, sagaID, `order`), ErrSagaStarted)
+	require.NoError(t, orchestrator.Reply(ctx, sagaID, Reply{Type: `payments_confirmed`, CorrelationID: `payment`}))
+	require.NoError(t, orchestrator.Reply(ctx, sagaID, Reply{Type: `stock_failed`, Reason: `out of stock`}))
+
+	instance := store[sagaID]
+	require.Equal(t, Aborted, instance.State)
+	require.Equal(t, `order`, instance.CorrelationID)
+	require.Equal(t, StepState{Name: `stock`, Status: StepFailed, Attempts: 1, LastError: `out of stock`}, instance.Steps[0])
+	require.Equal(t, StepState{Name: `payment`, Status: StepCompensated, Attempts: 1, LastError: `out of stock`, CorrelationID: `payment`}, instance.Steps[1])
+	require.Equal(t, []MessageType{`new_order`, `notify`, `cancel_order`}, transport.commands)
+}
+
+type memoryStore map[uuid.UUID]Instance
+
+func (s memoryStore) Load(_ context.Context, sagaID uuid.UUID) (Instance, error) {
+	return s[sagaID], nil
+}
+
+func (s memoryStore) Save(_ context.Context, instance Instance) error {
+	s[instance.ID] = instance
+	return nil
+}
+
+type memoryTransport struct {
+	commands []MessageType
+}
+
+func (t *memoryTransport) Send(_ context.Context, _ uuid.UUID, command MessageType) error {
+	t.commands = append(t.commands, command)
+	return nil
+}
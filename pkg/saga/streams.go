@@ -0,0 +1,208 @@
+package saga
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/moeryomenko/saga/pkg/errors"
+)
+
+const (
+	// CommandStream carries commands of orchestrator to participants.
+	CommandStream = `orders_stream`
+	// ReplyStream carries replies of participants to orchestrator.
+	ReplyStream = `confirmation_stream`
+)
+
+// MessageHandler handles message values of stream. Id of message is kept by
+// redelivery of pending message, but event published again by outbox relay is
+// new message with new id, so consumers identify it by outbox event id of values.
+type MessageHandler func(ctx context.Context, id string, values map[string]any) error
+
+const (
+	// readBlock is how long consumer waits for new messages.
+	readBlock = 5 * time.Second
+	// claimPeriod is how often consumer handles pending messages.
+	claimPeriod = 30 * time.Second
+	// claimIdle is how long message is pending before other consumer claims it.
+	claimIdle = time.Minute
+	// batchSize is number of pending messages read at once.
+	batchSize = 100
+)
+
+// Streams is message transport of saga over redis streams.
+type Streams struct {
+	client   *redis.Client
+	consumer string
+}
+
+// NewStreams returns transport, which consumes streams as consumer with given name,
+// the name must be stable across restarts of replica to take its pending messages.
+func NewStreams(client *redis.Client, consumer string) Streams {
+	return Streams{client: client, consumer: consumer}
+}
+
+// Publish appends message to stream.
+func (s Streams) Publish(ctx context.Context, stream string, values map[string]string) error {
+	_, err := s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		Values: values,
+	}).Result()
+	return err
+}
+
+// PublishBatch appends messages to stream through single pipeline and returns
+// number of messages appended in a row from the first one. Messages after the
+// first failed one may be appended too, so they are delivered at least once.
+func (s Streams) PublishBatch(ctx context.Context, stream string, batch []map[string]string) (int, error) {
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	cmds, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, values := range batch {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: stream,
+				Values: values,
+			})
+		}
+		return nil
+	})
+	for i, cmd := range cmds {
+		if cmd.Err() != nil {
+			return i, cmd.Err()
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
+
+// Subscribe consumes stream as member of consumer group until context is done,
+// messages are acknowledged after successful handling or if they are rejected,
+// other errors of handler are logged and message is left pending. Pending
+// messages of consumer are handled again on start and periodically, pending
+// messages of gone consumers are claimed after claimIdle.
+func (s Streams) Subscribe(group string, stream string, handler MessageHandler) func(context.Context) error {
+	return func(ctx context.Context) error {
+		for s.createGroup(ctx, stream, group) != nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+			}
+		}
+
+		var claimed time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			default:
+			}
+
+			if time.Since(claimed) >= claimPeriod {
+				err := s.handlePending(ctx, group, stream, handler)
+				if err != nil {
+					log.Println(err)
+				} else {
+					claimed = time.Now()
+				}
+			}
+
+			events, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group:    group,
+				Consumer: s.consumer,
+				Streams:  []string{stream, `>`},
+				Block:    readBlock,
+				Count:    1,
+				NoAck:    false,
+			}).Result()
+			switch {
+			case err == redis.Nil:
+				continue
+			case err != nil:
+				<-time.After(time.Second)
+				continue
+			}
+
+			s.handle(ctx, group, stream, events[0].Messages, handler)
+		}
+	}
+}
+
+// handlePending handles messages pending for consumer and claims messages
+// pending for other consumers longer than claimIdle.
+func (s Streams) handlePending(ctx context.Context, group, stream string, handler MessageHandler) error {
+	for start := `0`; ; {
+		events, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: s.consumer,
+			Streams:  []string{stream, start},
+			Count:    batchSize,
+			Block:    -1,
+		}).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if len(events) == 0 || len(events[0].Messages) == 0 {
+			break
+		}
+
+		messages := events[0].Messages
+		s.handle(ctx, group, stream, messages, handler)
+		start = messages[len(messages)-1].ID
+	}
+
+	for start := `0-0`; ; {
+		messages, next, err := s.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    group,
+			Consumer: s.consumer,
+			MinIdle:  claimIdle,
+			Start:    start,
+			Count:    batchSize,
+		}).Result()
+		if err != nil {
+			return err
+		}
+
+		s.handle(ctx, group, stream, messages, handler)
+		if next == `0-0` {
+			return nil
+		}
+		start = next
+	}
+}
+
+// handle handles messages and acknowledges them unless handler failed to handle them.
+func (s Streams) handle(ctx context.Context, group, stream string, messages []redis.XMessage, handler MessageHandler) {
+	for _, msg := range messages {
+		err := handler(ctx, msg.ID, msg.Values)
+		if err != nil {
+			log.Println(err)
+			if !errors.Is(err, ErrRejected) {
+				continue
+			}
+		}
+
+		_, err = s.client.XAck(ctx, stream, group, msg.ID).Result()
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// createGroup creates consumer group with stream, if group doesn't exist yet.
+func (s Streams) createGroup(ctx context.Context, stream, group string) error {
+	err := s.client.XGroupCreateMkStream(ctx, stream, group, `0`).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), `BUSYGROUP`) {
+		return err
+	}
+	return nil
+}
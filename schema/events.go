@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 
 	"github.com/google/uuid"
-	"github.com/shopspring/decimal"
+
+	"github.com/moeryomenko/saga/pkg/money"
 )
 
+// GetEventType returns type of event, empty type is returned if event has no type.
 func GetEventType(data map[string]any) EventType {
 	switch kind := data[`type`].(type) {
 	case string:
@@ -14,7 +16,7 @@ func GetEventType(data map[string]any) EventType {
 	case EventType:
 		return kind
 	default:
-		panic(`bug: invalid event type`)
+		return ``
 	}
 }
 
@@ -22,6 +24,11 @@ type Event struct {
 	Type EventType `json:"type"`
 }
 
+// Kind returns type of event as kind of outbox message.
+func (e Event) Kind() string {
+	return string(e.Type)
+}
+
 type EventType string
 
 const (
@@ -32,15 +39,48 @@ const (
 	PaymentsFailed    EventType = `paymants_failed`
 	StockConfirmed    EventType = `stock_confirmed`
 	StockFailed       EventType = `stock_failed`
+	ReturnOrder       EventType = `return_order`
+	PaymentsRefunded  EventType = `payments_refunded`
+	StockReturned     EventType = `stock_returned`
 )
 
 type OrderEvent struct {
 	Event
-	OrderID    uuid.UUID       `json:"order_id"`
-	CustomerID uuid.UUID       `json:"customer_id"`
-	Price      decimal.Decimal `json:"price"`
-	PaymentID  uuid.UUID       `json:"payment_id,omitempty"`
-	Items      string          `json:"items"`
+	OrderID    uuid.UUID `json:"order_id"`
+	CustomerID uuid.UUID `json:"customer_id"`
+	// Price is order price with currency, e.g. "9.99 USD".
+	Price     money.Money `json:"price"`
+	PaymentID uuid.UUID   `json:"payment_id,omitempty"`
+	// ReturnID identifies return of completed order, set only for return_order events.
+	ReturnID uuid.UUID `json:"return_id,omitempty"`
+	Items    Items     `json:"items"`
+}
+
+// Item represents order line item.
+type Item struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+// Items represents order line items, which encodes as JSON string,
+// because stream message values must be flat.
+type Items []Item
+
+func (i Items) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal([]Item(i))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+func (i *Items) UnmarshalJSON(data []byte) error {
+	var raw string
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(raw), (*[]Item)(i))
 }
 
 func (e *OrderEvent) SetType(kind EventType) {
@@ -90,6 +130,7 @@ type PaymentsEvent struct {
 	Event
 	OrderID    uuid.UUID `json:"order_id"`
 	PaymentsID uuid.UUID `json:"payments_id"`
+	ReturnID   uuid.UUID `json:"return_id,omitempty"`
 }
 
 func (e PaymentsEvent) Map() map[string]string {
@@ -115,7 +156,39 @@ func (e *PaymentsEvent) SetType(kind EventType) {
 
 type StockEvent struct {
 	Event
-	OrderID uuid.UUID `json:"order_id"`
+	OrderID  uuid.UUID `json:"order_id"`
+	ReturnID uuid.UUID `json:"return_id,omitempty"`
+	// Reason describes why stock failed.
+	Reason string `json:"reason,omitempty"`
+	// Allocation is warehouses which fulfil items of confirmed stock.
+	Allocation Allocation `json:"allocation,omitempty"`
+}
+
+// AllocatedItem represents part of line item fulfilled by warehouse.
+type AllocatedItem struct {
+	SKU       string `json:"sku"`
+	Warehouse string `json:"warehouse"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Allocation represents allocated line items, which encodes as JSON string like Items.
+type Allocation []AllocatedItem
+
+func (a Allocation) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal([]AllocatedItem(a))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+func (a *Allocation) UnmarshalJSON(data []byte) error {
+	var raw string
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(raw), (*[]AllocatedItem)(a))
 }
 
 func (e StockEvent) Map() map[string]string {
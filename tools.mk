@@ -7,6 +7,7 @@ tools: ## Install all needed tools, e.g. for static checks
 .PHONE: gen
 gen: tools ## Generate projects files and components.
 	@oapi-codegen --config api/order/config.yaml api/order/api.yaml > internal/order/infrastructure/api/http.gen.go
+	@oapi-codegen --config api/stock/config.yaml api/stock/api.yaml > internal/stock/infrastructure/api/http.gen.go
 
 .PHONY: deps
 deps: ## Manage go mod dependencies, beautify go.mod and go.sum files